@@ -11,6 +11,7 @@ import (
 	"sylr.dev/fix/config"
 	"sylr.dev/fix/pkg/acceptor"
 	"sylr.dev/fix/pkg/acceptor/application"
+	"sylr.dev/fix/pkg/errors"
 	"sylr.dev/fix/pkg/utils"
 )
 
@@ -39,6 +40,8 @@ func init() {
 
 func Execute(cmd *cobra.Command, args []string) error {
 	options := config.GetOptions()
+	ctx, cancel := utils.WithMaxRuntime(cmd.Context(), options.MaxRuntime)
+	defer cancel()
 	logger := config.GetLogger()
 
 	context, err := config.GetCurrentContext()
@@ -98,9 +101,13 @@ func Execute(cmd *cobra.Command, args []string) error {
 	interrupt := make(chan os.Signal, 1)
 	signal.Notify(interrupt, os.Interrupt, syscall.SIGTERM)
 
-	<-interrupt
-	acceptor.Stop()
-	os.Exit(0)
+	select {
+	case <-ctx.Done():
+		return errors.MaxRuntimeExceeded
+	case <-interrupt:
+		acceptor.Stop()
+		os.Exit(0)
+	}
 
 	return nil
 }
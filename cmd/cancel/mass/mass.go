@@ -8,7 +8,6 @@ import (
 	"syscall"
 	"time"
 
-	"github.com/google/uuid"
 	"github.com/quickfixgo/enum"
 	"github.com/quickfixgo/field"
 	"github.com/quickfixgo/quickfix"
@@ -32,6 +31,7 @@ var (
 	optionOrderSymbol        string
 	optionExecReportsTimeout time.Duration
 	partyIdOptions           *options.PartyIdOptions
+	clOrdIDOptions           *options.IDOptions
 )
 
 var MassCancelOrderCmd = &cobra.Command{
@@ -40,17 +40,19 @@ var MassCancelOrderCmd = &cobra.Command{
 	Long:              "Send mass cancel order request after initiating a session with a FIX acceptor.",
 	Args:              cobra.ExactArgs(0),
 	ValidArgsFunction: cobra.NoFileCompletions,
+	Annotations:       utils.MessageAnnotations(string(enum.MsgType_ORDER_MASS_CANCEL_REQUEST), quickfix.BeginStringFIXT11+"/FIX.5.0SP2"),
 	PersistentPreRunE: utils.MakePersistentPreRunE(Validate),
 	RunE:              Execute,
 }
 
 func init() {
-	MassCancelOrderCmd.Flags().StringVar(&optionOrderID, "id", "", "Order id (uuid autogenerated if not given)")
 	MassCancelOrderCmd.Flags().StringVar(&optionOrderSide, "side", "", "Order side (buy, sell ... etc)")
 	MassCancelOrderCmd.Flags().StringVar(&optionOrderSymbol, "symbol", "", "Order symbol")
 	MassCancelOrderCmd.Flags().DurationVar(&optionExecReportsTimeout, "exec-reports-timeout", 5*time.Second, "Log out if execution reports not received within timeout (0s wait indefinitely)")
+	initiator.AddAutoApplVerIDFlag(MassCancelOrderCmd)
 
 	partyIdOptions = options.NewPartyIdOptions(MassCancelOrderCmd)
+	clOrdIDOptions = options.NewIDOptions(MassCancelOrderCmd, &optionOrderID, "id", "ClOrdID", "Order id")
 
 	MassCancelOrderCmd.MarkFlagRequired("side")
 	MassCancelOrderCmd.MarkFlagRequired("symbol")
@@ -65,9 +67,8 @@ func Validate(cmd *cobra.Command, args []string) error {
 		return errors.OptionOrderSideUnknown
 	}
 
-	if len(optionOrderID) == 0 {
-		uid := uuid.New()
-		optionOrderID = uid.String()
+	if err := clOrdIDOptions.Validate(); err != nil {
+		return err
 	}
 
 	return partyIdOptions.Validate()
@@ -75,6 +76,8 @@ func Validate(cmd *cobra.Command, args []string) error {
 
 func Execute(cmd *cobra.Command, args []string) error {
 	options := config.GetOptions()
+	ctx, cancel := utils.WithMaxRuntime(cmd.Context(), options.MaxRuntime)
+	defer cancel()
 	logger := config.GetLogger()
 
 	context, err := config.GetCurrentContext()
@@ -143,6 +146,8 @@ func Execute(cmd *cobra.Command, args []string) error {
 	var sessionId quickfix.SessionID
 	var ok bool
 	select {
+	case <-ctx.Done():
+		return errors.MaxRuntimeExceeded
 	case <-time.After(timeout):
 		return errors.ConnectionTimeout
 	case sessionId, ok = <-app.Connected:
@@ -151,6 +156,10 @@ func Execute(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	if err := initiator.ApplyNegotiatedApplVerID(cmd, session); err != nil {
+		return err
+	}
+
 	// Prepare mass cancel message
 	cancelMsg, err := buildMessage(*session)
 	if err != nil {
@@ -176,6 +185,8 @@ func Execute(cmd *cobra.Command, args []string) error {
 LOOP:
 	for {
 		select {
+		case <-ctx.Done():
+			return errors.MaxRuntimeExceeded
 		case signal := <-interrupt:
 			logger.Debug().Msgf("Received signal: %s", signal)
 			break LOOP
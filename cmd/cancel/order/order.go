@@ -39,6 +39,7 @@ var CancelOrderCmd = &cobra.Command{
 	Long:              "Send a cancel order request after initiating a session with a FIX acceptor.",
 	Args:              cobra.ExactArgs(0),
 	ValidArgsFunction: cobra.NoFileCompletions,
+	Annotations:       utils.MessageAnnotations(string(enum.MsgType_ORDER_CANCEL_REQUEST), quickfix.BeginStringFIXT11+"/FIX.5.0SP2"),
 	PersistentPreRunE: utils.MakePersistentPreRunE(Validate),
 	RunE:              Execute,
 }
@@ -48,6 +49,7 @@ func init() {
 	CancelOrderCmd.Flags().StringVar(&optionOrderSide, "side", "", "Order side (buy, sell ... etc)")
 	CancelOrderCmd.Flags().StringVar(&optionOrderSymbol, "symbol", "", "Order symbol")
 	CancelOrderCmd.Flags().DurationVar(&optionExecReportsTimeout, "exec-reports-timeout", 5*time.Second, "Log out if execution reports not received within timeout (0s wait indefinitely)")
+	initiator.AddAutoApplVerIDFlag(CancelOrderCmd)
 
 	partyIdOptions = options.NewPartyIdOptions(CancelOrderCmd)
 
@@ -70,6 +72,8 @@ func Validate(cmd *cobra.Command, args []string) error {
 
 func Execute(cmd *cobra.Command, args []string) error {
 	options := config.GetOptions()
+	ctx, cancel := utils.WithMaxRuntime(cmd.Context(), options.MaxRuntime)
+	defer cancel()
 	logger := config.GetLogger()
 
 	context, err := config.GetCurrentContext()
@@ -138,6 +142,8 @@ func Execute(cmd *cobra.Command, args []string) error {
 	var sessionId quickfix.SessionID
 	var ok bool
 	select {
+	case <-ctx.Done():
+		return errors.MaxRuntimeExceeded
 	case <-time.After(timeout):
 		return errors.ConnectionTimeout
 	case sessionId, ok = <-app.Connected:
@@ -146,6 +152,10 @@ func Execute(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	if err := initiator.ApplyNegotiatedApplVerID(cmd, session); err != nil {
+		return err
+	}
+
 	// Prepare cancel message
 	cancelMsg, err := buildMessage(*session)
 	if err != nil {
@@ -171,6 +181,8 @@ func Execute(cmd *cobra.Command, args []string) error {
 LOOP:
 	for {
 		select {
+		case <-ctx.Done():
+			return errors.MaxRuntimeExceeded
 		case signal := <-interrupt:
 			logger.Debug().Msgf("Received signal: %s", signal)
 			break LOOP
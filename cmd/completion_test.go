@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// execute runs the fix command with args and returns what it printed.
+func execute(t *testing.T, args ...string) string {
+	t.Helper()
+
+	out := &bytes.Buffer{}
+	FixCmd.SetOut(out)
+	FixCmd.SetArgs(args)
+	t.Cleanup(func() {
+		FixCmd.SetOut(nil)
+		FixCmd.SetArgs(nil)
+	})
+
+	if err := FixCmd.Execute(); err != nil {
+		t.Fatalf("fix %s: %s", strings.Join(args, " "), err)
+	}
+
+	return out.String()
+}
+
+func TestCompletion(t *testing.T) {
+	tests := []struct {
+		shell string
+		want  string
+	}{
+		{shell: "bash", want: "# bash completion V2 for fix"},
+		{shell: "zsh", want: "#compdef fix"},
+		{shell: "fish", want: "# fish completion for fix"},
+		{shell: "powershell", want: "# powershell completion for fix"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.shell, func(t *testing.T) {
+			if got := execute(t, "completion", tt.shell); !strings.HasPrefix(got, tt.want) {
+				t.Errorf("fix completion %s does not start with %q", tt.shell, tt.want)
+			}
+		})
+	}
+}
+
+func TestDynamicCompletion(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want []string
+	}{
+		{
+			name: "log format",
+			args: []string{"--log-format", ""},
+			want: []string{"console", "json"},
+		},
+		{
+			name: "log level of a subcommand",
+			args: []string{"marketdata", "request", "--log-level", ""},
+			want: []string{"trace", "debug", "info", "warn", "error"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := execute(t, append([]string{"__complete"}, tt.args...)...)
+
+			// Completions are printed one per line followed by the
+			// directive, e.g. `:4`
+			got := []string{}
+			for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+				if strings.HasPrefix(line, ":") {
+					break
+				}
+				got = append(got, line)
+			}
+
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("fix __complete %s = %q, want %q", strings.Join(tt.args, " "), got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,20 @@
+package config
+
+import (
+	"github.com/spf13/cobra"
+
+	config_path "sylr.dev/fix/cmd/config/path"
+	config_schema "sylr.dev/fix/cmd/config/schema"
+)
+
+// ConfigCmd represents the config command
+var ConfigCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Manage fix configuration",
+	Long:  "Manage fix configuration.",
+}
+
+func init() {
+	ConfigCmd.AddCommand(config_path.ConfigPathCmd)
+	ConfigCmd.AddCommand(config_schema.ConfigSchemaCmd)
+}
@@ -0,0 +1,34 @@
+package config_path
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"sylr.dev/fix/config"
+)
+
+// ConfigPathCmd represents the config path command
+var ConfigPathCmd = &cobra.Command{
+	Use:               "path",
+	Short:             "Print the path of the configuration file",
+	Long:              "Print the absolute path of the configuration file used with the current flags, whether it exists or not.",
+	Args:              cobra.ExactArgs(0),
+	ValidArgsFunction: cobra.NoFileCompletions,
+	RunE:              Execute,
+}
+
+func Execute(cmd *cobra.Command, args []string) error {
+	options := config.GetOptions()
+
+	path, err := filepath.Abs(os.ExpandEnv(options.Config))
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintln(os.Stdout, path)
+
+	return nil
+}
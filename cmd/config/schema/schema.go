@@ -0,0 +1,37 @@
+package config_schema
+
+import (
+	"encoding/json"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"sylr.dev/fix/config"
+)
+
+// ConfigSchemaCmd represents the config schema command
+var ConfigSchemaCmd = &cobra.Command{
+	Use:               "schema",
+	Short:             "Print the JSON Schema of the configuration",
+	Long:              "Print the JSON Schema describing the configuration file format, or the conformance profile format with --conformance-profile.",
+	Args:              cobra.ExactArgs(0),
+	ValidArgsFunction: cobra.NoFileCompletions,
+	RunE:              Execute,
+}
+
+var optionProfile bool
+
+func init() {
+	ConfigSchemaCmd.Flags().BoolVar(&optionProfile, "conformance-profile", false, "Print the JSON Schema of conformance profiles instead")
+}
+
+func Execute(cmd *cobra.Command, args []string) error {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+
+	if optionProfile {
+		return encoder.Encode(config.ConformanceProfileSchema())
+	}
+
+	return encoder.Encode(config.JSONSchema())
+}
@@ -1,25 +1,41 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
+	"io"
+	"net"
 	"net/http"
 	"net/http/pprof"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"strings"
+	"syscall"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/rs/zerolog"
 	"github.com/spf13/cobra"
+	"golang.org/x/term"
 
 	"sylr.dev/fix/cmd/cancel"
+	configcmd "sylr.dev/fix/cmd/config"
+	"sylr.dev/fix/cmd/info"
 	initcmd "sylr.dev/fix/cmd/init"
 	"sylr.dev/fix/cmd/initiator"
 	"sylr.dev/fix/cmd/list"
 	"sylr.dev/fix/cmd/marketdata"
 	"sylr.dev/fix/cmd/new"
+	"sylr.dev/fix/cmd/replay"
+	"sylr.dev/fix/cmd/send"
+	"sylr.dev/fix/cmd/session"
 	"sylr.dev/fix/cmd/status"
+	"sylr.dev/fix/cmd/util"
 	"sylr.dev/fix/config"
+	"sylr.dev/fix/pkg/errors"
+	"sylr.dev/fix/pkg/tracing"
+	"sylr.dev/fix/pkg/utils"
 )
 
 var Version = "dev"
@@ -31,43 +47,112 @@ var FixCmd = &cobra.Command{
 	Version:      Version,
 	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
 		InitHTTP(cmd, args)
-		return InitLogger(cmd, args)
+		if err := InitPProf(cmd, args); err != nil {
+			return err
+		}
+		return loggerErr
 	},
 }
 
+var (
+	pprofServer *http.Server
+	loggerErr   error
+)
+
 func init() {
 	options := config.GetOptions()
+	config.SetVersion(Version)
+
+	// The logger is set up once the flags are parsed, before the commands
+	// validate their options so that they can log, its errors are returned
+	// by PersistentPreRunE
+	cobra.OnInitialize(func() {
+		loggerErr = InitLogger(FixCmd, nil)
+		InitTracing()
+	})
 
 	FixCmd.AddCommand(cancel.CancelCmd)
+	FixCmd.AddCommand(configcmd.ConfigCmd)
+	FixCmd.AddCommand(info.InfoCmd)
 	FixCmd.AddCommand(initcmd.InitCmd)
 	FixCmd.AddCommand(initiator.InitiatorCmd)
 	FixCmd.AddCommand(list.ListCmd)
 	FixCmd.AddCommand(marketdata.MarketDataCmd)
 	FixCmd.AddCommand(new.NewCmd)
+	FixCmd.AddCommand(replay.ReplayCmd)
+	FixCmd.AddCommand(send.SendCmd)
+	FixCmd.AddCommand(session.SessionCmd)
 	FixCmd.AddCommand(status.StatusCmd)
+	FixCmd.AddCommand(util.UtilCmd)
 
 	configPath := filepath.Join("$HOME", ".fix", "config")
 
 	FixCmd.PersistentFlags().StringVar(&options.Config, "config", os.ExpandEnv(configPath), "Config file")
 	FixCmd.PersistentFlags().CountVarP(&options.Verbose, "verbose", "v", "Increase verbosity")
 	FixCmd.PersistentFlags().BoolVar(&options.LogCaller, "log-caller", false, "Add caller info to log lines")
+	FixCmd.PersistentFlags().StringVar(&options.LogFormat, "log-format", "", "Log format (console, json), defaults to console when stderr is a terminal and json otherwise")
+	FixCmd.PersistentFlags().StringVar(&options.LogLevel, "log-level", "", "Log level (trace, debug, info, warn, error), takes precedence over --verbose")
+	FixCmd.PersistentFlags().StringVar(&options.SOHChar, "soh-char", "|", "Delimiter rendering SOH when printing raw FIX messages (e.g. |, ^A, use \\x01 to keep SOH)")
+	FixCmd.PersistentFlags().IntSliceVar(&options.MaskTags, "mask-tags", []int{554, 925}, "Tags whose values are masked in logged messages")
 	FixCmd.PersistentFlags().BoolVar(&options.Interactive, "interactive", true, "Enable interactive mode")
 	FixCmd.PersistentFlags().BoolP("help", "h", false, "Help for fix")
 	FixCmd.PersistentFlags().Bool("version", false, "Version for fix")
+	FixCmd.PersistentFlags().DurationVar(&options.MaxRuntime, "max-runtime", 0, "Maximum duration of the command before shutting down (0 means no limit)")
 	FixCmd.PersistentFlags().BoolVar(&options.Metrics, "metrics", false, "Enable metrics")
 	FixCmd.PersistentFlags().BoolVar(&options.PProf, "pprof", false, "Enable pprof")
 	FixCmd.PersistentFlags().IntVar(&options.HTTPPort, "port", 8080, "HTTP port")
+	FixCmd.PersistentFlags().StringVar(&options.OTelEndpoint, "otel-endpoint", "", "Export the spans of the command to the given OTLP/HTTP endpoint (e.g. http://localhost:4318)")
+	FixCmd.PersistentFlags().StringVar(&options.PProfAddr, "pprof-addr", "", "Expose pprof on the given address (e.g. localhost:6060) for the lifetime of the command")
+
+	FixCmd.RegisterFlagCompletionFunc("log-format", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{"console", "json"}, cobra.ShellCompDirectiveNoFileComp
+	})
+	FixCmd.RegisterFlagCompletionFunc("log-level", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{"trace", "debug", "info", "warn", "error"}, cobra.ShellCompDirectiveNoFileComp
+	})
 }
 
 func InitLogger(cmd *cobra.Command, args []string) error {
 	options := config.GetOptions()
 	zerolog.TimeFieldFormat = time.RFC3339Nano
-	consoleWriter := zerolog.ConsoleWriter{
-		Out:        os.Stdout,
-		TimeFormat: "Jan 2 15:04:05.000-0700",
+
+	level := config.IntToZerologLevel(options.Verbose)
+	if len(options.LogLevel) > 0 {
+		var err error
+		level, err = zerolog.ParseLevel(strings.ToLower(options.LogLevel))
+		if err != nil || level == zerolog.NoLevel {
+			return fmt.Errorf("%w: unknown log level `%s`", errors.Options, options.LogLevel)
+		}
+	}
+
+	// Logs are written to stderr so that they never mix with the data
+	// printed on stdout.
+	format := options.LogFormat
+	if len(format) == 0 {
+		format = "json"
+		if term.IsTerminal(int(os.Stderr.Fd())) {
+			format = "console"
+		}
+	}
+
+	var writer io.Writer
+	switch format {
+	case "console":
+		writer = zerolog.ConsoleWriter{
+			Out:        os.Stderr,
+			TimeFormat: "Jan 2 15:04:05.000-0700",
+		}
+	case "json":
+		writer = os.Stderr
+	default:
+		return fmt.Errorf("%w: unknown log format `%s`", errors.Options, options.LogFormat)
 	}
-	multi := zerolog.MultiLevelWriter(consoleWriter)
-	logger := zerolog.New(multi).With().Timestamp().Logger().Level(config.IntToZerologLevel(options.Verbose))
+
+	utils.SetSOHChar(options.SOHChar)
+	utils.SetMaskedTags(options.MaskTags)
+
+	multi := zerolog.MultiLevelWriter(writer)
+	logger := zerolog.New(multi).With().Timestamp().Logger().Level(level)
 
 	if options.LogCaller {
 		logger = logger.With().Caller().Logger()
@@ -89,14 +174,92 @@ func InitHTTP(cmd *cobra.Command, args []string) error {
 		mux.Handle("/metrics", promhttp.Handler())
 	}
 	if options.PProf {
-		mux.HandleFunc("/debug/pprof/", pprof.Index)
-		mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
-		mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
-		mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
-		mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+		handlePProf(mux)
 	}
 
 	go http.ListenAndServe(fmt.Sprintf(":%d", options.HTTPPort), mux)
 
 	return nil
 }
+
+// InitPProf starts a dedicated pprof server if --pprof-addr is given. The
+// server is shut down by main once the command returns, whether it failed or
+// not, or when a signal is received.
+func InitPProf(cmd *cobra.Command, args []string) error {
+	options := config.GetOptions()
+
+	if len(options.PProfAddr) == 0 || pprofServer != nil {
+		return nil
+	}
+
+	listener, err := net.Listen("tcp", options.PProfAddr)
+	if err != nil {
+		return fmt.Errorf("%w: unable to listen on pprof address: %s", errors.Options, err)
+	}
+
+	mux := http.NewServeMux()
+	handlePProf(mux)
+	pprofServer = &http.Server{Handler: mux}
+
+	go pprofServer.Serve(listener)
+
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt, syscall.SIGTERM)
+
+	go func() {
+		<-interrupt
+		signal.Stop(interrupt)
+		StopPProf()
+	}()
+
+	return nil
+}
+
+// StopPProf shuts down the pprof server started by InitPProf.
+func StopPProf() error {
+	if pprofServer == nil {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	return pprofServer.Shutdown(ctx)
+}
+
+// InitTracing starts tracing the command if --otel-endpoint is given.
+func InitTracing() {
+	options := config.GetOptions()
+
+	if len(options.OTelEndpoint) == 0 {
+		return
+	}
+
+	command := FixCmd.Name()
+	if c, _, err := FixCmd.Find(os.Args[1:]); err == nil {
+		command = c.CommandPath()
+	}
+
+	tracing.Start(options.OTelEndpoint, command, Version)
+}
+
+// StopTracing ends the span of the command with its error and exports the
+// spans recorded.
+func StopTracing(cmdErr error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := tracing.Stop(ctx, cmdErr); err != nil {
+		if logger := config.GetLogger(); logger != nil {
+			logger.Warn().Err(err).Msg("Unable to export spans")
+		}
+	}
+}
+
+func handlePProf(mux *http.ServeMux) {
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+}
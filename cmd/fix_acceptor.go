@@ -5,8 +5,10 @@ package cmd
 
 import (
 	"sylr.dev/fix/cmd/acceptor"
+	"sylr.dev/fix/cmd/serve"
 )
 
 func init() {
 	FixCmd.AddCommand(acceptor.AcceptorCmd)
+	FixCmd.AddCommand(serve.ServeCmd)
 }
@@ -0,0 +1,20 @@
+package info
+
+import (
+	"github.com/spf13/cobra"
+
+	info_messages "sylr.dev/fix/cmd/info/messages"
+	info_versions "sylr.dev/fix/cmd/info/versions"
+)
+
+// InfoCmd represents the info command
+var InfoCmd = &cobra.Command{
+	Use:   "info",
+	Short: "Describe the tool capabilities",
+	Long:  "Describe the FIX versions and messages supported by the commands of the tool.",
+}
+
+func init() {
+	InfoCmd.AddCommand(info_messages.InfoMessagesCmd)
+	InfoCmd.AddCommand(info_versions.InfoVersionsCmd)
+}
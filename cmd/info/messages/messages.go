@@ -0,0 +1,52 @@
+package info_messages
+
+import (
+	"os"
+	"strings"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+
+	"github.com/quickfixgo/enum"
+
+	"sylr.dev/fix/pkg/dict"
+	"sylr.dev/fix/pkg/utils"
+)
+
+// InfoMessagesCmd represents the info messages command
+var InfoMessagesCmd = &cobra.Command{
+	Use:               "messages",
+	Short:             "List the commands sending typed FIX messages",
+	Long:              "List the commands sending typed FIX messages along with their MsgType and the FIX versions they support.",
+	Args:              cobra.ExactArgs(0),
+	ValidArgsFunction: cobra.NoFileCompletions,
+	RunE:              Execute,
+}
+
+func Execute(cmd *cobra.Command, args []string) error {
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"COMMAND", "MSGTYPE", "MESSAGE", "VERSIONS"})
+	table.SetBorder(false)
+	table.SetAutoWrapText(false)
+
+	for _, c := range utils.MessageCommands(cmd.Root()) {
+		msgType := c.Annotations[utils.AnnotationMsgType]
+		name, _ := dict.SearchValue(dict.MessageTypes, enum.MsgType(msgType))
+
+		versions := c.Annotations[utils.AnnotationVersions]
+		if len(versions) == 0 {
+			versions = "all"
+		}
+
+		table.Append([]string{
+			strings.TrimPrefix(c.CommandPath(), cmd.Root().Name()+" "),
+			msgType,
+			name,
+			strings.ReplaceAll(versions, ",", ", "),
+		})
+	}
+
+	table.Render()
+
+	return nil
+}
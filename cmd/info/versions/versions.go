@@ -0,0 +1,67 @@
+package info_versions
+
+import (
+	"os"
+	"strings"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+
+	"sylr.dev/fix/pkg/dict"
+	"sylr.dev/fix/pkg/utils"
+)
+
+// InfoVersionsCmd represents the info versions command
+var InfoVersionsCmd = &cobra.Command{
+	Use:               "versions",
+	Short:             "List the supported FIX versions",
+	Long:              "List the FIX versions supported by the commands sending typed FIX messages.",
+	Args:              cobra.ExactArgs(0),
+	ValidArgsFunction: cobra.NoFileCompletions,
+	RunE:              Execute,
+}
+
+func Execute(cmd *cobra.Command, args []string) error {
+	commands := utils.MessageCommands(cmd.Root())
+
+	// FIXT.1.1 versions are written with the DefaultApplVerID they were
+	// implemented for and listed after the transport versions
+	versions := append([]string{}, dict.BeginStrings...)
+	for _, c := range commands {
+		for _, v := range splitVersions(c) {
+			if utils.Search(versions, v) < 0 {
+				versions = append(versions, v)
+			}
+		}
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"VERSION", "COMMANDS"})
+	table.SetBorder(false)
+	table.SetAutoWrapText(false)
+
+	for _, version := range versions {
+		names := []string{}
+		for _, c := range commands {
+			supported := splitVersions(c)
+			if len(supported) == 0 || utils.Search(supported, version) >= 0 {
+				names = append(names, strings.TrimPrefix(c.CommandPath(), cmd.Root().Name()+" "))
+			}
+		}
+
+		table.Append([]string{version, strings.Join(names, ", ")})
+	}
+
+	table.Render()
+
+	return nil
+}
+
+func splitVersions(cmd *cobra.Command) []string {
+	versions := cmd.Annotations[utils.AnnotationVersions]
+	if len(versions) == 0 {
+		return nil
+	}
+
+	return strings.Split(versions, ",")
+}
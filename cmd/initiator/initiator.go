@@ -53,6 +53,8 @@ func init() {
 
 func Execute(cmd *cobra.Command, args []string) error {
 	options := config.GetOptions()
+	ctx, cancel := utils.WithMaxRuntime(cmd.Context(), options.MaxRuntime)
+	defer cancel()
 	logger := config.GetLogger()
 
 	context, err := config.GetCurrentContext()
@@ -119,6 +121,8 @@ func Execute(cmd *cobra.Command, args []string) error {
 
 	// Wait for session connection
 	select {
+	case <-ctx.Done():
+		return errors.MaxRuntimeExceeded
 	case <-time.After(timeout):
 		return errors.ConnectionTimeout
 	case _, ok := <-app.Connected:
@@ -140,6 +144,8 @@ func Execute(cmd *cobra.Command, args []string) error {
 LOOP:
 	for {
 		select {
+		case <-ctx.Done():
+			return errors.MaxRuntimeExceeded
 		case signal := <-interrupt:
 			logger.Debug().Msgf("Received signal: %s", signal)
 			break LOOP
@@ -13,9 +13,11 @@ import (
 	"github.com/quickfixgo/field"
 	"github.com/quickfixgo/fixt11"
 	"github.com/quickfixgo/quickfix"
+	"github.com/quickfixgo/tag"
 
 	"sylr.dev/fix/config"
 	"sylr.dev/fix/pkg/cli/complete"
+	"sylr.dev/fix/pkg/cli/options"
 	"sylr.dev/fix/pkg/dict"
 	"sylr.dev/fix/pkg/errors"
 	"sylr.dev/fix/pkg/initiator"
@@ -24,22 +26,27 @@ import (
 )
 
 var (
-	optionType string
+	optionType           string
+	optionSecurityReqID  string
+	securityReqIDOptions *options.IDOptions
 )
 
 var ListSecurityCmd = &cobra.Command{
 	Use:               "security",
 	Aliases:           []string{"securities"},
 	Short:             "List securities",
-	Long:              "Send a securitylist FIX Message after initiating a session with a FIX acceptor.",
+	Long:              "Send a securitylist FIX Message after initiating a session with a FIX acceptor. The symbols received are cached for the session to validate and complete the symbols given to other commands.",
 	Args:              cobra.ExactArgs(0),
 	ValidArgsFunction: cobra.NoFileCompletions,
+	Annotations:       utils.MessageAnnotations(string(enum.MsgType_SECURITY_LIST_REQUEST), quickfix.BeginStringFIXT11+"/FIX.5.0SP2"),
 	PersistentPreRunE: utils.MakePersistentPreRunE(Validate),
 	RunE:              Execute,
 }
 
 func init() {
 	ListSecurityCmd.Flags().StringVar(&optionType, "type", "symbol", "Securities type (symbol, product ... etc)")
+	securityReqIDOptions = options.NewIDOptions(ListSecurityCmd, &optionSecurityReqID, "id", "SecurityReqID", "SecurityListRequest id")
+	initiator.AddAutoApplVerIDFlag(ListSecurityCmd)
 
 	ListSecurityCmd.RegisterFlagCompletionFunc("type", complete.SecurityListRequestType)
 }
@@ -51,11 +58,13 @@ func Validate(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("unknown security type")
 	}
 
-	return nil
+	return securityReqIDOptions.Validate()
 }
 
 func Execute(cmd *cobra.Command, args []string) error {
 	options := config.GetOptions()
+	ctx, cancel := utils.WithMaxRuntime(cmd.Context(), options.MaxRuntime)
+	defer cancel()
 	logger := config.GetLogger()
 
 	context, err := config.GetCurrentContext()
@@ -122,6 +131,8 @@ func Execute(cmd *cobra.Command, args []string) error {
 
 	// Wait for session connection
 	select {
+	case <-ctx.Done():
+		return errors.MaxRuntimeExceeded
 	case <-time.After(timeout):
 		return errors.ConnectionTimeout
 	case _, ok := <-app.Connected:
@@ -130,6 +141,10 @@ func Execute(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	if err := initiator.ApplyNegotiatedApplVerID(cmd, session); err != nil {
+		return err
+	}
+
 	// Prepare securitylist
 	securitylist, err := buildMessage(*session)
 	if err != nil {
@@ -145,6 +160,8 @@ func Execute(cmd *cobra.Command, args []string) error {
 	// Wait for the order response
 	var responseMessage *quickfix.Message
 	select {
+	case <-ctx.Done():
+		return errors.MaxRuntimeExceeded
 	case <-time.After(timeout):
 		return errors.ResponseTimeout
 	case responseMessage = <-app.FromAppMessages:
@@ -152,6 +169,23 @@ func Execute(cmd *cobra.Command, args []string) error {
 
 	app.WriteMessageBodyAsTable(os.Stdout, responseMessage)
 
+	if typ, err := responseMessage.MsgType(); err == nil && enum.MsgType(typ) == enum.MsgType_SECURITY_LIST {
+		tree := utils.MessageTree(responseMessage, transportDict, appDict)
+		cache := config.SecurityCache{
+			Context: context.Name,
+			Session: session.Name,
+			Updated: time.Now(),
+			Symbols: utils.MessageTreeGroupValues(tree, int(tag.NoRelatedSym), int(tag.Symbol)),
+		}
+
+		path := context.SecurityCachePath(session)
+		if err := cache.Write(path); err != nil {
+			logger.Warn().Err(err).Msg("Unable to cache the security list")
+		} else {
+			logger.Debug().Msgf("%d symbol(s) cached in %s", len(cache.Symbols), path)
+		}
+	}
+
 	return nil
 }
 
@@ -162,7 +196,7 @@ func buildMessage(session config.Session) (quickfix.Messagable, error) {
 	}
 
 	stype := field.NewSecurityListRequestType(etype)
-	reqid := field.NewSecurityReqID(string(enum.SecurityRequestType_SYMBOL))
+	reqid := field.NewSecurityReqID(optionSecurityReqID)
 
 	// Message
 	message := quickfix.NewMessage()
@@ -0,0 +1,95 @@
+package marketdatarequest
+
+import (
+	"fmt"
+
+	"github.com/quickfixgo/quickfix"
+	"github.com/quickfixgo/quickfix/datadictionary"
+	"github.com/quickfixgo/tag"
+
+	"sylr.dev/fix/config"
+	"sylr.dev/fix/pkg/errors"
+	"sylr.dev/fix/pkg/utils"
+)
+
+// groupLayout holds the member tags of the repeating groups of the request,
+// in the order of their template, and the names of the group templates they
+// come from if any.
+type groupLayout struct {
+	members   map[quickfix.Tag][]quickfix.Tag
+	templates map[quickfix.Tag]string
+}
+
+// newGroupLayout returns the standard layout of the request groups.
+func newGroupLayout() *groupLayout {
+	return &groupLayout{
+		members: map[quickfix.Tag][]quickfix.Tag{
+			tag.NoMDEntryTypes: {tag.MDEntryType},
+			tag.NoRelatedSym:   {tag.Symbol, tag.SecurityGroup, tag.MarketSegmentID},
+		},
+		templates: map[quickfix.Tag]string{},
+	}
+}
+
+// loadGroupTemplates returns the layout of the request groups, the standard
+// one being replaced per venue by the group templates selected by the context
+// marketDataGroupTemplates.
+func loadGroupTemplates(context *config.Context, transportDict, appDict *datadictionary.DataDictionary) (*groupLayout, error) {
+	layout := newGroupLayout()
+
+	for _, name := range context.MarketDataGroupTemplates {
+		tmpl, err := context.GetGroupTemplate(name)
+		if err != nil {
+			return nil, err
+		}
+
+		countTag, members, err := tmpl.Resolve(transportDict, appDict)
+		if err != nil {
+			return nil, err
+		}
+
+		if _, ok := layout.members[countTag]; !ok {
+			return nil, fmt.Errorf("%w: group template %s: countTag must be NoMDEntryTypes or NoRelatedSym", errors.Config, name)
+		}
+
+		layout.members[countTag] = members
+		layout.templates[countTag] = name
+	}
+
+	return layout, nil
+}
+
+// checkGroups makes sure the request groups hold the tags the request sets and
+// that they are defined in the app dictionary, along with all the members of
+// the group templates of the layout.
+func checkGroups(layout *groupLayout, appDict *datadictionary.DataDictionary, msgType string, required map[quickfix.Tag][]quickfix.Tag) error {
+	for group, tags := range required {
+		name, custom := layout.templates[group]
+
+		for _, t := range tags {
+			if custom && utils.Search(layout.members[group], t) < 0 {
+				return fmt.Errorf("%w: group template %s: tag %d used by the request is not a member", errors.Config, name, t)
+			}
+		}
+
+		if custom {
+			tags = layout.members[group]
+		}
+
+		if err := utils.CheckGroupDefinition(appDict, msgType, group, tags...); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// groupTemplate returns the quickfix template of the request group.
+func groupTemplate(layout *groupLayout, group quickfix.Tag) quickfix.GroupTemplate {
+	template := make(quickfix.GroupTemplate, 0, len(layout.members[group]))
+	for _, t := range layout.members[group] {
+		template = append(template, quickfix.GroupElement(t))
+	}
+
+	return template
+}
@@ -0,0 +1,58 @@
+package marketdatarequest
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"sylr.dev/fix/pkg/errors"
+)
+
+// IDMapEntry describes what a MarketDataRequest sent with a given MDReqID
+// covers, written by --id-map-file.
+type IDMapEntry struct {
+	Symbols       []string `json:"symbols"`
+	SecurityGroup string   `json:"security_group,omitempty"`
+	SegmentID     string   `json:"market_segment_id,omitempty"`
+	Types         []string `json:"types"`
+	Depth         int      `json:"depth"`
+	TargetSubID   string   `json:"target_sub_id,omitempty"`
+}
+
+// writeIDMap writes the JSON object mapping every MDReqID to the symbols and
+// types it covers to path, or to stderr if path is `-`.
+func writeIDMap(path string, specs map[string]requestSpec) error {
+	idMap := make(map[string]IDMapEntry, len(specs))
+	for id, spec := range specs {
+		symbols := spec.symbols
+		if symbols == nil {
+			symbols = []string{}
+		}
+
+		idMap[id] = IDMapEntry{
+			Symbols:       symbols,
+			SecurityGroup: optionSecGroup,
+			SegmentID:     optionSegmentID,
+			Types:         spec.group.Types,
+			Depth:         spec.group.Depth,
+			TargetSubID:   spec.targetSubID,
+		}
+	}
+
+	b, err := json.MarshalIndent(idMap, "", "  ")
+	if err != nil {
+		return err
+	}
+	b = append(b, '\n')
+
+	if path == "-" {
+		_, err = os.Stderr.Write(b)
+		return err
+	}
+
+	if err := os.WriteFile(path, b, 0o644); err != nil {
+		return fmt.Errorf("%w: unable to write MDReqID map: %s", errors.Options, err)
+	}
+
+	return nil
+}
@@ -1,12 +1,18 @@
 package marketdatarequest
 
 import (
+	"compress/gzip"
+	gocontext "context"
 	"fmt"
+	"io"
 	"os"
 	"os/signal"
+	"sort"
+	"strconv"
 	"strings"
 	"syscall"
 	"time"
+	"unicode"
 
 	"github.com/google/uuid"
 	"github.com/rs/zerolog"
@@ -16,10 +22,12 @@ import (
 	"github.com/quickfixgo/field"
 	"github.com/quickfixgo/fixt11"
 	"github.com/quickfixgo/quickfix"
+	"github.com/quickfixgo/quickfix/datadictionary"
 	"github.com/quickfixgo/tag"
 
 	"sylr.dev/fix/config"
 	"sylr.dev/fix/pkg/cli/complete"
+	"sylr.dev/fix/pkg/cli/options"
 	"sylr.dev/fix/pkg/dict"
 	"sylr.dev/fix/pkg/errors"
 	"sylr.dev/fix/pkg/initiator"
@@ -34,33 +42,171 @@ var (
 	optionUpdateType string
 	optionMDReqID    string
 	optionPrintData  bool
+	optionRawOut     string
+	optionOutFile    string
+	optionOutAppend  bool
+	optionCompress   bool
+	optionStrictVer  bool
+	optionTimeFormat string
+	optionSince      string
+	optionShowInfo   bool
+	optionStrict     bool
+	optionValidate   bool
+	optionOutput     string
+	optionJSONPretty bool
+	optionEcho       bool
+	optionWaitAll    bool
+	optionTrace      bool
+	optionRetryDupID bool
+	optionStrictDict string
+	optionSummary    string
+	optionSecGroup   string
+	optionSegmentID  string
+	optionRecoverSeq bool
+	optionApplVerID  string
+	optionDepth      int
+	optionAggregated bool
+	optionImplDelete bool
+	optionRespTmout  time.Duration
+	optionIdleTmout  time.Duration
+	optionNonASCII   bool
+	optionMaxSymbols int
+	optionFilter     string
+	optionFanSubIDs  []string
+	optionIDSeed     int64
+	optionRelogon    bool
+	optionGroupMode  string
+	optionRefreshSec bool
+	optionShowSeqNum bool
+	optionChanBuffer int
+	optionMaxRejects int
+	optionHold       time.Duration
+	optionIDMapFile  string
+	optionSortTypes  bool
+
+	optionOnDisconnect  string
+	optionRetryAttempts int
+	optionRetryInterval time.Duration
+
+	optionPostLogonDelay time.Duration
+	optionRateLimit      string
 
 	SubType      enum.SubscriptionRequestType
 	MDUpdateType enum.MDUpdateType
+	SinceCursor  string
+	SinceTag     quickfix.Tag
+	MsgApplVerID enum.ApplVerID
+	RateLimiter  *utils.RateLimiter
+	Filter       *utils.Filter
+	Profile      *config.ConformanceProfile
+
+	mdReqIDOptions *options.IDOptions
+
+	// TypeGroups holds the requested types grouped by market depth, each group
+	// being sent in its own request(s).
+	TypeGroups []TypeGroup
+
+	// logRequestIDs is true when the MDReqID is not common to the log lines of
+	// the command, each request logging the id it is sent under.
+	logRequestIDs bool
+
+	// qualifiers holds the optional request qualifiers given on the command line
+	qualifiers = map[quickfix.Tag]string{}
+
+	// nonASCIISymbols holds the symbols containing non-ASCII characters found
+	// in Validate, they are reported in Execute once the logger is set up.
+	nonASCIISymbols []string
+
+	// summary collects the run metadata written by --summary-json
+	summary = NewSummary()
+
+	// securityCache identifies the security cache of the session, refreshed
+	// once on the first logon with --refresh-securities.
+	securityCache     config.SecurityCache
+	securityCachePath string
+	securitiesFresh   bool
 )
 
+// TypeGroup is a set of MDEntryTypes requested with the same market depth.
+type TypeGroup struct {
+	Depth int
+	Types []string
+}
+
 var MarketDataRequestCmd = &cobra.Command{
 	Use:               "request",
 	Short:             "Send a MarketDataRequest FIX message",
 	Long:              "Send a MarketDataRequest FIX Message after initiating a session with a FIX acceptor.",
 	Args:              cobra.ExactArgs(0),
 	ValidArgsFunction: cobra.NoFileCompletions,
+	Annotations:       utils.MessageAnnotations(string(enum.MsgType_MARKET_DATA_REQUEST)),
 	PersistentPreRunE: utils.MakePersistentPreRunE(Validate),
 	RunE:              Execute,
 }
 
 func init() {
 	MarketDataRequestCmd.Flags().StringArrayVar(&optionSymbols, "symbol", []string{}, "Symbols")
-	MarketDataRequestCmd.Flags().StringArrayVar(&optionTypes, "type", []string{"bid", "offer"}, "Order type (offer, bid, trade)")
+	MarketDataRequestCmd.Flags().StringVar(&optionSecGroup, "security-group", "", "Subscribe to a whole security group instead of individual symbols")
+	MarketDataRequestCmd.Flags().StringVar(&optionSegmentID, "market-segment-id", "", "Subscribe to a whole market segment instead of individual symbols")
+	MarketDataRequestCmd.Flags().StringArrayVar(&optionTypes, "type", []string{"bid", "offer"}, "Order type (offer, bid, trade), optionally suffixed by a market depth (e.g. trade:1) sent in a separate request, several types can be given comma separated")
+	MarketDataRequestCmd.Flags().BoolVar(&optionSortTypes, "sort-types", false, "Sort the entry types of each request in the context mdEntryTypeOrder, or by MDEntryType value, instead of the command line order")
 	MarketDataRequestCmd.Flags().StringVar(&optionSubType, "sub-type", "snapshot", "Subscription type")
 	MarketDataRequestCmd.Flags().StringVar(&optionUpdateType, "update-type", "incremental_refresh", "Update type")
-	MarketDataRequestCmd.Flags().StringVar(&optionMDReqID, "id", "", "MarketDataRequest id (uuid autogenerated if not given)")
+	mdReqIDOptions = options.NewIDOptions(MarketDataRequestCmd, &optionMDReqID, "id", "MDReqID", "MarketDataRequest id")
+	MarketDataRequestCmd.Flags().Int64Var(&optionIDSeed, "id-seed", 0, "Seed generating the same MDReqIDs on every run, for reproducible test captures only")
 	MarketDataRequestCmd.Flags().BoolVar(&optionPrintData, "print-data", true, "Print data")
+	MarketDataRequestCmd.Flags().IntVar(&optionChanBuffer, "app-chan-buffer", application.DefaultAppChanBuffer, "Number of received messages buffered before blocking the session (defaults to the initiator's AppChanBuffer if set)")
+	MarketDataRequestCmd.Flags().StringVar(&optionRawOut, "raw-out", "", "Write raw sent and received messages (SOH preserved, newline separated) to file, not meant for human viewing")
+	MarketDataRequestCmd.Flags().StringVar(&optionOutput, "output", application.OutputTable, "Output format (table, json, csv)")
+	MarketDataRequestCmd.Flags().StringVar(&optionOutFile, "output-file", "", "Write the printed data to this file instead of stdout")
+	MarketDataRequestCmd.Flags().BoolVar(&optionOutAppend, "output-append", false, "Append to --output-file instead of truncating it")
+	MarketDataRequestCmd.Flags().BoolVar(&optionCompress, "compress", false, "Gzip --output-file (implied when the file name ends with .gz)")
+	MarketDataRequestCmd.Flags().StringVar(&optionGroupMode, "group", "flat", "Representation of the inbound repeating groups: flat, one record per entry, or json, the whole message with nested groups (implies --output json)")
+	MarketDataRequestCmd.Flags().BoolVar(&optionJSONPretty, "json-pretty", false, "Print indented JSON, one message per block (implies --output json)")
+	MarketDataRequestCmd.Flags().BoolVar(&optionShowSeqNum, "show-seqnum", false, "Print the MsgSeqNum of every printed message, sent and received")
+	MarketDataRequestCmd.Flags().BoolVar(&optionEcho, "echo-request", false, "Print the sent request(s) with the selected output format")
+	MarketDataRequestCmd.Flags().BoolVar(&optionTrace, "trace", false, "Print each request followed by all the responses referencing its MDReqID, grouped when the command ends")
+	MarketDataRequestCmd.Flags().IntVar(&optionMaxRejects, "stop-after-reject-count", 1, "Exit with an error once this many rejects were received, each one being logged (0 means never)")
+	MarketDataRequestCmd.Flags().BoolVar(&optionRetryDupID, "retry-dup-id", false, "Send a request again once with a new MDReqID if the venue rejects it as duplicate")
+	MarketDataRequestCmd.Flags().StringVar(&optionIDMapFile, "id-map-file", "", "Write a JSON object mapping each MDReqID to the symbols and types it covers to this file (- for stderr) once the requests are sent")
+	MarketDataRequestCmd.Flags().StringVar(&optionSummary, "summary-json", "", "Write a JSON summary of the run to this file on exit")
+	MarketDataRequestCmd.Flags().BoolVar(&optionRecoverSeq, "recover-seq", false, "Log on again once with sequence numbers reset if the logon fails on a sequence number mismatch")
+	MarketDataRequestCmd.Flags().BoolVar(&optionWaitAll, "wait-all", false, "Exit once every request got at least one response or reject")
+	MarketDataRequestCmd.Flags().DurationVar(&optionRespTmout, "response-timeout", 0, "Maximum duration to wait for responses with --wait-all (0 means no limit)")
+	MarketDataRequestCmd.Flags().DurationVar(&optionHold, "hold", 0, "Collect data for this duration after subscribing, then unsubscribe and log off (also unsubscribes on interrupt)")
+	MarketDataRequestCmd.Flags().DurationVar(&optionIdleTmout, "idle-timeout", 0, "Exit if no market data message is received for this duration, heartbeats excluded (0 means no limit)")
+	MarketDataRequestCmd.Flags().StringVar(&optionFilter, "filter", "", "Only print the inbound market data entries matching this expression, e.g. 'symbol==EUR/USD && type==bid'")
+	MarketDataRequestCmd.Flags().StringVar(&optionTimeFormat, "timestamp-format", "rfc3339", "Format of the timestamps printed in the table and csv outputs (rfc3339, unix or a Go time layout), json output always uses rfc3339")
+	MarketDataRequestCmd.Flags().StringVar(&optionSince, "since", "", "Resume incremental updates after this cursor (sequence number or RFC3339 timestamp), requires MarketDataResume in session config")
+	MarketDataRequestCmd.Flags().StringArrayVar(&optionFanSubIDs, "fan-target-sub-id", []string{}, "Send the request once per TargetSubID on the session, with distinct MDReqIDs (can be repeated)")
+	MarketDataRequestCmd.Flags().IntVar(&optionMaxSymbols, "max-symbols-per-request", 0, "Split symbols in several requests of at most this many symbols (defaults to session's MaxSymbolsPerRequest, 0 means no limit)")
+	MarketDataRequestCmd.Flags().BoolVar(&optionShowInfo, "show-session-info", false, "Log the session parameters negotiated during logon")
+	MarketDataRequestCmd.Flags().StringVar(&optionOnDisconnect, "on-disconnect", "exit", "Policy when the session gets disconnected (exit, retry)")
+	MarketDataRequestCmd.Flags().BoolVar(&optionRelogon, "relogon-schedule", false, "When logged out at the end of the session window, log on again when it reopens and send the requests again")
+	MarketDataRequestCmd.Flags().IntVar(&optionRetryAttempts, "retry-attempts", 3, "Maximum number of retries when --on-disconnect=retry")
+	MarketDataRequestCmd.Flags().DurationVar(&optionRetryInterval, "retry-interval", 5*time.Second, "Duration between retries when --on-disconnect=retry")
+	MarketDataRequestCmd.Flags().BoolVar(&optionStrict, "strict", false, "Fail instead of warning when requesting types not supported by the venue, non-ASCII symbols or symbols missing from the security cache")
+	MarketDataRequestCmd.Flags().BoolVar(&optionRefreshSec, "refresh-securities", false, "Fetch the security list after logon to refresh the security cache before checking the symbols")
+	MarketDataRequestCmd.Flags().BoolVar(&optionNonASCII, "allow-non-ascii", false, "Allow non-ASCII symbols for venues that permit them")
+	MarketDataRequestCmd.Flags().BoolVar(&optionValidate, "validate-outbound", true, "Validate the request against the app dictionary before sending it")
+	MarketDataRequestCmd.Flags().DurationVar(&optionPostLogonDelay, "post-logon-delay", 0, "Delay between the logon and the sending of the request(s)")
+	MarketDataRequestCmd.Flags().StringVar(&optionRateLimit, "rate-limit", "", "Maximum rate of outbound requests (e.g. 10/s, 100/m), unlimited if empty")
+	MarketDataRequestCmd.Flags().StringVar(&optionApplVerID, "msg-appl-ver-id", "", "ApplVerID set in the request header to override the session's DefaultApplVerID (FIXT.1.1 only)")
+	MarketDataRequestCmd.Flags().IntVar(&optionDepth, "market-depth", 0, "Depth of the book (0 means full book, 1 top of book)")
+	MarketDataRequestCmd.Flags().BoolVar(&optionAggregated, "aggregated-book", false, "Request an aggregated book (AggregatedBook), omitted if not given")
+	MarketDataRequestCmd.Flags().BoolVar(&optionImplDelete, "implicit-delete", false, "Allow the venue to implicitly delete entries beyond the depth (MDImplicitDelete), omitted if not given")
+	MarketDataRequestCmd.Flags().StringVar(&optionStrictDict, "strict-dictionary", "", "Report inbound tags unknown to the app dictionary (warn, error)")
+	MarketDataRequestCmd.Flags().Lookup("strict-dictionary").NoOptDefVal = "warn"
+	MarketDataRequestCmd.Flags().BoolVar(&optionStrictVer, "strict-version", false, "Fail if the acceptor's DefaultApplVerID does not match the session's")
 
-	MarketDataRequestCmd.RegisterFlagCompletionFunc("symbol", cobra.NoFileCompletions)
+	MarketDataRequestCmd.RegisterFlagCompletionFunc("symbol", complete.Symbols)
 	MarketDataRequestCmd.RegisterFlagCompletionFunc("type", complete.MDEntryTypes)
 	MarketDataRequestCmd.RegisterFlagCompletionFunc("sub-type", complete.SubscriptionRequestTypes)
 	MarketDataRequestCmd.RegisterFlagCompletionFunc("update-type", complete.MDUpdateTypes)
+	MarketDataRequestCmd.RegisterFlagCompletionFunc("timestamp-format", complete.TimestampFormats)
+	MarketDataRequestCmd.RegisterFlagCompletionFunc("output", complete.OutputFormats)
+	MarketDataRequestCmd.RegisterFlagCompletionFunc("on-disconnect", complete.DisconnectPolicies)
+	MarketDataRequestCmd.RegisterFlagCompletionFunc("msg-appl-ver-id", complete.ApplVerIDs)
 }
 
 func Validate(cmd *cobra.Command, args []string) error {
@@ -69,19 +215,61 @@ func Validate(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	if len(optionSymbols) == 0 {
+	if len(optionSymbols) == 0 && len(optionSecGroup) == 0 && len(optionSegmentID) == 0 {
 		return errors.OptionsNoSymbolGiven
 	}
 
+	nonASCIISymbols, err = checkSymbols(optionSymbols, optionNonASCII, optionStrict)
+	if err != nil {
+		return err
+	}
+
 	if len(optionTypes) == 0 {
 		return errors.OptionsNoTypeGiven
 	}
 
-	for _, t := range optionTypes {
-		if _, ok := dict.MDEntryTypes[strings.ToUpper(t)]; !ok {
-			return fmt.Errorf("%w: unknown type `%s`", errors.Options, t)
+	// Types can be given as comma separated lists as well as by repeating
+	// the flag, types given several times are only requested once provided
+	// they are given the same depth
+	types := make([]string, 0, len(optionTypes))
+	seen := make(map[string]int, len(optionTypes))
+	TypeGroups = nil
+	for _, arg := range optionTypes {
+		for _, raw := range strings.Split(arg, ",") {
+			raw = strings.TrimSpace(raw)
+			if len(raw) == 0 {
+				continue
+			}
+
+			t, rawDepth, hasDepth := strings.Cut(raw, ":")
+			if _, ok := dict.MDEntryTypes[strings.ToUpper(t)]; !ok {
+				return fmt.Errorf("%w: unknown type `%s`", errors.Options, t)
+			}
+
+			depth := optionDepth
+			if hasDepth {
+				var err error
+				if depth, err = strconv.Atoi(rawDepth); err != nil || depth < 0 {
+					return fmt.Errorf("%w: invalid market depth `%s` for type `%s`", errors.Options, rawDepth, t)
+				}
+			}
+
+			if seenDepth, ok := seen[strings.ToLower(t)]; ok {
+				if seenDepth != depth {
+					return fmt.Errorf("%w: type `%s` given with market depths %d and %d", errors.OptionsInconsistentValues, t, seenDepth, depth)
+				}
+				continue
+			}
+			seen[strings.ToLower(t)] = depth
+
+			TypeGroups = addTypeToGroups(TypeGroups, t, depth)
+			types = append(types, t)
 		}
 	}
+	if len(types) == 0 {
+		return errors.OptionsNoTypeGiven
+	}
+	optionTypes = types
 
 	var ok bool
 	if SubType, ok = dict.SubscriptionRequestTypes[strings.ToUpper(optionSubType)]; !ok {
@@ -92,17 +280,183 @@ func Validate(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("%w: unknown update type `%s`", errors.Options, optionUpdateType)
 	}
 
-	if len(optionMDReqID) == 0 {
-		uid := uuid.New()
-		optionMDReqID = uid.String()
+	if len(optionSince) > 0 {
+		if _, err := strconv.ParseUint(optionSince, 10, 64); err == nil {
+			SinceCursor = optionSince
+		} else if t, err := time.Parse(time.RFC3339Nano, optionSince); err == nil {
+			SinceCursor = t.UTC().Format("20060102-15:04:05.000")
+		} else {
+			return fmt.Errorf("%w: invalid cursor `%s`, expecting a sequence number or a RFC3339 timestamp", errors.Options, optionSince)
+		}
+	}
+
+	subIDs := make([]string, 0, len(optionFanSubIDs))
+	for _, id := range optionFanSubIDs {
+		id = strings.TrimSpace(id)
+		if len(id) == 0 {
+			return fmt.Errorf("%w: empty --fan-target-sub-id", errors.Options)
+		}
+		if utils.Search(subIDs, id) < 0 {
+			subIDs = append(subIDs, id)
+		}
+	}
+	optionFanSubIDs = subIDs
+
+	if len(optionFilter) > 0 {
+		var err error
+		if Filter, err = utils.ParseFilter(optionFilter, application.MarketDataFilterFields); err != nil {
+			return fmt.Errorf("%w: invalid filter: %s", errors.Options, err)
+		}
+	}
+
+	switch optionStrictDict {
+	case "", "warn", "error":
+	default:
+		return fmt.Errorf("%w: unknown strict dictionary level `%s`", errors.Options, optionStrictDict)
+	}
+
+	if utils.Search(application.OutputFormats, optionOutput) < 0 {
+		return fmt.Errorf("%w: unknown output format `%s`", errors.Options, optionOutput)
+	}
+
+	if optionOutAppend && len(optionOutFile) == 0 {
+		return fmt.Errorf("%w: --output-append requires --output-file", errors.OptionsInconsistentValues)
+	}
+
+	if optionCompress && len(optionOutFile) == 0 {
+		return fmt.Errorf("%w: --compress requires --output-file", errors.OptionsInconsistentValues)
+	}
+
+	if optionJSONPretty {
+		switch optionOutput {
+		case application.OutputCSV:
+			return fmt.Errorf("%w: --json-pretty can't be used with --output csv", errors.OptionsInconsistentValues)
+		case application.OutputTable:
+			optionOutput = application.OutputJSON
+		}
+	}
+
+	switch optionGroupMode {
+	case "flat":
+	case "json":
+		switch optionOutput {
+		case application.OutputCSV:
+			return fmt.Errorf("%w: --group json can't be used with --output csv", errors.OptionsInconsistentValues)
+		case application.OutputTable:
+			optionOutput = application.OutputJSON
+		}
+	default:
+		return fmt.Errorf("%w: unknown group mode `%s`", errors.Options, optionGroupMode)
+	}
+
+	switch optionOnDisconnect {
+	case "exit", "retry":
+	default:
+		return fmt.Errorf("%w: unknown disconnect policy `%s`", errors.Options, optionOnDisconnect)
+	}
+
+	if optionRetryAttempts < 0 {
+		return fmt.Errorf("%w: --retry-attempts can't be negative", errors.Options)
+	}
+
+	if len(optionApplVerID) > 0 {
+		if v, ok := dict.ApplVerIDs[strings.ToUpper(optionApplVerID)]; ok {
+			MsgApplVerID = v
+		} else if _, err := dict.SearchValue(dict.ApplVerIDs, enum.ApplVerID(optionApplVerID)); err == nil {
+			MsgApplVerID = enum.ApplVerID(optionApplVerID)
+		} else {
+			return fmt.Errorf("%w: unknown ApplVerID `%s`", errors.Options, optionApplVerID)
+		}
+	}
+
+	if optionDepth < 0 {
+		return fmt.Errorf("%w: --market-depth can't be negative", errors.Options)
+	}
+
+	if cmd.Flags().Changed("aggregated-book") {
+		qualifiers[tag.AggregatedBook] = string(enum.AggregatedBook_NO)
+		if optionAggregated {
+			qualifiers[tag.AggregatedBook] = string(enum.AggregatedBook_YES)
+		}
+	}
+
+	if cmd.Flags().Changed("implicit-delete") {
+		qualifiers[tag.MDImplicitDelete] = string(enum.MDImplicitDelete_NO)
+		if optionImplDelete {
+			qualifiers[tag.MDImplicitDelete] = string(enum.MDImplicitDelete_YES)
+		}
+	}
+
+	if optionRespTmout < 0 {
+		return fmt.Errorf("%w: --response-timeout can't be negative", errors.Options)
+	}
+
+	if optionIdleTmout < 0 {
+		return fmt.Errorf("%w: --idle-timeout can't be negative", errors.Options)
+	}
+
+	if optionHold < 0 {
+		return fmt.Errorf("%w: --hold can't be negative", errors.Options)
+	}
+
+	if optionPostLogonDelay < 0 {
+		return fmt.Errorf("%w: --post-logon-delay can't be negative", errors.Options)
+	}
+
+	if len(optionRateLimit) > 0 {
+		rate, err := utils.ParseRate(optionRateLimit)
+		if err != nil {
+			return fmt.Errorf("%w: --rate-limit: %s", errors.Options, err)
+		}
+		RateLimiter = utils.NewRateLimiter(rate, 1)
+	}
+
+	if optionMaxSymbols < 0 {
+		return fmt.Errorf("%w: --max-symbols-per-request can't be negative", errors.Options)
+	}
+
+	if len(optionTimeFormat) == 0 {
+		return fmt.Errorf("%w: empty timestamp format", errors.Options)
+	}
+
+	if optionMaxRejects < 0 {
+		return fmt.Errorf("%w: --stop-after-reject-count can't be negative", errors.Options)
+	}
+
+	if cmd.Flags().Changed("id-seed") {
+		utils.SeedIDs(optionIDSeed)
+	}
+
+	if err := mdReqIDOptions.Validate(); err != nil {
+		return err
+	}
+
+	if optionRetryDupID && !mdReqIDOptions.Autogen() {
+		return fmt.Errorf("%w: --retry-dup-id can't be used with --%s", errors.OptionsInconsistentValues, mdReqIDOptions.NoAutogenFlag())
 	}
 
 	return nil
 }
 
 func Execute(cmd *cobra.Command, args []string) error {
+	summary = NewSummary()
+
+	err := execute(cmd, args)
+
+	// The summary is written whatever the outcome of the run
+	if len(optionSummary) > 0 {
+		if werr := summary.Write(optionSummary, err); werr != nil {
+			config.GetLogger().Error().Err(werr).Msg("Unable to write summary")
+		}
+	}
+
+	return err
+}
+
+func execute(cmd *cobra.Command, args []string) error {
 	options := config.GetOptions()
-	logger := config.GetLogger()
+	ctx, cancel := utils.WithMaxRuntime(cmd.Context(), options.MaxRuntime)
+	defer cancel()
 
 	context, err := config.GetCurrentContext()
 	if err != nil {
@@ -114,140 +468,1045 @@ func Execute(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	// Symbol patterns are expanded here and not in Validate as they require
+	// the configuration to be loaded.
+	optionSymbols, err = expandSymbols(context, optionSymbols)
+	if err != nil {
+		return err
+	}
+
+	// The context default subscription type only applies when --sub-type
+	// was not given explicitly
+	if !cmd.Flags().Changed("sub-type") && len(context.DefaultSubscriptionType) > 0 {
+		subType, ok := dict.SubscriptionRequestTypes[strings.ToUpper(context.DefaultSubscriptionType)]
+		if !ok {
+			return fmt.Errorf("%w: context %s: unknown defaultSubscriptionType `%s`", errors.Config, context.Name, context.DefaultSubscriptionType)
+		}
+		optionSubType = context.DefaultSubscriptionType
+		SubType = subType
+	}
+
+	if optionHold > 0 && SubType != enum.SubscriptionRequestType_SNAPSHOT_PLUS_UPDATES {
+		return fmt.Errorf("%w: --hold requires a snapshot_plus_updates subscription", errors.OptionsInconsistentValues)
+	}
+
+	// Venues usually expect group level subscriptions to come without symbols
+	groupLevel := len(optionSecGroup) > 0 || len(optionSegmentID) > 0
+	if groupLevel && len(optionSymbols) > 0 && !context.GroupSubscriptionWithSymbols {
+		return fmt.Errorf("%w: --security-group and --market-segment-id can't be used with --symbol unless the context enables groupSubscriptionWithSymbols", errors.OptionsInconsistentValues)
+	}
+
 	ctxInitiator, err := context.GetInitiator()
 	if err != nil {
 		return err
 	}
 
+	if optionRelogon && !sessions[0].HasWindow() {
+		return fmt.Errorf("%w: --relogon-schedule requires StartTime and EndTime to be set for session %s", errors.OptionsInconsistentValues, sessions[0].Name)
+	}
+
+	// quickfix stamps the TargetSubID of the session on every message it sends
+	if len(optionFanSubIDs) > 0 && len(sessions[0].TargetSubID) > 0 {
+		return fmt.Errorf("%w: --fan-target-sub-id can't be used with session %s which has a TargetSubID", errors.OptionsInconsistentValues, sessions[0].Name)
+	}
+
+	if Profile, err = context.GetConformanceProfile(); err != nil {
+		return err
+	}
+
 	session := sessions[0]
+	summary.SetSession(session)
+
+	if len(SinceCursor) > 0 && !session.MarketDataResume {
+		return fmt.Errorf("%w: --since requires MarketDataResume to be enabled for session %s", errors.Options, session.Name)
+	}
+
+	if len(MsgApplVerID) > 0 && session.BeginString != quickfix.BeginStringFIXT11 {
+		return fmt.Errorf("%w: --msg-appl-ver-id requires a %s session", errors.OptionsInconsistentValues, quickfix.BeginStringFIXT11)
+	}
+
+	fields := map[string]interface{}{
+		"session":      session.Name,
+		"symbol_count": len(optionSymbols),
+	}
+
+	// Several requests are sent as <id>-<n> and duplicates sent again under a
+	// new id
+	logRequestIDs = requestCount(session) > 1 || optionRetryDupID
+	if !logRequestIDs {
+		fields["mdreqid"] = optionMDReqID
+	}
+
+	logger := config.GetChildLogger(fields)
+
+	for _, sym := range nonASCIISymbols {
+		logger.Warn().Msgf("Symbol %q contains non-ASCII characters which venues may reject", sym)
+	}
+
+	if err := checkSupportedTypes(logger, context.SupportedMDEntryTypes); err != nil {
+		return err
+	}
+
+	if optionSortTypes {
+		for i := range TypeGroups {
+			sortTypes(TypeGroups[i].Types, context.MDEntryTypeOrder)
+		}
+	}
+
+	// Symbols are checked against the cache unless it is about to be refreshed
+	securityCache = config.SecurityCache{Context: context.Name, Session: session.Name}
+	securityCachePath = context.SecurityCachePath(session)
+	if !optionRefreshSec && len(optionSymbols) > 0 {
+		cache, err := config.ReadSecurityCache(securityCachePath)
+		if err != nil {
+			return err
+		}
+		if err := checkCachedSymbols(logger, cache); err != nil {
+			return err
+		}
+	}
+
 	transportDict, appDict, err := session.GetFIXDictionaries()
 	if err != nil {
 		return err
 	}
 
+	layout, err := loadGroupTemplates(context, transportDict, appDict)
+	if err != nil {
+		return err
+	}
+
+	// Make sure the groups used by the request exist in the negotiated version
+	groups := map[quickfix.Tag][]quickfix.Tag{
+		tag.NoMDEntryTypes: {tag.MDEntryType},
+		tag.NoRelatedSym:   {tag.Symbol},
+	}
+	if len(optionSecGroup) > 0 {
+		groups[tag.NoRelatedSym] = append(groups[tag.NoRelatedSym], tag.SecurityGroup)
+	}
+	if len(optionSegmentID) > 0 {
+		groups[tag.NoRelatedSym] = append(groups[tag.NoRelatedSym], tag.MarketSegmentID)
+	}
+	if err := checkGroups(layout, appDict, string(enum.MsgType_MARKET_DATA_REQUEST), groups); err != nil {
+		return err
+	}
+
+	if len(SinceCursor) > 0 {
+		if SinceTag, err = resolveSinceTag(*session, appDict); err != nil {
+			return err
+		}
+	}
+
 	settings, err := context.ToQuickFixInitiatorSettings()
 	if err != nil {
 		return err
 	}
 
-	app := application.NewMarketDataRequest(optionPrintData)
-	app.Logger = logger
-	app.Settings = settings
-	app.TransportDataDictionary = transportDict
-	app.AppDataDictionary = appDict
+	var rawOut *os.File
+	if len(optionRawOut) > 0 {
+		rawOut, err = os.Create(optionRawOut)
+		if err != nil {
+			return fmt.Errorf("%w: %s", errors.Options, err)
+		}
+		defer rawOut.Close()
+	}
+
+	var out io.Writer
+	if len(optionOutFile) > 0 {
+		flags := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+		if optionOutAppend {
+			flags = os.O_CREATE | os.O_WRONLY | os.O_APPEND
+		}
+
+		outFile, err := os.OpenFile(optionOutFile, flags, 0o644)
+		if err != nil {
+			return fmt.Errorf("%w: %s", errors.Options, err)
+		}
+		defer func() {
+			outFile.Sync()
+			outFile.Close()
+		}()
+		out = outFile
+
+		// Appending to a gzip file adds a new member which gzip readers
+		// handle transparently
+		if optionCompress || strings.HasSuffix(optionOutFile, ".gz") {
+			gz := gzip.NewWriter(outFile)
+			defer func() {
+				if err := gz.Close(); err != nil {
+					logger.Error().Err(err).Msg("Unable to close compressed output")
+				}
+			}()
+			out = gz
+		}
+	}
+
+	// Choose right app chan buffer cli option > config > default value
+	chanBuffer := optionChanBuffer
+	if !cmd.Flags().Changed("app-chan-buffer") && ctxInitiator.AppChanBuffer > 0 {
+		chanBuffer = ctxInitiator.AppChanBuffer
+	}
+	if chanBuffer < 0 {
+		return fmt.Errorf("%w: app chan buffer can't be negative", errors.Options)
+	}
+
+	newApp := func() *application.MarketDataRequest {
+		app := application.NewMarketDataRequest(optionPrintData, chanBuffer)
+		app.Logger = logger
+		app.Settings = settings
+		app.TransportDataDictionary = transportDict
+		app.AppDataDictionary = appDict
+		app.TimestampFormat = optionTimeFormat
+		app.Output = optionOutput
+		app.JSONPretty = optionJSONPretty
+		app.GroupJSON = optionGroupMode == "json"
+		app.Trace = optionTrace
+		app.Filter = Filter
+		app.LabelTargetSubID = len(optionFanSubIDs) > 0
+		app.ShowSeqNum = optionShowSeqNum
+		if rawOut != nil {
+			app.RawOut = rawOut
+		}
+		if out != nil {
+			app.Out = out
+		}
+
+		return app
+	}
 
 	var quickfixLogger *zerolog.Logger
 	if options.QuickFixLogging {
 		quickfixLogger = logger
 	}
 
+	// Choose right timeout cli option > config > default value (5s)
+	var timeout time.Duration
+	if options.Timeout != time.Duration(0) {
+		timeout = options.Timeout
+	} else if ctxInitiator.SocketTimeout != time.Duration(0) {
+		timeout = ctxInitiator.SocketTimeout
+	} else {
+		timeout = 5 * time.Second
+	}
+
+	// Signals are relayed so that the summary knows about them
+	signals := make(chan os.Signal, 1)
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		for sig := range signals {
+			summary.SetSignal(sig)
+			interrupt <- sig
+		}
+	}()
+
+	recovered := false
+	for attempt := 0; ; attempt++ {
+		app := newApp()
+		disconnected, err := run(ctx, logger, app, settings, quickfixLogger, session, layout, timeout, interrupt)
+
+		if reason := app.SeqNumMismatch(); err != nil && len(reason) > 0 {
+			if !optionRecoverSeq || recovered {
+				return fmt.Errorf("%w: %s", errors.FixSeqNumMismatch, reason)
+			}
+
+			logger.Warn().Msgf("Logon failed on sequence number mismatch (%s), logging on again with sequence numbers reset", reason)
+
+			// ResetOnLogon resets the store and asks the acceptor to reset
+			// its sequence numbers as well
+			session.ResetOnLogon = true
+			settings, err = context.ToQuickFixInitiatorSettings()
+			if err != nil {
+				return err
+			}
+
+			recovered = true
+			attempt--
+			continue
+		}
+
+		retryable := disconnected || errors.Is(err, errors.FixLogout) || errors.Is(err, errors.ConnectionTimeout)
+
+		// Logouts outside of the session window are the scheduled ones
+		if optionRelogon && retryable {
+			inWindow, werr := session.InWindow(time.Now())
+			if werr != nil {
+				return werr
+			}
+
+			if !inWindow {
+				next, werr := session.NextWindowStart(time.Now())
+				if werr != nil {
+					return werr
+				}
+
+				logger.Info().Msgf("Session window closed, logging on again at %s", next.Format(time.RFC3339))
+
+				select {
+				case <-ctx.Done():
+					return errors.MaxRuntimeExceeded
+				case signal := <-interrupt:
+					logger.Debug().Msgf("Received signal: %s", signal)
+					return nil
+				case <-time.After(time.Until(next)):
+				}
+
+				attempt = -1
+				continue
+			}
+		}
+
+		if optionOnDisconnect != "retry" || !retryable {
+			return err
+		}
+
+		if attempt >= optionRetryAttempts {
+			logger.Warn().Msgf("Giving up after %d retry attempt(s)", attempt)
+			return err
+		}
+
+		logger.Warn().Msgf("Session disconnected, retrying in %s (%d/%d)", optionRetryInterval, attempt+1, optionRetryAttempts)
+
+		select {
+		case <-ctx.Done():
+			return errors.MaxRuntimeExceeded
+		case signal := <-interrupt:
+			logger.Debug().Msgf("Received signal: %s", signal)
+			return nil
+		case <-time.After(optionRetryInterval):
+		}
+	}
+}
+
+// run initiates a session, sends the market data request(s) and processes the
+// responses. It returns true if the session got disconnected after the requests
+// were sent.
+func run(ctx gocontext.Context, logger *zerolog.Logger, app *application.MarketDataRequest, settings *quickfix.Settings, quickfixLogger *zerolog.Logger, session *config.Session, layout *groupLayout, timeout time.Duration, interrupt chan os.Signal) (bool, error) {
 	init, err := initiator.Initiate(app, settings, quickfixLogger)
 	if err != nil {
-		return err
+		return false, err
 	}
 
 	// Start session
 	err = init.Start()
 	if err != nil {
-		return err
+		return false, err
 	}
 
 	defer func() {
 		app.Stop()
 		init.Stop()
-	}()
 
-	// Choose right timeout cli option > config > default value (5s)
-	var timeout time.Duration
-	if options.Timeout != time.Duration(0) {
-		timeout = options.Timeout
-	} else if ctxInitiator.SocketTimeout != time.Duration(0) {
-		timeout = ctxInitiator.SocketTimeout
-	} else {
-		timeout = 5 * time.Second
-	}
+		// Unregister the session so that it can be initiated again on retry
+		quickfix.UnregisterSession(app.SessionID)
+	}()
 
 	// Wait for session connection
 	select {
+	case <-ctx.Done():
+		return false, errors.MaxRuntimeExceeded
+	case signal := <-interrupt:
+		logger.Debug().Msgf("Received signal: %s", signal)
+		return false, nil
 	case <-time.After(timeout):
-		return errors.ConnectionTimeout
+		return false, errors.ConnectionTimeout
 	case _, ok := <-app.Connected:
 		if !ok {
-			return errors.FixLogout
+			return false, errors.FixLogout
 		}
 	}
 
-	// Prepare securitylist
-	securitylist, err := buildMessage(*session)
-	if err != nil {
-		return err
+	if optionShowInfo {
+		logger.Info().
+			Int("heartbtint", app.LogonInfo.HeartBtInt).
+			Int("sent_seqnum", app.LogonInfo.SentSeqNum).
+			Int("received_seqnum", app.LogonInfo.ReceivedSeqNum).
+			Str("applverid", app.LogonInfo.ApplVerID).
+			Msg("Session info")
 	}
 
-	// Send the order
-	err = quickfix.Send(securitylist)
-	if err != nil {
-		return err
+	if err := checkApplVerID(logger, session.DefaultApplVerID, app.LogonInfo.ApplVerID); err != nil {
+		return false, err
 	}
 
-	interrupt := make(chan os.Signal, 1)
-	signal.Notify(interrupt, os.Interrupt, syscall.SIGTERM)
+	// Some venues reject business messages received right after the logon
+	if optionPostLogonDelay > 0 {
+		logger.Debug().Msgf("Waiting %s before sending the request", optionPostLogonDelay)
+
+		select {
+		case <-ctx.Done():
+			return false, errors.MaxRuntimeExceeded
+		case signal := <-interrupt:
+			logger.Debug().Msgf("Received signal: %s", signal)
+			return false, nil
+		case <-time.After(optionPostLogonDelay):
+		}
+	}
+
+	if optionRefreshSec && !securitiesFresh {
+		refreshed, err := refreshSecurities(ctx, logger, app, session, timeout, interrupt)
+		if err != nil || !refreshed {
+			return false, err
+		}
+	}
+
+	chunks := symbolChunks(session)
+	subIDs := targetSubIDs()
+
+	// MDReqIDs which have not received any response yet
+	requests := len(subIDs) * len(TypeGroups) * len(chunks)
+	pending := make(map[string]struct{}, requests)
+
+	// Requests by MDReqID so that they can be sent again with a new ID
+	specs := make(map[string]requestSpec, requests)
+
+	n := 0
+	for _, subID := range subIDs {
+		for _, group := range TypeGroups {
+			for _, symbols := range chunks {
+				n++
+				mdReqID := optionMDReqID
+				if requests > 1 {
+					mdReqID = fmt.Sprintf("%s-%d", optionMDReqID, n)
+				}
+				pending[mdReqID] = struct{}{}
+				specs[mdReqID] = requestSpec{group: group, symbols: symbols, targetSubID: subID, layout: layout}
+
+				sent, err := sendRequest(ctx, logger, app, session, interrupt, mdReqID, specs[mdReqID])
+				if err != nil || !sent {
+					return false, err
+				}
+			}
+		}
+	}
+
+	logger.Info().Msgf("MarketDataRequest sent in %d request(s)", requests)
+
+	if len(optionIDMapFile) > 0 {
+		if err := writeIDMap(optionIDMapFile, specs); err != nil {
+			return false, err
+		}
+	}
+
+	responses := 0
+	rejects := 0
+
+	var responseTimeout <-chan time.Time
+	if optionWaitAll && optionRespTmout > 0 {
+		responseTimeout = time.After(optionRespTmout)
+	}
+
+	var hold <-chan time.Time
+	if optionHold > 0 {
+		hold = time.After(optionHold)
+	}
+
+	var idleTimeout <-chan time.Time
+	var idleTimer *time.Timer
+	if optionIdleTmout > 0 {
+		idleTimer = time.NewTimer(optionIdleTmout)
+		defer idleTimer.Stop()
+		idleTimeout = idleTimer.C
+	}
 
-LOOP:
 	for {
 		select {
+		case <-ctx.Done():
+			return false, errors.MaxRuntimeExceeded
+		case signal := <-interrupt:
+			logger.Debug().Msgf("Received signal: %s", signal)
+			if optionHold > 0 {
+				return false, unsubscribe(logger, app, session, specs)
+			}
+			return false, nil
+		case <-hold:
+			logger.Info().Msgf("Held the subscription for %s, unsubscribing", optionHold)
+			return false, unsubscribe(logger, app, session, specs)
+		case <-idleTimeout:
+			logger.Warn().Msgf("No message received for %s", optionIdleTmout)
+			return false, errors.IdleTimeout
+		case <-responseTimeout:
+			missing := make([]string, 0, len(pending))
+			for id := range pending {
+				missing = append(missing, id)
+			}
+			sort.Strings(missing)
+			logger.Warn().Strs("mdreqids", missing).Msgf("%d request(s) did not get any response", len(missing))
+			return false, errors.ResponseTimeout
+		case msg, ok := <-app.FromAppMessages:
+			if !ok {
+				return true, nil
+			}
+
+			app.ObserveChanDepth()
+			summary.AddMessage(msg.ToMessage())
+
+			if idleTimer != nil {
+				if !idleTimer.Stop() {
+					<-idleTimer.C
+				}
+				idleTimer.Reset(optionIdleTmout)
+			}
+
+			if len(optionStrictDict) > 0 {
+				if unknown := utils.UnknownTags(msg.ToMessage(), app.TransportDataDictionary, app.AppDataDictionary); len(unknown) > 0 {
+					if optionStrictDict == "error" {
+						return false, fmt.Errorf("%w: %v", errors.FixUnknownTags, unknown)
+					}
+					logger.Warn().Ints("tags", unknown).Msg("Inbound message contains tags unknown to the dictionary")
+				}
+			}
+
+			if id, err := msg.ToMessage().Body.GetString(tag.MDReqID); err == nil {
+				delete(pending, id)
+
+				// Duplicate MDReqIDs sent again under a new id are not
+				// counted as rejects
+				if spec, ok := specs[id]; ok && optionRetryDupID && !spec.retried && isDuplicateMDReqIDReject(msg.ToMessage()) {
+					newID, err := mdReqIDOptions.Generate()
+					if err != nil {
+						return false, err
+					}
+					logger.Warn().Msgf("MDReqID %s rejected as duplicate, sending again as %s", id, newID)
+
+					spec.retried = true
+					specs[newID] = spec
+					pending[newID] = struct{}{}
+					delete(specs, id)
+
+					sent, err := sendRequest(ctx, logger, app, session, interrupt, newID, spec)
+					if err != nil || !sent {
+						return false, err
+					}
+					continue
+				}
+			}
+
+			if optionMaxRejects > 0 && isReject(msg.ToMessage()) {
+				rejects++
+				logger.Warn().Msgf("Reject %d/%d received", rejects, optionMaxRejects)
+
+				if rejects >= optionMaxRejects {
+					return false, fmt.Errorf("%w: %d reject(s) received", errors.FixMarketDataRequestRejected, rejects)
+				}
+			}
+
+			if optionWaitAll {
+				if len(pending) == 0 {
+					logger.Info().Msg("All requests got a response")
+					return false, nil
+				}
+				continue
+			}
+
+			responses++
+			if SubType == enum.SubscriptionRequestType_SNAPSHOT && responses >= requests {
+				return false, nil
+			}
+		}
+	}
+}
+
+// symbolChunks splits the symbols in chunks if the venue caps the number of
+// symbols per request.
+func symbolChunks(session *config.Session) [][]string {
+	maxSymbols := optionMaxSymbols
+	if maxSymbols == 0 {
+		maxSymbols = session.MaxSymbolsPerRequest
+	}
+
+	return utils.Chunk(optionSymbols, maxSymbols)
+}
+
+// targetSubIDs returns the TargetSubIDs requests are fanned out to, an empty
+// one standing for the session's.
+func targetSubIDs() []string {
+	if len(optionFanSubIDs) == 0 {
+		return []string{""}
+	}
+
+	return optionFanSubIDs
+}
+
+// requestCount returns the number of requests sent, each under its own MDReqID.
+func requestCount(session *config.Session) int {
+	return len(targetSubIDs()) * len(TypeGroups) * len(symbolChunks(session))
+}
+
+// requestLogger returns the logger of the request sent under mdReqID.
+func requestLogger(logger *zerolog.Logger, mdReqID string) *zerolog.Logger {
+	if !logRequestIDs {
+		return logger
+	}
+
+	l := logger.With().Str("mdreqid", mdReqID).Logger()
+
+	return &l
+}
+
+// requestSpec holds what is needed to build a market data request.
+type requestSpec struct {
+	group       TypeGroup
+	symbols     []string
+	targetSubID string
+	layout      *groupLayout
+	retried     bool
+}
+
+// sendRequest builds, validates and sends a market data request. It returns
+// false if it got interrupted while waiting for the rate limiter.
+func sendRequest(ctx gocontext.Context, logger *zerolog.Logger, app *application.MarketDataRequest, session *config.Session, interrupt chan os.Signal, mdReqID string, spec requestSpec) (bool, error) {
+	logger = requestLogger(logger, mdReqID)
+
+	request, err := buildMessage(logger, *session, app.AppDataDictionary, spec.layout, mdReqID, spec.group, spec.symbols)
+	if err != nil {
+		return false, err
+	}
+
+	if len(spec.targetSubID) > 0 {
+		request.Header.Set(field.NewTargetSubID(spec.targetSubID))
+	}
+
+	if optionValidate {
+		err = utils.ValidateOutgoingMessage(request, session.BeginString, app.TransportDataDictionary, app.AppDataDictionary)
+		if err != nil {
+			return false, err
+		}
+	}
+
+	if err = Profile.Validate(request, app.AppDataDictionary, app.TransportDataDictionary); err != nil {
+		return false, err
+	}
+
+	// Pace requests to stay within the venue message rate
+	if delay := RateLimiter.Reserve(); delay > 0 {
+		select {
+		case <-ctx.Done():
+			return false, errors.MaxRuntimeExceeded
 		case signal := <-interrupt:
 			logger.Debug().Msgf("Received signal: %s", signal)
+			return false, nil
+		case <-time.After(delay):
+		}
+	}
+
+	// Send the market data request
+	// The session is given explicitly as fanned out requests don't carry the
+	// TargetSubID of the session
+	err = quickfix.SendToTarget(request, app.SessionID)
+	if err != nil {
+		return false, err
+	}
+
+	summary.AddRequest(mdReqID)
+
+	if optionEcho || optionTrace {
+		app.PrintRequest(request)
+	}
+
+	return true, nil
+}
+
+// unsubscribe sends a request disabling the subscription of every MDReqID in
+// specs, the session being logged off once it returns.
+func unsubscribe(logger *zerolog.Logger, app *application.MarketDataRequest, session *config.Session, specs map[string]requestSpec) error {
+	ids := make([]string, 0, len(specs))
+	for id := range specs {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	for _, id := range ids {
+		spec := specs[id]
+		request, err := buildMessage(requestLogger(logger, id), *session, app.AppDataDictionary, spec.layout, id, spec.group, spec.symbols)
+		if err != nil {
+			return err
+		}
+
+		request.Body.Set(field.NewSubscriptionRequestType(enum.SubscriptionRequestType_DISABLE_PREVIOUS_SNAPSHOT_PLUS_UPDATE_REQUEST))
+		if len(spec.targetSubID) > 0 {
+			request.Header.Set(field.NewTargetSubID(spec.targetSubID))
+		}
+
+		if err := quickfix.SendToTarget(request, app.SessionID); err != nil {
+			return err
+		}
+
+		if optionEcho || optionTrace {
+			app.PrintRequest(request)
+		}
+	}
+
+	logger.Info().Msgf("Unsubscribed %d request(s)", len(ids))
+
+	return nil
+}
+
+// refreshSecurities requests the list of all the securities, writes it to the
+// security cache and checks the symbols against it. It returns false if
+// interrupted.
+func refreshSecurities(ctx gocontext.Context, logger *zerolog.Logger, app *application.MarketDataRequest, session *config.Session, timeout time.Duration, interrupt chan os.Signal) (bool, error) {
+	request := quickfix.NewMessage()
+	request.Header.Set(field.NewMsgType(enum.MsgType_SECURITY_LIST_REQUEST))
+	request.Body.Set(field.NewSecurityReqID(uuid.NewString()))
+	request.Body.Set(field.NewSecurityListRequestType(enum.SecurityListRequestType_ALL_SECURITIES))
+
+	utils.QuickFixMessagePartSetString(&request.Header, session.TargetCompID, field.NewTargetCompID)
+	utils.QuickFixMessagePartSetString(&request.Header, session.TargetSubID, field.NewTargetSubID)
+	utils.QuickFixMessagePartSetString(&request.Header, session.SenderCompID, field.NewSenderCompID)
+	utils.QuickFixMessagePartSetString(&request.Header, session.SenderSubID, field.NewSenderSubID)
+
+	if err := quickfix.SendToTarget(request, app.SessionID); err != nil {
+		return false, err
+	}
+
+	var response *quickfix.Message
+	select {
+	case <-ctx.Done():
+		return false, errors.MaxRuntimeExceeded
+	case signal := <-interrupt:
+		logger.Debug().Msgf("Received signal: %s", signal)
+		return false, nil
+	case <-time.After(timeout):
+		return false, fmt.Errorf("%w: no security list received", errors.ResponseTimeout)
+	case _, ok := <-app.Connected:
+		if !ok {
+			return false, errors.FixLogout
+		}
+	case response = <-app.SecurityLists:
+	}
+
+	tree := utils.MessageTree(response, app.TransportDataDictionary, app.AppDataDictionary)
+	cache := securityCache
+	cache.Updated = time.Now()
+	cache.Symbols = utils.MessageTreeGroupValues(tree, int(tag.NoRelatedSym), int(tag.Symbol))
+	if err := cache.Write(securityCachePath); err != nil {
+		logger.Warn().Err(err).Msg("Unable to cache the security list")
+	}
+
+	logger.Debug().Msgf("Security cache refreshed with %d symbol(s)", len(cache.Symbols))
+	securitiesFresh = true
+
+	return true, checkCachedSymbols(logger, &cache)
+}
+
+// checkCachedSymbols checks that the requested symbols are in the security
+// cache, if any.
+func checkCachedSymbols(logger *zerolog.Logger, cache *config.SecurityCache) error {
+	if cache == nil {
+		return nil
+	}
+
+	unknown := cache.Unknown(optionSymbols)
+	if len(unknown) == 0 {
+		return nil
+	}
+
+	if optionStrict {
+		return fmt.Errorf("%w: symbol(s) %s not in the security list cached on %s, use --refresh-securities to fetch it again", errors.Options, strings.Join(unknown, ", "), cache.Updated.Format(time.RFC3339))
+	}
+
+	logger.Warn().Msgf("Symbol(s) %s not in the security list cached on %s", strings.Join(unknown, ", "), cache.Updated.Format(time.RFC3339))
+
+	return nil
+}
+
+// checkSymbols rejects the symbols containing control characters and returns
+// the ones containing non-ASCII characters, which are rejected as well if
+// strict is set, unless allowNonASCII is set.
+func checkSymbols(symbols []string, allowNonASCII bool, strict bool) ([]string, error) {
+	nonASCII := []string{}
+
+	for _, sym := range symbols {
+		switch {
+		case strings.IndexFunc(sym, unicode.IsControl) >= 0:
+			return nil, fmt.Errorf("%w: symbol %q contains control characters", errors.Options, sym)
+		case allowNonASCII:
+		case strings.IndexFunc(sym, func(r rune) bool { return r > unicode.MaxASCII }) >= 0:
+			if strict {
+				return nil, fmt.Errorf("%w: symbol %q contains non-ASCII characters, use --allow-non-ascii if the venue permits them", errors.Options, sym)
+			}
+			nonASCII = append(nonASCII, sym)
+		}
+	}
+
+	return nonASCII, nil
+}
+
+// isReject returns true if msg is a session, business or market data request
+// reject.
+func isReject(msg *quickfix.Message) bool {
+	typ, err := msg.MsgType()
+	if err != nil {
+		return false
+	}
+
+	switch enum.MsgType(typ) {
+	case enum.MsgType_REJECT, enum.MsgType_BUSINESS_MESSAGE_REJECT, enum.MsgType_MARKET_DATA_REQUEST_REJECT:
+		return true
+	}
+
+	return false
+}
+
+// isDuplicateMDReqIDReject returns true if msg is a MarketDataRequestReject
+// with a duplicate MDReqID reason.
+func isDuplicateMDReqIDReject(msg *quickfix.Message) bool {
+	typ, err := msg.MsgType()
+	if err != nil || enum.MsgType(typ) != enum.MsgType_MARKET_DATA_REQUEST_REJECT {
+		return false
+	}
+
+	reason, err := msg.Body.GetString(tag.MDReqRejReason)
+
+	return err == nil && enum.MDReqRejReason(reason) == enum.MDReqRejReason_DUPLICATE_MDREQID
+}
+
+// addTypeToGroups adds the type t to the group of the given depth, creating
+// the group if needed.
+func addTypeToGroups(groups []TypeGroup, t string, depth int) []TypeGroup {
+	for i := range groups {
+		if groups[i].Depth == depth {
+			groups[i].Types = append(groups[i].Types, t)
+			return groups
+		}
+	}
+
+	return append(groups, TypeGroup{Depth: depth, Types: []string{t}})
+}
+
+// resolveSinceTag returns the tag the --since cursor is sent in, making sure it
+// is defined for MarketDataRequest when requests are validated and that its
+// type in the app dictionary matches the cursor: timestamps can only be sent in
+// a UTCTimestamp field, which the default ApplBegSeqNum is not.
+func resolveSinceTag(session config.Session, appDict *datadictionary.DataDictionary) (quickfix.Tag, error) {
+	resumeTag := tag.ApplBegSeqNum
+	if session.MarketDataResumeTag > 0 {
+		resumeTag = quickfix.Tag(session.MarketDataResumeTag)
+	}
+
+	typ := ""
+	if appDict != nil {
+		if ft, ok := appDict.FieldTypeByTag[int(resumeTag)]; ok {
+			typ = ft.Type
+		}
+	}
+
+	if optionValidate && !utils.MessageHasField(appDict, string(enum.MsgType_MARKET_DATA_REQUEST), resumeTag) {
+		return 0, fmt.Errorf("%w: tag %d is not defined for MarketDataRequest in the app dictionary, set MarketDataResumeTag of session %s to a field it defines or use --validate-outbound=false", errors.Config, resumeTag, session.Name)
+	}
+
+	_, err := strconv.ParseUint(SinceCursor, 10, 64)
+	timestamp := err != nil
+
+	switch {
+	case timestamp && len(typ) == 0:
+		return 0, fmt.Errorf("%w: a timestamp --since requires MarketDataResumeTag of session %s to name a UTCTimestamp field of the app dictionary", errors.OptionsInconsistentValues, session.Name)
+	case timestamp && typ != "UTCTIMESTAMP":
+		return 0, fmt.Errorf("%w: --since is a timestamp but tag %d is a %s field, expecting a sequence number", errors.OptionsInconsistentValues, resumeTag, typ)
+	case !timestamp && typ == "UTCTIMESTAMP":
+		return 0, fmt.Errorf("%w: --since is a sequence number but tag %d is a UTCTimestamp field, expecting a RFC3339 timestamp", errors.OptionsInconsistentValues, resumeTag)
+	}
+
+	return resumeTag, nil
+}
+
+// sortTypes sorts the types in the given order, types missing from it being
+// sorted after by MDEntryType value.
+func sortTypes(types []string, order []string) {
+	rank := func(t string) int {
+		for i, o := range order {
+			if strings.EqualFold(o, t) {
+				return i
+			}
+		}
+		return len(order)
+	}
 
-			break LOOP
-		case _, ok := <-app.FromAppMessages:
-			if !ok || SubType == enum.SubscriptionRequestType_SNAPSHOT {
-				break LOOP
+	sort.SliceStable(types, func(i, j int) bool {
+		ri, rj := rank(types[i]), rank(types[j])
+		if ri != rj {
+			return ri < rj
+		}
+
+		return dict.MDEntryTypes[strings.ToUpper(types[i])] < dict.MDEntryTypes[strings.ToUpper(types[j])]
+	})
+}
+
+// expandSymbols expands the symbols containing glob patterns against the
+// instruments listed in the context instrument file.
+func expandSymbols(context *config.Context, symbols []string) ([]string, error) {
+	var instruments []string
+	var err error
+
+	expanded := make([]string, 0, len(symbols))
+	for _, sym := range symbols {
+		if !strings.ContainsAny(sym, "*?") {
+			expanded = append(expanded, sym)
+			continue
+		}
+
+		if instruments == nil {
+			if len(context.InstrumentFile) == 0 {
+				return nil, fmt.Errorf("%w: symbol pattern `%s` given but no instrument file configured", errors.Options, sym)
+			}
+
+			instruments, err = context.GetInstruments()
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		matched := false
+		for _, instrument := range instruments {
+			if utils.GlobMatch(sym, instrument) {
+				expanded = append(expanded, instrument)
+				matched = true
 			}
 		}
+
+		if !matched {
+			return nil, fmt.Errorf("%w: symbol pattern `%s` matches no instrument", errors.Options, sym)
+		}
+	}
+
+	return expanded, nil
+}
+
+// checkSupportedTypes checks that the requested types are supported by the
+// venue if the context declares the types it supports.
+func checkSupportedTypes(logger *zerolog.Logger, supported []string) error {
+	if len(supported) == 0 {
+		return nil
+	}
+
+	for _, t := range optionTypes {
+		found := false
+		for _, s := range supported {
+			if strings.EqualFold(s, t) {
+				found = true
+				break
+			}
+		}
+
+		if found {
+			continue
+		}
+
+		if optionStrict {
+			return fmt.Errorf("%w: type `%s` not supported by the venue", errors.Options, t)
+		}
+
+		logger.Warn().Msgf("Type `%s` not supported by the venue", t)
 	}
 
 	return nil
 }
 
-func buildMessage(session config.Session) (quickfix.Messagable, error) {
-	mdReqID := field.NewMDReqID(optionMDReqID)
+// checkApplVerID compares the DefaultApplVerID configured for the session with
+// the one advertised by the acceptor on logon.
+func checkApplVerID(logger *zerolog.Logger, configured string, advertised string) error {
+	if len(advertised) == 0 {
+		logger.Info().Msg("Acceptor did not advertise any DefaultApplVerID")
+		return nil
+	}
+
+	negotiated := advertised
+	if v, err := dict.SearchValue(dict.ApplVerIDs, enum.ApplVerID(advertised)); err == nil {
+		negotiated = v
+	}
+	logger.Info().Msgf("Negotiated application version: %s", negotiated)
+
+	if len(configured) == 0 {
+		return nil
+	}
+
+	expected := configured
+	if v, ok := dict.ApplVerIDs[strings.ToUpper(configured)]; ok {
+		expected = string(v)
+	}
+
+	if expected == advertised {
+		return nil
+	}
+
+	if optionStrictVer {
+		return fmt.Errorf("%w: session configured with %s, acceptor advertised %s", errors.FixApplVerIDMismatch, configured, negotiated)
+	}
+
+	logger.Warn().Msgf("Session configured with %s but acceptor advertised %s", configured, negotiated)
+
+	return nil
+}
+
+func buildMessage(logger *zerolog.Logger, session config.Session, appDict *datadictionary.DataDictionary, layout *groupLayout, id string, group TypeGroup, symbols []string) (*quickfix.Message, error) {
+	mdReqID := field.NewMDReqID(id)
 	subReqType := field.NewSubscriptionRequestType(dict.SubscriptionRequestTypes[strings.ToUpper(optionSubType)])
-	marketDepth := field.NewMarketDepth(0)
+	marketDepth := field.NewMarketDepth(group.Depth)
 
 	// Message
 	message := quickfix.NewMessage()
 	header := fixt11.NewHeader(&message.Header)
 
 	header.Set(field.NewMsgType(enum.MsgType_MARKET_DATA_REQUEST))
+	if len(MsgApplVerID) > 0 {
+		header.Set(field.NewApplVerID(MsgApplVerID))
+	}
 	message.Body.Set(mdReqID)
 	message.Body.Set(subReqType)
 	message.Body.Set(marketDepth)
 	message.Body.Set(field.NewMDUpdateType(MDUpdateType))
 
-	entryTypes := quickfix.NewRepeatingGroup(
-		tag.NoMDEntryTypes,
-		quickfix.GroupTemplate{
-			quickfix.GroupElement(tag.MDEntryType),
-		},
-	)
+	// Optional qualifiers are omitted if the negotiated version does not
+	// define them for MarketDataRequest, they are walked in tag order so that
+	// warnings are logged in a stable order
+	qualifierTags := make([]quickfix.Tag, 0, len(qualifiers))
+	for t := range qualifiers {
+		qualifierTags = append(qualifierTags, t)
+	}
+	sort.Slice(qualifierTags, func(i, j int) bool { return qualifierTags[i] < qualifierTags[j] })
 
-	for _, t := range optionTypes {
+	for _, t := range qualifierTags {
+		v := qualifiers[t]
+		if !utils.MessageHasField(appDict, string(enum.MsgType_MARKET_DATA_REQUEST), t) {
+			logger.Warn().Msgf("Tag %d not supported by the app dictionary, omitted", t)
+			continue
+		}
+		message.Body.SetString(t, v)
+	}
+
+	if len(SinceCursor) > 0 {
+		message.Body.SetString(SinceTag, SinceCursor)
+	}
+
+	entryTypes := quickfix.NewRepeatingGroup(tag.NoMDEntryTypes, groupTemplate(layout, tag.NoMDEntryTypes))
+
+	for _, t := range group.Types {
 		entryTypes.Add().Set(field.NewMDEntryType(dict.MDEntryTypes[strings.ToUpper(t)]))
 	}
 
 	message.Body.SetGroup(entryTypes)
 
-	relatedSym := quickfix.NewRepeatingGroup(
-		tag.NoRelatedSym,
-		quickfix.GroupTemplate{
-			quickfix.GroupElement(tag.Symbol),
-		},
-	)
-	for _, sym := range optionSymbols {
-		relatedSym.Add().Set(field.NewSymbol(sym))
+	relatedSym := quickfix.NewRepeatingGroup(tag.NoRelatedSym, groupTemplate(layout, tag.NoRelatedSym))
+
+	// Group level subscriptions narrow each symbol entry, or make up a single
+	// entry when no symbol is given
+	setScope := func(entry *quickfix.Group) {
+		utils.QuickFixMessagePartSetString(entry, optionSecGroup, field.NewSecurityGroup)
+		utils.QuickFixMessagePartSetString(entry, optionSegmentID, field.NewMarketSegmentID)
+	}
+	for _, sym := range symbols {
+		entry := relatedSym.Add()
+		entry.Set(field.NewSymbol(sym))
+		setScope(entry)
+	}
+	if len(symbols) == 0 {
+		setScope(relatedSym.Add())
 	}
 	message.Body.SetGroup(relatedSym)
 
+	logger.Debug().Msgf("MarketDataRequest built with %d entry types", len(group.Types))
+
 	utils.QuickFixMessagePartSetString(&message.Header, session.TargetCompID, field.NewTargetCompID)
 	utils.QuickFixMessagePartSetString(&message.Header, session.TargetSubID, field.NewTargetSubID)
 	utils.QuickFixMessagePartSetString(&message.Header, session.SenderCompID, field.NewSenderCompID)
@@ -0,0 +1,479 @@
+package marketdatarequest
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"github.com/quickfixgo/enum"
+	"github.com/quickfixgo/quickfix"
+	"github.com/quickfixgo/tag"
+	"github.com/rs/zerolog"
+
+	"sylr.dev/fix/config"
+	"sylr.dev/fix/pkg/errors"
+	"sylr.dev/fix/pkg/testutils"
+	"sylr.dev/fix/pkg/utils"
+)
+
+// setRequestOptions sets the package level options buildMessage reads for the
+// duration of the test.
+func setRequestOptions(t *testing.T, subType string, updateType enum.MDUpdateType, secGroup string, quals map[quickfix.Tag]string) {
+	t.Helper()
+
+	oldSubType, oldUpdateType, oldSecGroup, oldSegmentID, oldQualifiers := optionSubType, MDUpdateType, optionSecGroup, optionSegmentID, qualifiers
+	t.Cleanup(func() {
+		optionSubType, MDUpdateType, optionSecGroup, optionSegmentID, qualifiers = oldSubType, oldUpdateType, oldSecGroup, oldSegmentID, oldQualifiers
+	})
+
+	if quals == nil {
+		quals = map[quickfix.Tag]string{}
+	}
+
+	optionSubType, MDUpdateType, optionSecGroup, optionSegmentID, qualifiers = subType, updateType, secGroup, "", quals
+}
+
+func TestBuildMessage(t *testing.T) {
+	transportDict, appDict := testutils.Dictionaries(t)
+	logger := zerolog.Nop()
+
+	session := config.Session{
+		Name:         "test",
+		BeginString:  quickfix.BeginStringFIXT11,
+		SenderCompID: "CLIENT",
+		TargetCompID: "VENUE",
+	}
+
+	tests := []struct {
+		name       string
+		symbols    []string
+		group      TypeGroup
+		subType    string
+		updateType enum.MDUpdateType
+		secGroup   string
+		qualifiers map[quickfix.Tag]string
+
+		wantFields  map[quickfix.Tag]string
+		wantTypes   []string
+		wantSymbols []string
+		wantEntries int
+	}{
+		{
+			name:       "single symbol snapshot",
+			symbols:    []string{"EUR/USD"},
+			group:      TypeGroup{Depth: 0, Types: []string{"bid", "offer"}},
+			subType:    "snapshot",
+			updateType: enum.MDUpdateType_FULL_REFRESH,
+			wantFields: map[quickfix.Tag]string{
+				tag.SubscriptionRequestType: string(enum.SubscriptionRequestType_SNAPSHOT),
+				tag.MarketDepth:             "0",
+				tag.MDUpdateType:            string(enum.MDUpdateType_FULL_REFRESH),
+			},
+			wantTypes:   []string{string(enum.MDEntryType_BID), string(enum.MDEntryType_OFFER)},
+			wantSymbols: []string{"EUR/USD"},
+			wantEntries: 1,
+		},
+		{
+			name:       "multiple symbols subscription",
+			symbols:    []string{"EUR/USD", "GBP/USD", "USD/JPY"},
+			group:      TypeGroup{Depth: 1, Types: []string{"bid", "offer", "trade"}},
+			subType:    "snapshot_plus_updates",
+			updateType: enum.MDUpdateType_INCREMENTAL_REFRESH,
+			wantFields: map[quickfix.Tag]string{
+				tag.SubscriptionRequestType: string(enum.SubscriptionRequestType_SNAPSHOT_PLUS_UPDATES),
+				tag.MarketDepth:             "1",
+				tag.MDUpdateType:            string(enum.MDUpdateType_INCREMENTAL_REFRESH),
+			},
+			wantTypes:   []string{string(enum.MDEntryType_BID), string(enum.MDEntryType_OFFER), string(enum.MDEntryType_TRADE)},
+			wantSymbols: []string{"EUR/USD", "GBP/USD", "USD/JPY"},
+			wantEntries: 3,
+		},
+		{
+			name:       "depth and qualifiers",
+			symbols:    []string{"EUR/USD"},
+			group:      TypeGroup{Depth: 5, Types: []string{"trade"}},
+			subType:    "snapshot_plus_updates",
+			updateType: enum.MDUpdateType_FULL_REFRESH,
+			qualifiers: map[quickfix.Tag]string{
+				tag.MDImplicitDelete: string(enum.MDImplicitDelete_YES),
+				tag.AggregatedBook:   string(enum.AggregatedBook_NO),
+			},
+			wantFields: map[quickfix.Tag]string{
+				tag.MarketDepth:      "5",
+				tag.AggregatedBook:   string(enum.AggregatedBook_NO),
+				tag.MDImplicitDelete: string(enum.MDImplicitDelete_YES),
+			},
+			wantTypes:   []string{string(enum.MDEntryType_TRADE)},
+			wantSymbols: []string{"EUR/USD"},
+			wantEntries: 1,
+		},
+		{
+			name:        "security group without symbol",
+			group:       TypeGroup{Depth: 0, Types: []string{"bid"}},
+			subType:     "snapshot",
+			updateType:  enum.MDUpdateType_FULL_REFRESH,
+			secGroup:    "FX",
+			wantTypes:   []string{string(enum.MDEntryType_BID)},
+			wantSymbols: []string{},
+			wantEntries: 1,
+		},
+		{
+			name:        "security group narrowing symbols",
+			symbols:     []string{"EUR/USD", "GBP/USD"},
+			group:       TypeGroup{Depth: 0, Types: []string{"offer"}},
+			subType:     "snapshot",
+			updateType:  enum.MDUpdateType_FULL_REFRESH,
+			secGroup:    "FX",
+			wantTypes:   []string{string(enum.MDEntryType_OFFER)},
+			wantSymbols: []string{"EUR/USD", "GBP/USD"},
+			wantEntries: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setRequestOptions(t, tt.subType, tt.updateType, tt.secGroup, tt.qualifiers)
+
+			msg, err := buildMessage(&logger, session, appDict, newGroupLayout(), "req-1", tt.group, tt.symbols)
+			if err != nil {
+				t.Fatalf("buildMessage() error = %v", err)
+			}
+
+			if err := utils.ValidateOutgoingMessage(msg, session.BeginString, transportDict, appDict); err != nil {
+				t.Fatalf("ValidateOutgoingMessage() error = %v", err)
+			}
+
+			parsed, err := utils.ParseRawMessage([]byte(msg.String()), transportDict, appDict)
+			if err != nil {
+				t.Fatalf("ParseRawMessage() error = %v", err)
+			}
+			tree := utils.MessageTree(parsed, transportDict, appDict)
+
+			want := map[quickfix.Tag]string{tag.MDReqID: "req-1"}
+			for k, v := range tt.wantFields {
+				want[k] = v
+			}
+			for k, v := range want {
+				if got := treeValue(tree, k); got != v {
+					t.Errorf("tag %d = %q, want %q", k, got, v)
+				}
+			}
+
+			if got := treeValue(tree, tag.NoMDEntryTypes); got != strconv.Itoa(len(tt.wantTypes)) {
+				t.Errorf("NoMDEntryTypes = %q, want %d", got, len(tt.wantTypes))
+			}
+			if got := utils.MessageTreeGroupValues(tree, int(tag.NoMDEntryTypes), int(tag.MDEntryType)); !reflect.DeepEqual(got, tt.wantTypes) {
+				t.Errorf("MDEntryType = %v, want %v", got, tt.wantTypes)
+			}
+
+			if got := treeValue(tree, tag.NoRelatedSym); got != strconv.Itoa(tt.wantEntries) {
+				t.Errorf("NoRelatedSym = %q, want %d", got, tt.wantEntries)
+			}
+			if got := utils.MessageTreeGroupValues(tree, int(tag.NoRelatedSym), int(tag.Symbol)); !reflect.DeepEqual(got, tt.wantSymbols) {
+				t.Errorf("Symbol = %v, want %v", got, tt.wantSymbols)
+			}
+
+			wantGroups := []string{}
+			for i := 0; len(tt.secGroup) > 0 && i < tt.wantEntries; i++ {
+				wantGroups = append(wantGroups, tt.secGroup)
+			}
+			if got := utils.MessageTreeGroupValues(tree, int(tag.NoRelatedSym), int(tag.SecurityGroup)); !reflect.DeepEqual(got, wantGroups) {
+				t.Errorf("SecurityGroup = %v, want %v", got, wantGroups)
+			}
+		})
+	}
+}
+
+// treeValue returns the value of the top level field t of the message tree.
+func treeValue(tree []*utils.MessageField, t quickfix.Tag) string {
+	for _, f := range tree {
+		if f.Tag == int(t) {
+			return f.Value
+		}
+	}
+
+	return ""
+}
+
+func TestCheckSymbols(t *testing.T) {
+	tests := []struct {
+		name          string
+		symbols       []string
+		allowNonASCII bool
+		strict        bool
+		wantNonASCII  []string
+		wantErr       bool
+	}{
+		{
+			name:         "ascii",
+			symbols:      []string{"EUR/USD", "BTC-PERP", "ES Z4"},
+			wantNonASCII: []string{},
+		},
+		{
+			name:    "newline",
+			symbols: []string{"EUR/USD", "GBP\n/USD"},
+			wantErr: true,
+		},
+		{
+			name:    "soh delimiter",
+			symbols: []string{"EUR/USD\x01"},
+			wantErr: true,
+		},
+		{
+			name:          "control character with non-ASCII allowed",
+			symbols:       []string{"EUR\t/USD"},
+			allowNonASCII: true,
+			wantErr:       true,
+		},
+		{
+			name:         "non-ASCII",
+			symbols:      []string{"EUR/USD", "ÉUR/USD", "日経225"},
+			wantNonASCII: []string{"ÉUR/USD", "日経225"},
+		},
+		{
+			name:    "non-ASCII strict",
+			symbols: []string{"ÉUR/USD"},
+			strict:  true,
+			wantErr: true,
+		},
+		{
+			name:          "non-ASCII allowed",
+			symbols:       []string{"ÉUR/USD"},
+			allowNonASCII: true,
+			strict:        true,
+			wantNonASCII:  []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := checkSymbols(tt.symbols, tt.allowNonASCII, tt.strict)
+			if tt.wantErr {
+				if !errors.Is(err, errors.Options) {
+					t.Fatalf("checkSymbols() error = %v, want %v", err, errors.Options)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("checkSymbols() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.wantNonASCII) {
+				t.Errorf("checkSymbols() = %q, want %q", got, tt.wantNonASCII)
+			}
+		})
+	}
+}
+
+func TestSortTypes(t *testing.T) {
+	tests := []struct {
+		name  string
+		types []string
+		order []string
+		want  []string
+	}{
+		{
+			name:  "by value",
+			types: []string{"trade", "offer", "bid"},
+			want:  []string{"bid", "offer", "trade"},
+		},
+		{
+			name:  "by value letters after digits",
+			types: []string{"open_interest", "trade_volume", "trade"},
+			want:  []string{"trade", "trade_volume", "open_interest"},
+		},
+		{
+			name:  "context order",
+			types: []string{"bid", "offer", "trade"},
+			order: []string{"trade", "offer", "bid"},
+			want:  []string{"trade", "offer", "bid"},
+		},
+		{
+			name:  "context order case insensitive",
+			types: []string{"bid", "offer"},
+			order: []string{"OFFER", "Bid"},
+			want:  []string{"offer", "bid"},
+		},
+		{
+			name:  "types missing from the context order",
+			types: []string{"trade_volume", "bid", "trade", "offer"},
+			order: []string{"offer"},
+			want:  []string{"offer", "bid", "trade", "trade_volume"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sortTypes(tt.types, tt.order)
+			if !reflect.DeepEqual(tt.types, tt.want) {
+				t.Errorf("sortTypes() = %q, want %q", tt.types, tt.want)
+			}
+		})
+	}
+}
+
+func TestSinceCursor(t *testing.T) {
+	transportDict, appDict := testutils.Dictionaries(t)
+	logger := zerolog.Nop()
+
+	oldCursor, oldTag, oldValidate := SinceCursor, SinceTag, optionValidate
+	t.Cleanup(func() {
+		SinceCursor, SinceTag, optionValidate = oldCursor, oldTag, oldValidate
+	})
+
+	tests := []struct {
+		name      string
+		resumeTag int
+		cursor    string
+		validate  bool
+		wantTag   quickfix.Tag
+		wantErr   error
+	}{
+		{
+			name:     "default tag not in MarketDataRequest",
+			cursor:   "42",
+			validate: true,
+			wantErr:  errors.Config,
+		},
+		{
+			name:    "default tag without validation",
+			cursor:  "42",
+			wantTag: tag.ApplBegSeqNum,
+		},
+		{
+			name:      "sequence number in a MarketDataRequest field",
+			resumeTag: int(tag.ApplQueueMax),
+			cursor:    "42",
+			validate:  true,
+			wantTag:   tag.ApplQueueMax,
+		},
+		{
+			name:      "timestamp in a sequence number field",
+			resumeTag: int(tag.ApplQueueMax),
+			cursor:    "20230101-00:00:00.000",
+			validate:  true,
+			wantErr:   errors.OptionsInconsistentValues,
+		},
+		{
+			name:      "timestamp in a UTCTimestamp field",
+			resumeTag: int(tag.TransactTime),
+			cursor:    "20230101-00:00:00.000",
+			wantTag:   tag.TransactTime,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setRequestOptions(t, "snapshot_plus_updates", enum.MDUpdateType_INCREMENTAL_REFRESH, "", nil)
+			SinceCursor, optionValidate = tt.cursor, tt.validate
+
+			session := config.Session{
+				Name:                "test",
+				BeginString:         quickfix.BeginStringFIXT11,
+				SenderCompID:        "CLIENT",
+				TargetCompID:        "VENUE",
+				MarketDataResume:    true,
+				MarketDataResumeTag: tt.resumeTag,
+			}
+
+			var err error
+			SinceTag, err = resolveSinceTag(session, appDict)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("resolveSinceTag() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("resolveSinceTag() error = %v", err)
+			}
+			if SinceTag != tt.wantTag {
+				t.Fatalf("resolveSinceTag() = %d, want %d", SinceTag, tt.wantTag)
+			}
+
+			msg, err := buildMessage(&logger, session, appDict, newGroupLayout(), "req-1", TypeGroup{Types: []string{"bid"}}, []string{"EUR/USD"})
+			if err != nil {
+				t.Fatalf("buildMessage() error = %v", err)
+			}
+			if got, err := msg.Body.GetString(SinceTag); err != nil || got != tt.cursor {
+				t.Errorf("tag %d = %q, want %q", SinceTag, got, tt.cursor)
+			}
+
+			// What sendRequest checks before sending
+			if tt.validate {
+				if err := utils.ValidateOutgoingMessage(msg, session.BeginString, transportDict, appDict); err != nil {
+					t.Errorf("ValidateOutgoingMessage() error = %v", err)
+				}
+			}
+		})
+	}
+}
+
+func TestRequestLogger(t *testing.T) {
+	oldSymbols, oldMaxSymbols, oldSubIDs, oldGroups, oldLogIDs := optionSymbols, optionMaxSymbols, optionFanSubIDs, TypeGroups, logRequestIDs
+	t.Cleanup(func() {
+		optionSymbols, optionMaxSymbols, optionFanSubIDs, TypeGroups, logRequestIDs = oldSymbols, oldMaxSymbols, oldSubIDs, oldGroups, oldLogIDs
+	})
+
+	tests := []struct {
+		name       string
+		symbols    []string
+		maxSymbols int
+		subIDs     []string
+		groups     []TypeGroup
+		want       int
+	}{
+		{
+			name:    "single request",
+			symbols: []string{"EUR/USD", "GBP/USD"},
+			groups:  []TypeGroup{{Types: []string{"bid", "offer"}}},
+			want:    1,
+		},
+		{
+			name:       "chunks",
+			symbols:    []string{"EUR/USD", "GBP/USD", "USD/JPY"},
+			maxSymbols: 2,
+			groups:     []TypeGroup{{Types: []string{"bid", "offer"}}},
+			want:       2,
+		},
+		{
+			name:    "depths and target sub ids",
+			symbols: []string{"EUR/USD"},
+			subIDs:  []string{"DESK1", "DESK2"},
+			groups:  []TypeGroup{{Types: []string{"bid", "offer"}}, {Depth: 1, Types: []string{"trade"}}},
+			want:    4,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			optionSymbols, optionMaxSymbols, optionFanSubIDs, TypeGroups = tt.symbols, tt.maxSymbols, tt.subIDs, tt.groups
+
+			if got := requestCount(&config.Session{}); got != tt.want {
+				t.Errorf("requestCount() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+
+	for _, logIDs := range []bool{false, true} {
+		logRequestIDs = logIDs
+
+		buf := &bytes.Buffer{}
+		logger := zerolog.New(buf)
+		requestLogger(&logger, "req-1-2").Info().Msg("sent")
+
+		logged := map[string]string{}
+		if err := json.Unmarshal(buf.Bytes(), &logged); err != nil {
+			t.Fatalf("unable to decode log line %q: %s", buf, err)
+		}
+
+		want := ""
+		if logIDs {
+			want = "req-1-2"
+		}
+		if logged["mdreqid"] != want {
+			t.Errorf("logRequestIDs = %t: logged mdreqid = %q, want %q", logIDs, logged["mdreqid"], want)
+		}
+	}
+}
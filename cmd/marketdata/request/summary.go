@@ -0,0 +1,120 @@
+package marketdatarequest
+
+import (
+	"encoding/json"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/quickfixgo/enum"
+	"github.com/quickfixgo/quickfix"
+
+	"sylr.dev/fix/config"
+)
+
+// Summary holds the metadata of a run written by --summary-json.
+type Summary struct {
+	Session      *SummarySession `json:"session,omitempty"`
+	Start        time.Time       `json:"start"`
+	End          time.Time       `json:"end"`
+	MessageTypes map[string]int  `json:"message_types"`
+	Rejects      int             `json:"rejects"`
+	MDReqIDs     []string        `json:"mdreqids"`
+	ExitReason   string          `json:"exit_reason"`
+	ExitCode     int             `json:"exit_code"`
+
+	signal os.Signal
+	mux    sync.Mutex
+}
+
+// SummarySession is the identity of the session used by the run.
+type SummarySession struct {
+	Name         string `json:"name"`
+	BeginString  string `json:"begin_string"`
+	SenderCompID string `json:"sender_comp_id"`
+	SenderSubID  string `json:"sender_sub_id,omitempty"`
+	TargetCompID string `json:"target_comp_id"`
+	TargetSubID  string `json:"target_sub_id,omitempty"`
+}
+
+func NewSummary() *Summary {
+	return &Summary{
+		Start:        time.Now(),
+		MessageTypes: make(map[string]int),
+		MDReqIDs:     []string{},
+	}
+}
+
+func (s *Summary) SetSession(session *config.Session) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	s.Session = &SummarySession{
+		Name:         session.Name,
+		BeginString:  session.BeginString,
+		SenderCompID: session.SenderCompID,
+		SenderSubID:  session.SenderSubID,
+		TargetCompID: session.TargetCompID,
+		TargetSubID:  session.TargetSubID,
+	}
+}
+
+// AddRequest records a sent MDReqID.
+func (s *Summary) AddRequest(mdReqID string) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	s.MDReqIDs = append(s.MDReqIDs, mdReqID)
+}
+
+// AddMessage counts a received message by type, rejects being counted apart
+// as well.
+func (s *Summary) AddMessage(msg *quickfix.Message) {
+	typ, err := msg.MsgType()
+	if err != nil {
+		return
+	}
+
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	s.MessageTypes[typ]++
+
+	switch enum.MsgType(typ) {
+	case enum.MsgType_MARKET_DATA_REQUEST_REJECT, enum.MsgType_BUSINESS_MESSAGE_REJECT:
+		s.Rejects++
+	}
+}
+
+// SetSignal records the signal which interrupted the run.
+func (s *Summary) SetSignal(signal os.Signal) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	s.signal = signal
+}
+
+// Write ends the summary with the outcome of the run and writes it to path.
+func (s *Summary) Write(path string, err error) error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	s.End = time.Now()
+
+	switch {
+	case err != nil:
+		s.ExitReason = err.Error()
+		s.ExitCode = 1
+	case s.signal != nil:
+		s.ExitReason = "signal: " + s.signal.String()
+	default:
+		s.ExitReason = "completed"
+	}
+
+	b, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, append(b, '\n'), 0o644)
+}
@@ -36,6 +36,7 @@ var MarketDataValidatorCmd = &cobra.Command{
 	Long:              "Validates market data retrieved.",
 	Args:              cobra.ExactArgs(0),
 	ValidArgsFunction: cobra.NoFileCompletions,
+	Annotations:       utils.MessageAnnotations(string(enum.MsgType_MARKET_DATA_REQUEST)),
 	PersistentPreRunE: utils.MakePersistentPreRunE(Validate),
 	RunE:              Execute,
 }
@@ -57,6 +58,8 @@ func Validate(cmd *cobra.Command, args []string) error {
 
 func Execute(cmd *cobra.Command, args []string) error {
 	options := config.GetOptions()
+	ctx, cancel := utils.WithMaxRuntime(cmd.Context(), options.MaxRuntime)
+	defer cancel()
 	logger := config.GetLogger()
 
 	context, err := config.GetCurrentContext()
@@ -123,6 +126,8 @@ func Execute(cmd *cobra.Command, args []string) error {
 
 	// Wait for session connection
 	select {
+	case <-ctx.Done():
+		return errors.MaxRuntimeExceeded
 	case <-time.After(timeout):
 		return errors.ConnectionTimeout
 	case _, ok := <-app.Connected:
@@ -149,6 +154,8 @@ func Execute(cmd *cobra.Command, args []string) error {
 LOOP:
 	for {
 		select {
+		case <-ctx.Done():
+			return errors.MaxRuntimeExceeded
 		case signal := <-interrupt:
 			switch signal {
 			case syscall.SIGINT, syscall.SIGTERM:
@@ -8,7 +8,6 @@ import (
 	"syscall"
 	"time"
 
-	"github.com/google/uuid"
 	"github.com/rs/zerolog"
 	"github.com/shopspring/decimal"
 	"github.com/spf13/cobra"
@@ -37,6 +36,7 @@ var (
 	optionOrderPrice                 float64
 	optionOrderOrigination           string
 	partyIdOptions                   *options.PartyIdOptions
+	clOrdIDOptions                   *options.IDOptions
 	attributeOptions                 *options.AttributeOptions
 	optionExecReports                int
 	optionExecReportsTimeout         time.Duration
@@ -49,12 +49,12 @@ var NewOrderCmd = &cobra.Command{
 	Long:              "Send a new single order after initiating a session with a FIX acceptor.",
 	Args:              cobra.ExactArgs(0),
 	ValidArgsFunction: cobra.NoFileCompletions,
+	Annotations:       utils.MessageAnnotations(string(enum.MsgType_ORDER_SINGLE), quickfix.BeginStringFIXT11+"/FIX.5.0SP2"),
 	PersistentPreRunE: utils.MakePersistentPreRunE(Validate),
 	RunE:              Execute,
 }
 
 func init() {
-	NewOrderCmd.Flags().StringVar(&optionOrderID, "id", "", "Order id (uuid autogenerated if not given)")
 	NewOrderCmd.Flags().StringVar(&optionOrderSide, "side", "", "Order side (buy, sell ... etc)")
 	NewOrderCmd.Flags().StringVar(&optionOrderType, "type", "", "Order type (market, limit, stop ... etc)")
 	NewOrderCmd.Flags().StringVar(&optionOrderSymbol, "symbol", "", "Order symbol")
@@ -64,11 +64,13 @@ func init() {
 	NewOrderCmd.Flags().StringVar(&optionOrderOrigination, "origination", "", "Order origination")
 
 	partyIdOptions = options.NewPartyIdOptions(NewOrderCmd)
+	clOrdIDOptions = options.NewIDOptions(NewOrderCmd, &optionOrderID, "id", "ClOrdID", "Order id")
 	attributeOptions = options.NewAttributeOptions(NewOrderCmd)
 
 	NewOrderCmd.Flags().IntVar(&optionExecReports, "exec-reports", 1, "Expect given number of execution reports before logging out (0 wait indefinitely)")
 	NewOrderCmd.Flags().DurationVar(&optionExecReportsTimeout, "exec-reports-timeout", 5*time.Second, "Log out if execution reports not received within timeout (0s wait indefinitely)")
 	NewOrderCmd.Flags().BoolVar(&optionExecReportsTimeoutReset, "exec-reports-timeout-reset", false, "Reset execution reports timeout each time an execution report is received")
+	initiator.AddAutoApplVerIDFlag(NewOrderCmd)
 
 	NewOrderCmd.MarkFlagRequired("side")
 	NewOrderCmd.MarkFlagRequired("type")
@@ -95,9 +97,8 @@ func Validate(cmd *cobra.Command, args []string) error {
 		return errors.OptionOrderTypeUnknown
 	}
 
-	if len(optionOrderID) == 0 {
-		uid := uuid.New()
-		optionOrderID = uid.String()
+	if err := clOrdIDOptions.Validate(); err != nil {
+		return err
 	}
 
 	if len(optionOrderOrigination) > 0 {
@@ -123,6 +124,8 @@ func Validate(cmd *cobra.Command, args []string) error {
 
 func Execute(cmd *cobra.Command, args []string) error {
 	options := config.GetOptions()
+	ctx, cancel := utils.WithMaxRuntime(cmd.Context(), options.MaxRuntime)
+	defer cancel()
 	logger := config.GetLogger()
 
 	context, err := config.GetCurrentContext()
@@ -189,6 +192,8 @@ func Execute(cmd *cobra.Command, args []string) error {
 
 	// Wait for session connection
 	select {
+	case <-ctx.Done():
+		return errors.MaxRuntimeExceeded
 	case <-time.After(timeout):
 		return errors.ConnectionTimeout
 	case _, ok := <-app.Connected:
@@ -197,6 +202,10 @@ func Execute(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	if err := initiator.ApplyNegotiatedApplVerID(cmd, session); err != nil {
+		return err
+	}
+
 	// Prepare order
 	order, err := buildMessage(*session)
 	if err != nil {
@@ -223,6 +232,8 @@ func Execute(cmd *cobra.Command, args []string) error {
 LOOP:
 	for {
 		select {
+		case <-ctx.Done():
+			return errors.MaxRuntimeExceeded
 		case signal := <-interrupt:
 			logger.Debug().Msgf("Received signal: %s", signal)
 			break LOOP
@@ -0,0 +1,292 @@
+package replay
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/spf13/cobra"
+
+	"github.com/quickfixgo/enum"
+	"github.com/quickfixgo/field"
+	"github.com/quickfixgo/quickfix"
+	"github.com/quickfixgo/tag"
+
+	"sylr.dev/fix/config"
+	"sylr.dev/fix/pkg/errors"
+	"sylr.dev/fix/pkg/initiator"
+	"sylr.dev/fix/pkg/initiator/application"
+	"sylr.dev/fix/pkg/utils"
+)
+
+var (
+	optionRealtime bool
+	optionPossDup  bool
+)
+
+// adminMsgTypes are the session level messages which are not replayed as they
+// are handled by quickfix.
+var adminMsgTypes = []enum.MsgType{
+	enum.MsgType_HEARTBEAT,
+	enum.MsgType_TEST_REQUEST,
+	enum.MsgType_RESEND_REQUEST,
+	enum.MsgType_REJECT,
+	enum.MsgType_SEQUENCE_RESET,
+	enum.MsgType_LOGOUT,
+	enum.MsgType_LOGON,
+}
+
+var ReplayCmd = &cobra.Command{
+	Use:   "replay <capture-file>",
+	Short: "Replay captured FIX messages",
+	Long: "Send the application messages read from a capture file over the session, one message per line optionally " +
+		"prefixed by the time it was captured at. Messages are sent as fast as possible unless --realtime is given.",
+	Args: cobra.ExactArgs(1),
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return nil, cobra.ShellCompDirectiveDefault
+	},
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if err := initiator.ValidateOptions(cmd, args); err != nil {
+			return err
+		}
+
+		if cmd.HasParent() {
+			parent := cmd.Parent()
+			if parent.PersistentPreRunE != nil {
+				return parent.PersistentPreRunE(parent, args)
+			}
+		}
+
+		return nil
+	},
+	RunE: Execute,
+}
+
+func init() {
+	initiator.AddPersistentFlags(ReplayCmd)
+	if err := initiator.AddPersistentFlagCompletions(ReplayCmd); err != nil {
+		panic(err)
+	}
+
+	ReplayCmd.Flags().BoolVar(&optionRealtime, "realtime", false, "Honor the original timing between messages using their captured timestamps")
+	ReplayCmd.Flags().BoolVar(&optionPossDup, "poss-dup", false, "Flag the messages as possible duplicates (PossDupFlag) with their original SendingTime as OrigSendingTime")
+}
+
+func Execute(cmd *cobra.Command, args []string) error {
+	options := config.GetOptions()
+	ctx, cancel := utils.WithMaxRuntime(cmd.Context(), options.MaxRuntime)
+	defer cancel()
+	logger := config.GetLogger()
+
+	context, err := config.GetCurrentContext()
+	if err != nil {
+		return err
+	}
+
+	sessions, err := context.GetSessions()
+	if err != nil {
+		return err
+	}
+
+	session := sessions[0]
+	initiatior, err := context.GetInitiator()
+	if err != nil {
+		return err
+	}
+
+	transportDict, appDict, err := session.GetFIXDictionaries()
+	if err != nil {
+		return err
+	}
+
+	// Read the capture before initiating the session so that errors are
+	// reported without connecting
+	f, err := os.Open(args[0])
+	if err != nil {
+		return fmt.Errorf("%w: %s", errors.Options, err)
+	}
+	captured, err := utils.ReadCapturedMessages(f, transportDict, appDict)
+	f.Close()
+	if err != nil {
+		return fmt.Errorf("%w: %s: %s", errors.FixInvalidOutboundMessage, args[0], err)
+	}
+
+	messages, err := prepareMessages(logger, *session, captured)
+	if err != nil {
+		return err
+	}
+	if len(messages) == 0 {
+		logger.Warn().Msg("No application message to replay")
+		return nil
+	}
+
+	settings, err := context.ToQuickFixInitiatorSettings()
+	if err != nil {
+		return err
+	}
+
+	app := application.NewInitiator()
+	app.Logger = logger
+	app.Settings = settings
+	app.TransportDataDictionary = transportDict
+	app.AppDataDictionary = appDict
+
+	var quickfixLogger *zerolog.Logger
+	if options.QuickFixLogging {
+		quickfixLogger = logger
+	}
+
+	init, err := initiator.Initiate(app, settings, quickfixLogger)
+	if err != nil {
+		return err
+	}
+
+	// Start session
+	if err = init.Start(); err != nil {
+		return err
+	}
+
+	defer func() {
+		app.Stop()
+		init.Stop()
+	}()
+
+	// Choose right timeout cli option > config > default value (5s)
+	var timeout time.Duration
+	if options.Timeout != time.Duration(0) {
+		timeout = options.Timeout
+	} else if initiatior.SocketTimeout != time.Duration(0) {
+		timeout = initiatior.SocketTimeout
+	} else {
+		timeout = 5 * time.Second
+	}
+
+	// Wait for session connection
+	select {
+	case <-ctx.Done():
+		return errors.MaxRuntimeExceeded
+	case <-time.After(timeout):
+		return errors.ConnectionTimeout
+	case _, ok := <-app.Connected:
+		if !ok {
+			return errors.FixLogout
+		}
+	}
+
+	// Drain the app channels so that quickfix does not block while replaying
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for range app.ToAppMessages {
+		}
+	}()
+	go func() {
+		for range app.FromAppMessages {
+		}
+	}()
+
+	start := time.Now()
+	for i, message := range messages {
+		if optionRealtime && i > 0 && !message.Time.IsZero() && !messages[0].Time.IsZero() {
+			delay := message.Time.Sub(messages[0].Time) - time.Since(start)
+			if delay > 0 {
+				select {
+				case <-ctx.Done():
+					return errors.MaxRuntimeExceeded
+				case <-done:
+					return errors.FixLogout
+				case <-time.After(delay):
+				}
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return errors.MaxRuntimeExceeded
+		case <-done:
+			return errors.FixLogout
+		default:
+		}
+
+		if err = quickfix.SendToTarget(message.Message, app.SessionID); err != nil {
+			return err
+		}
+	}
+
+	logger.Info().Msgf("%d message(s) replayed in %s", len(messages), time.Since(start))
+
+	return nil
+}
+
+// prepareMessages filters out the session level messages and rewrites the
+// remaining ones for the current session. Messages flagged as possible
+// duplicates must carry their OrigSendingTime.
+func prepareMessages(logger *zerolog.Logger, session config.Session, captured []utils.CapturedMessage) ([]utils.CapturedMessage, error) {
+	messages := make([]utils.CapturedMessage, 0, len(captured))
+
+	for i, c := range captured {
+		typ, err := c.Message.MsgType()
+		if err != nil {
+			logger.Warn().Msgf("Skipping message without MsgType: %s", err)
+			continue
+		}
+
+		if utils.Search(adminMsgTypes, enum.MsgType(typ)) >= 0 {
+			logger.Debug().Msgf("Skipping session level message of type %s", typ)
+			continue
+		}
+
+		// The original SendingTime is dropped when rewriting the message
+		if optionPossDup {
+			utils.SetPossDup(c.Message, c.Time)
+		}
+
+		if err := utils.CheckPossDup(c.Message); err != nil {
+			return nil, fmt.Errorf("message %d: %w", i+1, err)
+		}
+
+		c.Message = rewriteMessage(c.Message, session)
+		messages = append(messages, c)
+	}
+
+	return messages, nil
+}
+
+// sessionHeaderTags are the header fields set for the current session or by
+// quickfix when sending the message.
+var sessionHeaderTags = []quickfix.Tag{
+	tag.BeginString,
+	tag.BodyLength,
+	tag.MsgSeqNum,
+	tag.SendingTime,
+	tag.SenderCompID,
+	tag.SenderSubID,
+	tag.TargetCompID,
+	tag.TargetSubID,
+}
+
+// rewriteMessage returns a copy of the message with the CompIDs of the current
+// session, leaving out the fields quickfix sets when sending the message.
+func rewriteMessage(message *quickfix.Message, session config.Session) *quickfix.Message {
+	msg := quickfix.NewMessage()
+	message.Body.CopyInto(&msg.Body.FieldMap)
+
+	for _, t := range message.Header.Tags() {
+		if utils.Search(sessionHeaderTags, t) >= 0 {
+			continue
+		}
+		if v, err := message.Header.GetBytes(t); err == nil {
+			msg.Header.SetBytes(t, v)
+		}
+	}
+
+	msg.Header.SetString(tag.BeginString, session.BeginString)
+
+	utils.QuickFixMessagePartSetString(&msg.Header, session.TargetCompID, field.NewTargetCompID)
+	utils.QuickFixMessagePartSetString(&msg.Header, session.TargetSubID, field.NewTargetSubID)
+	utils.QuickFixMessagePartSetString(&msg.Header, session.SenderCompID, field.NewSenderCompID)
+	utils.QuickFixMessagePartSetString(&msg.Header, session.SenderSubID, field.NewSenderSubID)
+
+	return msg
+}
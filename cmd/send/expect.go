@@ -0,0 +1,68 @@
+package send
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/quickfixgo/quickfix"
+	"github.com/quickfixgo/quickfix/datadictionary"
+)
+
+// expectation is a field value expected in the responses, from --expect.
+type expectation struct {
+	raw   string
+	tag   quickfix.Tag
+	value string
+}
+
+// parseExpectations parses --expect, tags being given by number or by field
+// name.
+func parseExpectations(transportDict, appDict *datadictionary.DataDictionary) ([]expectation, error) {
+	expectations := make([]expectation, 0, len(optionExpect))
+
+	for _, expect := range optionExpect {
+		k, v, _ := strings.Cut(expect, "=")
+
+		t, err := resolveTag(k, transportDict, appDict)
+		if err != nil {
+			return nil, err
+		}
+
+		expectations = append(expectations, expectation{raw: expect, tag: t, value: v})
+	}
+
+	return expectations, nil
+}
+
+// checkExpectations returns a line per expectation the message does not meet
+// with the values found instead. Fields repeated in groups meet the
+// expectation if any of their values does.
+func checkExpectations(message *quickfix.Message, expectations []expectation) []string {
+	failures := []string{}
+
+	for _, e := range expectations {
+		found := []string{}
+		matched := false
+
+		for _, f := range message.GetFields() {
+			if f.Tag() != e.tag {
+				continue
+			}
+			if f.Value() == e.value {
+				matched = true
+				break
+			}
+			found = append(found, f.Value())
+		}
+
+		switch {
+		case matched:
+		case len(found) == 0:
+			failures = append(failures, fmt.Sprintf("%s: field missing", e.raw))
+		default:
+			failures = append(failures, fmt.Sprintf("%s: got %s", e.raw, strings.Join(found, ", ")))
+		}
+	}
+
+	return failures
+}
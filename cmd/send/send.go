@@ -0,0 +1,558 @@
+package send
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/iancoleman/strcase"
+	"github.com/rs/zerolog"
+	"github.com/spf13/cobra"
+
+	"github.com/quickfixgo/enum"
+	"github.com/quickfixgo/field"
+	"github.com/quickfixgo/quickfix"
+	"github.com/quickfixgo/quickfix/datadictionary"
+	"github.com/quickfixgo/tag"
+
+	"sylr.dev/fix/config"
+	"sylr.dev/fix/pkg/cli/complete"
+	"sylr.dev/fix/pkg/dict"
+	"sylr.dev/fix/pkg/errors"
+	"sylr.dev/fix/pkg/initiator"
+	"sylr.dev/fix/pkg/initiator/application"
+	"sylr.dev/fix/pkg/utils"
+)
+
+var (
+	optionMsgType      string
+	optionSet          []string
+	optionGroups       []string
+	optionFile         string
+	optionRawFile      string
+	optionValidateOnly bool
+	optionPossDup      bool
+	optionExpect       []string
+	optionExpectAll    bool
+	optionCount        int
+	optionRespTimeout  time.Duration
+
+	MsgType enum.MsgType
+)
+
+var SendCmd = &cobra.Command{
+	Use:   "send",
+	Short: "Send a generic FIX message",
+	Long: "Build a FIX message from its MsgType and a list of fields, or read raw messages from a file, " +
+		"and send them after initiating a session with a FIX acceptor.",
+	Args:              cobra.ExactArgs(0),
+	ValidArgsFunction: cobra.NoFileCompletions,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if err := utils.ValidateRequiredFlags(cmd); err != nil {
+			return err
+		}
+
+		if err := Validate(cmd, args); err != nil {
+			return err
+		}
+
+		if err := initiator.ValidateOptions(cmd, args); err != nil {
+			return err
+		}
+
+		if cmd.HasParent() {
+			parent := cmd.Parent()
+			if parent.PersistentPreRunE != nil {
+				return parent.PersistentPreRunE(parent, args)
+			}
+		}
+
+		return nil
+	},
+	RunE: Execute,
+}
+
+func init() {
+	initiator.AddPersistentFlags(SendCmd)
+	if err := initiator.AddPersistentFlagCompletions(SendCmd); err != nil {
+		panic(err)
+	}
+
+	SendCmd.Flags().StringVar(&optionMsgType, "msg-type", "", "Message type, by name (e.g. MarketDataRequest) or by value (e.g. V)")
+	SendCmd.Flags().StringArrayVar(&optionSet, "set", []string{}, "Field to set as tag=value, tag being a number or a field name (e.g. 262=id or MDReqID=id)")
+	SendCmd.Flags().StringArrayVar(&optionGroups, "group", []string{}, "Repeating group entry as name:tag=value,tag=value, name being a group template of the context (repeat for each entry)")
+	SendCmd.Flags().StringVar(&optionFile, "file", "", "File of raw messages to send, one per line (SOH or | delimited)")
+	SendCmd.Flags().StringVar(&optionRawFile, "raw-file", "", "File of raw bytes as sent on the wire (SOH intact), e.g. extracted from a network capture, whose header is rewritten for the session")
+	SendCmd.Flags().BoolVar(&optionPossDup, "poss-dup", false, "Flag the messages as possible duplicates (PossDupFlag) with their SendingTime, if any, as OrigSendingTime")
+	SendCmd.Flags().BoolVar(&optionValidateOnly, "validate-only", false, "Validate the messages against the session dictionaries and exit without connecting")
+	SendCmd.Flags().StringArrayVar(&optionExpect, "expect", []string{}, "Field value expected in the response as tag=value, exiting with a non zero status if missing")
+	SendCmd.Flags().BoolVar(&optionExpectAll, "expect-all", false, "Check --expect against all the responses instead of the first one")
+	SendCmd.Flags().IntVar(&optionCount, "count", 1, "Number of responses to wait for")
+	SendCmd.Flags().DurationVar(&optionRespTimeout, "response-timeout", 0, "Maximum duration to wait for the responses (defaults to --timeout)")
+
+	SendCmd.RegisterFlagCompletionFunc("msg-type", complete.MsgTypes)
+	SendCmd.RegisterFlagCompletionFunc("set", cobra.NoFileCompletions)
+	SendCmd.RegisterFlagCompletionFunc("group", cobra.NoFileCompletions)
+	SendCmd.RegisterFlagCompletionFunc("expect", cobra.NoFileCompletions)
+}
+
+func Validate(cmd *cobra.Command, args []string) error {
+	for _, expect := range optionExpect {
+		if k, _, found := strings.Cut(expect, "="); !found || len(k) == 0 {
+			return fmt.Errorf("%w: invalid expectation `%s`, expecting tag=value", errors.Options, expect)
+		}
+	}
+
+	if optionCount < 1 {
+		return fmt.Errorf("%w: --count must be greater than 0", errors.Options)
+	}
+
+	if optionRespTimeout < 0 {
+		return fmt.Errorf("%w: --response-timeout can't be negative", errors.Options)
+	}
+
+	switch {
+	case len(optionFile) > 0 && len(optionRawFile) > 0:
+		return fmt.Errorf("%w: --file can't be used with --raw-file", errors.OptionsInconsistentValues)
+	case len(optionFile) > 0 && (len(optionMsgType) > 0 || len(optionSet) > 0 || len(optionGroups) > 0):
+		return fmt.Errorf("%w: --file can't be used with --msg-type, --set or --group", errors.OptionsInconsistentValues)
+	case len(optionRawFile) > 0 && (len(optionMsgType) > 0 || len(optionSet) > 0 || len(optionGroups) > 0):
+		return fmt.Errorf("%w: --raw-file can't be used with --msg-type, --set or --group", errors.OptionsInconsistentValues)
+	case len(optionFile) > 0 || len(optionRawFile) > 0:
+		return nil
+	case len(optionMsgType) == 0:
+		return fmt.Errorf("%w: either --msg-type, --file or --raw-file is required", errors.Options)
+	}
+
+	if t, ok := dict.MessageTypes[strcase.ToScreamingSnake(optionMsgType)]; ok {
+		MsgType = t
+	} else if _, err := dict.SearchValue(dict.MessageTypes, enum.MsgType(optionMsgType)); err == nil {
+		MsgType = enum.MsgType(optionMsgType)
+	} else {
+		return fmt.Errorf("%w: unknown message type `%s`", errors.Options, optionMsgType)
+	}
+
+	for _, set := range optionSet {
+		if k, _, found := strings.Cut(set, "="); !found || len(k) == 0 {
+			return fmt.Errorf("%w: invalid field `%s`, expecting tag=value", errors.Options, set)
+		}
+	}
+
+	for _, group := range optionGroups {
+		if name, fields, found := strings.Cut(group, ":"); !found || len(name) == 0 || len(fields) == 0 {
+			return fmt.Errorf("%w: invalid group entry `%s`, expecting name:tag=value,tag=value", errors.Options, group)
+		}
+	}
+
+	return nil
+}
+
+func Execute(cmd *cobra.Command, args []string) error {
+	options := config.GetOptions()
+	ctx, cancel := utils.WithMaxRuntime(cmd.Context(), options.MaxRuntime)
+	defer cancel()
+	logger := config.GetLogger()
+
+	context, err := config.GetCurrentContext()
+	if err != nil {
+		return err
+	}
+
+	sessions, err := context.GetSessions()
+	if err != nil {
+		return err
+	}
+
+	session := sessions[0]
+	initiatior, err := context.GetInitiator()
+	if err != nil {
+		return err
+	}
+
+	transportDict, appDict, err := session.GetFIXDictionaries()
+	if err != nil {
+		return err
+	}
+
+	// Prepare the messages before initiating the session so that errors are
+	// reported without connecting
+	var messages []*quickfix.Message
+	if len(optionFile) > 0 {
+		messages, err = readMessages(transportDict, appDict)
+	} else if len(optionRawFile) > 0 {
+		messages, err = readBinaryMessages(*session, transportDict, appDict)
+	} else {
+		var message *quickfix.Message
+		message, err = buildMessage(context, *session, transportDict, appDict)
+		messages = []*quickfix.Message{message}
+	}
+	if err != nil {
+		return err
+	}
+
+	expectations, err := parseExpectations(transportDict, appDict)
+	if err != nil {
+		return err
+	}
+
+	for i, message := range messages {
+		if optionPossDup {
+			utils.SetPossDup(message, time.Time{})
+		}
+		if err := utils.CheckPossDup(message); err != nil {
+			return fmt.Errorf("message %d: %w", i+1, err)
+		}
+	}
+
+	profile, err := context.GetConformanceProfile()
+	if err != nil {
+		return err
+	}
+
+	if optionValidateOnly {
+		return validateMessages(logger, messages, session.BeginString, profile, transportDict, appDict)
+	}
+
+	for i, message := range messages {
+		if err := profile.Validate(message, appDict, transportDict); err != nil {
+			return fmt.Errorf("message %d: %w", i+1, err)
+		}
+	}
+
+	settings, err := context.ToQuickFixInitiatorSettings()
+	if err != nil {
+		return err
+	}
+
+	app := application.NewInitiator()
+	app.Logger = logger
+	app.Settings = settings
+	app.TransportDataDictionary = transportDict
+	app.AppDataDictionary = appDict
+
+	var quickfixLogger *zerolog.Logger
+	if options.QuickFixLogging {
+		quickfixLogger = logger
+	}
+
+	init, err := initiator.Initiate(app, settings, quickfixLogger)
+	if err != nil {
+		return err
+	}
+
+	// Start session
+	if err = init.Start(); err != nil {
+		return err
+	}
+
+	defer func() {
+		app.Stop()
+		init.Stop()
+	}()
+
+	// Choose right timeout cli option > config > default value (5s)
+	var timeout time.Duration
+	if options.Timeout != time.Duration(0) {
+		timeout = options.Timeout
+	} else if initiatior.SocketTimeout != time.Duration(0) {
+		timeout = initiatior.SocketTimeout
+	} else {
+		timeout = 5 * time.Second
+	}
+
+	// Wait for session connection
+	select {
+	case <-ctx.Done():
+		return errors.MaxRuntimeExceeded
+	case <-time.After(timeout):
+		return errors.ConnectionTimeout
+	case _, ok := <-app.Connected:
+		if !ok {
+			return errors.FixLogout
+		}
+	}
+
+	// Send the messages
+	for _, message := range messages {
+		err = quickfix.SendToTarget(message, app.SessionID)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Wait for the responses
+	responseTimeout := timeout
+	if optionRespTimeout > 0 {
+		responseTimeout = optionRespTimeout
+	}
+	deadline := time.After(responseTimeout)
+
+	failed := 0
+	for received := 0; received < optionCount; {
+		select {
+		case <-ctx.Done():
+			return errors.MaxRuntimeExceeded
+		case <-deadline:
+			return fmt.Errorf("%w: %d/%d response(s) received", errors.ResponseTimeout, received, optionCount)
+		case _, ok := <-app.ToAppMessages:
+			if !ok {
+				return errors.FixLogout
+			}
+		case responseMessage, ok := <-app.FromAppMessages:
+			if !ok {
+				return errors.FixLogout
+			}
+
+			app.WriteMessageBodyAsTable(os.Stdout, responseMessage)
+
+			if received == 0 || optionExpectAll {
+				for _, failure := range checkExpectations(responseMessage, expectations) {
+					logger.Error().Int("response", received+1).Msg(failure)
+					failed++
+				}
+			}
+			received++
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%w: %d assertion(s) failed", errors.FixUnexpectedResponse, failed)
+	}
+
+	return nil
+}
+
+// buildMessage builds the message from --msg-type, --set and --group. Fields
+// defined in the transport dictionary header are set in the header, other ones
+// in the body.
+func buildMessage(context *config.Context, session config.Session, transportDict, appDict *datadictionary.DataDictionary) (*quickfix.Message, error) {
+	if appDict != nil && transportDict != nil {
+		_, inApp := appDict.Messages[string(MsgType)]
+		_, inTransport := transportDict.Messages[string(MsgType)]
+		if !inApp && !inTransport {
+			return nil, fmt.Errorf("%w: message type `%s` not defined in the session dictionaries", errors.Options, optionMsgType)
+		}
+	}
+
+	message := quickfix.NewMessage()
+	message.Header.Set(field.NewMsgType(MsgType))
+
+	utils.QuickFixMessagePartSetString(&message.Header, session.TargetCompID, field.NewTargetCompID)
+	utils.QuickFixMessagePartSetString(&message.Header, session.TargetSubID, field.NewTargetSubID)
+	utils.QuickFixMessagePartSetString(&message.Header, session.SenderCompID, field.NewSenderCompID)
+	utils.QuickFixMessagePartSetString(&message.Header, session.SenderSubID, field.NewSenderSubID)
+
+	for _, set := range optionSet {
+		k, v, _ := strings.Cut(set, "=")
+
+		t, err := resolveTag(k, transportDict, appDict)
+		if err != nil {
+			return nil, err
+		}
+
+		switch {
+		case t == tag.MsgType:
+			return nil, fmt.Errorf("%w: use --msg-type to set the message type", errors.OptionsInconsistentValues)
+		case transportDict != nil && isHeaderTag(transportDict, t):
+			message.Header.SetString(t, v)
+		default:
+			message.Body.SetString(t, v)
+		}
+	}
+
+	groups, err := buildGroups(context, transportDict, appDict)
+	if err != nil {
+		return nil, err
+	}
+	for _, group := range groups {
+		message.Body.SetGroup(group)
+	}
+
+	return message, nil
+}
+
+// buildGroups builds the repeating groups of --group from the group templates
+// of the context, in the order they first appear.
+func buildGroups(context *config.Context, transportDict, appDict *datadictionary.DataDictionary) ([]*quickfix.RepeatingGroup, error) {
+	groups := []*quickfix.RepeatingGroup{}
+	byName := make(map[string]*quickfix.RepeatingGroup)
+	members := make(map[string][]quickfix.Tag)
+
+	for _, entry := range optionGroups {
+		name, fields, _ := strings.Cut(entry, ":")
+
+		group, ok := byName[name]
+		if !ok {
+			tmpl, err := context.GetGroupTemplate(name)
+			if err != nil {
+				return nil, err
+			}
+
+			countTag, tags, err := tmpl.Resolve(transportDict, appDict)
+			if err != nil {
+				return nil, err
+			}
+
+			template := make(quickfix.GroupTemplate, 0, len(tags))
+			for _, t := range tags {
+				template = append(template, quickfix.GroupElement(t))
+			}
+
+			group = quickfix.NewRepeatingGroup(countTag, template)
+			groups = append(groups, group)
+			byName[name] = group
+			members[name] = tags
+		}
+
+		item := group.Add()
+		for _, field := range strings.Split(fields, ",") {
+			k, v, found := strings.Cut(field, "=")
+			if !found {
+				return nil, fmt.Errorf("%w: invalid group field `%s`, expecting tag=value", errors.Options, field)
+			}
+
+			t, err := resolveTag(k, transportDict, appDict)
+			if err != nil {
+				return nil, err
+			}
+			if utils.Search(members[name], t) < 0 {
+				return nil, fmt.Errorf("%w: tag `%s` is not a member of group template %s", errors.Options, k, name)
+			}
+
+			item.SetString(t, v)
+		}
+	}
+
+	return groups, nil
+}
+
+// readMessages reads the raw messages of --file.
+func readMessages(transportDict, appDict *datadictionary.DataDictionary) ([]*quickfix.Message, error) {
+	f, err := os.Open(optionFile)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", errors.Options, err)
+	}
+	defer f.Close()
+
+	messages, err := utils.ReadRawMessages(f, transportDict, appDict)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %s", errors.FixInvalidOutboundMessage, optionFile, err)
+	}
+
+	return messages, nil
+}
+
+// readBinaryMessages reads the raw bytes of --raw-file and rewrites the header
+// of every message for the session. Messages must be valid against the session
+// dictionaries once rewritten.
+func readBinaryMessages(session config.Session, transportDict, appDict *datadictionary.DataDictionary) ([]*quickfix.Message, error) {
+	f, err := os.Open(optionRawFile)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", errors.Options, err)
+	}
+	defer f.Close()
+
+	messages, err := utils.ReadBinaryMessages(f, transportDict, appDict)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %s", errors.FixInvalidOutboundMessage, optionRawFile, err)
+	}
+
+	for i, message := range messages {
+		rewriteHeader(message, session)
+
+		if err := utils.ValidateOutgoingMessage(message, session.BeginString, transportDict, appDict); err != nil {
+			return nil, fmt.Errorf("%s: message %d: %w", optionRawFile, i+1, err)
+		}
+	}
+
+	return messages, nil
+}
+
+// rewriteHeader replaces the session identity and the SendingTime of a message
+// captured on another session, dropping the routing fields of the original
+// one. The BodyLength, MsgSeqNum and CheckSum are set by quickfix when sending.
+func rewriteHeader(message *quickfix.Message, session config.Session) {
+	dropped := []quickfix.Tag{tag.SenderSubID, tag.TargetSubID, tag.SenderLocationID, tag.TargetLocationID, tag.NoHops}
+
+	kept := make(map[quickfix.Tag][]byte)
+	tags := message.Header.Tags()
+	for _, t := range tags {
+		if utils.Search(dropped, t) >= 0 {
+			continue
+		}
+		if v, err := message.Header.GetBytes(t); err == nil {
+			kept[t] = v
+		}
+	}
+
+	message.Header.Clear()
+	for _, t := range tags {
+		if v, ok := kept[t]; ok {
+			message.Header.SetBytes(t, v)
+		}
+	}
+
+	message.Header.SetString(tag.BeginString, session.BeginString)
+	message.Header.SetString(tag.SenderCompID, session.SenderCompID)
+	message.Header.SetString(tag.TargetCompID, session.TargetCompID)
+	utils.QuickFixMessagePartSetString(&message.Header, session.SenderSubID, field.NewSenderSubID)
+	utils.QuickFixMessagePartSetString(&message.Header, session.TargetSubID, field.NewTargetSubID)
+	message.Header.SetField(tag.SendingTime, quickfix.FIXUTCTimestamp{Time: time.Now().UTC()})
+}
+
+// validateMessages validates every message against the dictionaries and the
+// conformance profile, logging each invalid one, and returns an error if any of
+// them is invalid.
+func validateMessages(logger *zerolog.Logger, messages []*quickfix.Message, beginString string, profile *config.ConformanceProfile, transportDict, appDict *datadictionary.DataDictionary) error {
+	invalid := 0
+	for i, message := range messages {
+		if err := utils.ValidateOutgoingMessage(message, beginString, transportDict, appDict); err != nil {
+			logger.Error().Int("message", i+1).Msg(err.Error())
+			invalid++
+		} else if err := profile.Validate(message, appDict, transportDict); err != nil {
+			logger.Error().Int("message", i+1).Msg(err.Error())
+			invalid++
+		}
+	}
+
+	if invalid > 0 {
+		return fmt.Errorf("%w: %d/%d message(s) invalid", errors.FixInvalidOutboundMessage, invalid, len(messages))
+	}
+
+	logger.Info().Msgf("%d message(s) valid", len(messages))
+
+	return nil
+}
+
+// resolveTag returns the tag given by number or by field name.
+func resolveTag(raw string, dicts ...*datadictionary.DataDictionary) (quickfix.Tag, error) {
+	if n, err := strconv.Atoi(raw); err == nil {
+		if n <= 0 {
+			return 0, fmt.Errorf("%w: invalid tag `%s`", errors.Options, raw)
+		}
+		return quickfix.Tag(n), nil
+	}
+
+	for _, d := range dicts {
+		if d == nil {
+			continue
+		}
+		if ft, ok := d.FieldTypeByName[raw]; ok {
+			return quickfix.Tag(ft.Tag()), nil
+		}
+	}
+
+	return 0, fmt.Errorf("%w: unknown field `%s`", errors.Options, raw)
+}
+
+func isHeaderTag(d *datadictionary.DataDictionary, t quickfix.Tag) bool {
+	if d.Header == nil {
+		return false
+	}
+
+	_, ok := d.Header.Fields[int(t)]
+
+	return ok
+}
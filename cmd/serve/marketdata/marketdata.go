@@ -0,0 +1,119 @@
+package serve_marketdata
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/rs/zerolog"
+	"github.com/spf13/cobra"
+
+	"sylr.dev/fix/config"
+	"sylr.dev/fix/pkg/acceptor"
+	"sylr.dev/fix/pkg/acceptor/application"
+	"sylr.dev/fix/pkg/errors"
+	"sylr.dev/fix/pkg/utils"
+)
+
+var (
+	optionPrice  float64
+	optionSpread float64
+	optionSize   float64
+	optionJitter float64
+)
+
+var ServeMarketDataCmd = &cobra.Command{
+	Use:               "marketdata",
+	Short:             "Answer MarketDataRequests",
+	Long:              "Launch a FIX acceptor answering MarketDataRequests with synthetic MarketDataSnapshotFullRefresh messages.",
+	Args:              cobra.ExactArgs(0),
+	ValidArgsFunction: cobra.NoFileCompletions,
+	PersistentPreRunE: utils.MakePersistentPreRunE(Validate),
+	RunE:              Execute,
+}
+
+func init() {
+	ServeMarketDataCmd.Flags().Float64Var(&optionPrice, "price", 100, "Mid price of the synthetic book")
+	ServeMarketDataCmd.Flags().Float64Var(&optionSpread, "spread", 0.1, "Spread between the bid and the offer")
+	ServeMarketDataCmd.Flags().Float64Var(&optionSize, "size", 10, "Size of the synthetic entries")
+	ServeMarketDataCmd.Flags().Float64Var(&optionJitter, "jitter", 0, "Maximum random variation applied to the mid price (0 means fixed prices)")
+}
+
+func Validate(cmd *cobra.Command, args []string) error {
+	if optionPrice <= 0 {
+		return fmt.Errorf("%w: --price must be positive", errors.Options)
+	}
+
+	if optionSpread < 0 || optionSize < 0 || optionJitter < 0 {
+		return fmt.Errorf("%w: --spread, --size and --jitter can't be negative", errors.Options)
+	}
+
+	return nil
+}
+
+func Execute(cmd *cobra.Command, args []string) error {
+	options := config.GetOptions()
+	ctx, cancel := utils.WithMaxRuntime(cmd.Context(), options.MaxRuntime)
+	defer cancel()
+	logger := config.GetLogger()
+
+	context, err := config.GetCurrentContext()
+	if err != nil {
+		return err
+	}
+
+	sessions, err := context.GetSessions()
+	if err != nil {
+		return err
+	}
+
+	settings, err := context.ToQuickFixAcceptorSettings()
+	if err != nil {
+		return err
+	}
+
+	transportDict, appDict, err := sessions[0].GetFIXDictionaries()
+	if err != nil {
+		return err
+	}
+
+	app := application.NewMarketDataAcceptor(&application.MarketDataAcceptorOptions{
+		Price:  optionPrice,
+		Spread: optionSpread,
+		Size:   optionSize,
+		Jitter: optionJitter,
+	})
+	app.TransportDataDictionary = transportDict
+	app.AppDataDictionary = appDict
+	app.Logger = logger
+
+	var quickfixLogger *zerolog.Logger
+	if options.QuickFixLogging {
+		quickfixLogger = logger
+	}
+
+	acc, err := acceptor.NewAcceptor(app, settings, quickfixLogger)
+	if err != nil {
+		return err
+	}
+
+	// Start session
+	if err = acc.Start(); err != nil {
+		return err
+	}
+
+	defer acc.Stop()
+
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt, syscall.SIGTERM)
+
+	select {
+	case <-ctx.Done():
+		return errors.MaxRuntimeExceeded
+	case signal := <-interrupt:
+		logger.Debug().Msgf("Received signal: %s", signal)
+	}
+
+	return nil
+}
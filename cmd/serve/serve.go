@@ -0,0 +1,24 @@
+package serve
+
+import (
+	"github.com/spf13/cobra"
+
+	serve_marketdata "sylr.dev/fix/cmd/serve/marketdata"
+	"sylr.dev/fix/pkg/acceptor"
+	"sylr.dev/fix/pkg/utils"
+)
+
+var ServeCmd = &cobra.Command{
+	Use:               "serve",
+	Short:             "Launch a FIX acceptor answering requests",
+	Long:              "Launch a FIX acceptor answering requests with synthetic data, meant for local testing.",
+	PersistentPreRunE: utils.MakePersistentPreRunE(acceptor.ValidateOptions),
+}
+
+func init() {
+	acceptor.AddPersistentFlags(ServeCmd)
+	acceptor.AddPersistentFlagCompletions(ServeCmd)
+	acceptor.AddPersistentFlagCompletions(serve_marketdata.ServeMarketDataCmd)
+
+	ServeCmd.AddCommand(serve_marketdata.ServeMarketDataCmd)
+}
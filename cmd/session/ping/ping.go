@@ -0,0 +1,159 @@
+package session_ping
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/spf13/cobra"
+
+	"sylr.dev/fix/config"
+	"sylr.dev/fix/pkg/errors"
+	"sylr.dev/fix/pkg/initiator"
+	"sylr.dev/fix/pkg/initiator/application"
+	"sylr.dev/fix/pkg/utils"
+)
+
+var (
+	optionConnectOnly bool
+)
+
+var SessionPingCmd = &cobra.Command{
+	Use:               "ping",
+	Short:             "Logon then logoff",
+	Long:              "Initiate a session with a FIX acceptor, wait for the logon and logoff right away without sending any business message.",
+	Args:              cobra.ExactArgs(0),
+	ValidArgsFunction: cobra.NoFileCompletions,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if cmd.HasParent() {
+			parent := cmd.Parent()
+			if parent.PersistentPreRunE != nil {
+				return parent.PersistentPreRunE(parent, args)
+			}
+		}
+
+		return nil
+	},
+	RunE: Execute,
+}
+
+func init() {
+	SessionPingCmd.Flags().BoolVar(&optionConnectOnly, "connect-only", false, "Write the probe result as a single JSON object to stdout")
+}
+
+func Execute(cmd *cobra.Command, args []string) (err error) {
+	result := &Result{}
+	if optionConnectOnly {
+		defer func() {
+			if err != nil {
+				result.Error = err.Error()
+			}
+			if werr := writeResult(result); werr != nil && err == nil {
+				err = werr
+			}
+		}()
+	}
+
+	options := config.GetOptions()
+	ctx, cancel := utils.WithMaxRuntime(cmd.Context(), options.MaxRuntime)
+	defer cancel()
+	logger := config.GetLogger()
+
+	context, err := config.GetCurrentContext()
+	if err != nil {
+		return err
+	}
+
+	sessions, err := context.GetSessions()
+	if err != nil {
+		return err
+	}
+
+	session := sessions[0]
+	initiatior, err := context.GetInitiator()
+	if err != nil {
+		return err
+	}
+
+	transportDict, appDict, err := session.GetFIXDictionaries()
+	if err != nil {
+		return err
+	}
+
+	settings, err := context.ToQuickFixInitiatorSettings()
+	if err != nil {
+		return err
+	}
+
+	app := application.NewInitiator()
+	app.Logger = logger
+	app.Settings = settings
+	app.TransportDataDictionary = transportDict
+	app.AppDataDictionary = appDict
+
+	var quickfixLogger *zerolog.Logger
+	if options.QuickFixLogging {
+		quickfixLogger = logger
+	}
+
+	probe := newProbe(app)
+	init, err := initiator.Initiate(probe, settings, quickfixLogger)
+	if err != nil {
+		return err
+	}
+
+	// Start session
+	start := time.Now()
+	if err = init.Start(); err != nil {
+		return err
+	}
+
+	defer func() {
+		app.Stop()
+		init.Stop()
+	}()
+
+	// Choose right timeout cli option > config > default value (5s)
+	var timeout time.Duration
+	if options.Timeout != time.Duration(0) {
+		timeout = options.Timeout
+	} else if initiatior.SocketTimeout != time.Duration(0) {
+		timeout = initiatior.SocketTimeout
+	} else {
+		timeout = 5 * time.Second
+	}
+
+	// Wait for session connection
+	select {
+	case <-ctx.Done():
+		err = errors.MaxRuntimeExceeded
+	case <-time.After(timeout):
+		err = errors.ConnectionTimeout
+	case _, ok := <-app.Connected:
+		if !ok {
+			err = errors.FixLogout
+		}
+	}
+
+	rtt := time.Since(start)
+	connected, failure := probe.outcome()
+	result.Connected = connected
+	result.NegotiatedVersion = probe.negotiatedVersion(session.BeginString, session.DefaultApplVerID)
+
+	if err != nil {
+		if len(failure) > 0 {
+			return fmt.Errorf("%w: %s", err, failure)
+		}
+		return err
+	}
+
+	result.LoggedOn = true
+	result.RTTMs = rtt.Milliseconds()
+
+	logger.Info().
+		Str("session", app.SessionID.String()).
+		Dur("rtt", rtt).
+		Msgf("Logon successful in %s", rtt.Round(time.Millisecond))
+
+	return nil
+}
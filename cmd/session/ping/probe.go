@@ -0,0 +1,131 @@
+package session_ping
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/quickfixgo/enum"
+	"github.com/quickfixgo/quickfix"
+	"github.com/quickfixgo/tag"
+
+	"sylr.dev/fix/pkg/dict"
+)
+
+// Result is the outcome of the probe written by --connect-only.
+type Result struct {
+	Connected         bool   `json:"connected"`
+	LoggedOn          bool   `json:"logged_on"`
+	RTTMs             int64  `json:"rtt_ms"`
+	NegotiatedVersion string `json:"negotiated_version,omitempty"`
+	Error             string `json:"error,omitempty"`
+}
+
+// failureEvents are the prefixes of the quickfix events explaining why the
+// logon did not happen.
+var failureEvents = []string{
+	"Failed to connect",
+	"Failed handshake",
+	"Failed to initiate",
+	"Timed out waiting for logon response",
+}
+
+// probe wraps a quickfix.Application and records how far the session went
+// towards the logon.
+type probe struct {
+	quickfix.Application
+
+	connected bool
+	applVerID string
+	failure   string
+	mux       sync.Mutex
+}
+
+func newProbe(app quickfix.Application) *probe {
+	return &probe{
+		Application: app,
+	}
+}
+
+func (p *probe) ToAdmin(message *quickfix.Message, sessionID quickfix.SessionID) {
+	// The Logon is only sent once the transport is established
+	if message.IsMsgTypeOf(string(enum.MsgType_LOGON)) {
+		p.mux.Lock()
+		p.connected = true
+		p.mux.Unlock()
+	}
+
+	p.Application.ToAdmin(message, sessionID)
+}
+
+func (p *probe) FromAdmin(message *quickfix.Message, sessionID quickfix.SessionID) quickfix.MessageRejectError {
+	p.mux.Lock()
+	switch {
+	case message.IsMsgTypeOf(string(enum.MsgType_LOGON)):
+		if applVerID, err := message.Body.GetString(tag.DefaultApplVerID); err == nil {
+			p.applVerID = applVerID
+		}
+	case message.IsMsgTypeOf(string(enum.MsgType_LOGOUT)):
+		if text, err := message.Body.GetString(tag.Text); err == nil && len(text) > 0 {
+			p.failure = "logout: " + text
+		}
+	}
+	p.mux.Unlock()
+
+	return p.Application.FromAdmin(message, sessionID)
+}
+
+// OnQuickFixEvent records the last event explaining why the logon failed.
+func (p *probe) OnQuickFixEvent(event string) {
+	for _, prefix := range failureEvents {
+		if strings.HasPrefix(event, prefix) {
+			p.mux.Lock()
+			p.failure = event
+			p.mux.Unlock()
+			return
+		}
+	}
+}
+
+// outcome returns whether the transport got established and why the logon
+// failed if it did.
+func (p *probe) outcome() (bool, string) {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+
+	return p.connected, p.failure
+}
+
+// negotiatedVersion returns the BeginString of the session, or the
+// DefaultApplVerID advertised by the acceptor for FIXT sessions.
+func (p *probe) negotiatedVersion(beginString string, defaultApplVerID string) string {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+
+	if beginString != quickfix.BeginStringFIXT11 {
+		return beginString
+	}
+
+	if len(p.applVerID) == 0 {
+		return defaultApplVerID
+	}
+
+	if version, err := dict.SearchValue(dict.ApplVerIDs, enum.ApplVerID(p.applVerID)); err == nil {
+		return version
+	}
+
+	return p.applVerID
+}
+
+// writeResult writes the result as a single JSON line to stdout.
+func writeResult(result *Result) error {
+	b, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+
+	_, err = os.Stdout.Write(append(b, '\n'))
+
+	return err
+}
@@ -0,0 +1,40 @@
+package session
+
+import (
+	"github.com/spf13/cobra"
+
+	session_ping "sylr.dev/fix/cmd/session/ping"
+	"sylr.dev/fix/pkg/initiator"
+)
+
+var SessionCmd = &cobra.Command{
+	Use:   "session",
+	Short: "Manage FIX sessions",
+	Long:  "Manage FIX sessions without sending any business message.",
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		err := initiator.ValidateOptions(cmd, args)
+		if err != nil {
+			return err
+		}
+
+		if cmd.HasParent() {
+			parent := cmd.Parent()
+			if parent.PersistentPreRunE != nil {
+				err = parent.PersistentPreRunE(parent, args)
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	initiator.AddPersistentFlags(SessionCmd)
+	initiator.AddPersistentFlagCompletions(SessionCmd)
+	initiator.AddPersistentFlagCompletions(session_ping.SessionPingCmd)
+
+	SessionCmd.AddCommand(session_ping.SessionPingCmd)
+}
@@ -8,7 +8,6 @@ import (
 	"syscall"
 	"time"
 
-	"github.com/google/uuid"
 	"github.com/rs/zerolog"
 	"github.com/spf13/cobra"
 
@@ -19,6 +18,7 @@ import (
 
 	"sylr.dev/fix/config"
 	"sylr.dev/fix/pkg/cli/complete"
+	"sylr.dev/fix/pkg/cli/options"
 	"sylr.dev/fix/pkg/dict"
 	"sylr.dev/fix/pkg/errors"
 	"sylr.dev/fix/pkg/initiator"
@@ -31,6 +31,8 @@ var (
 	optionSubType           string
 	optionSymbol            string
 
+	securityStatReqIDOptions *options.IDOptions
+
 	SubType enum.SubscriptionRequestType
 )
 
@@ -40,6 +42,7 @@ var StatusSecurityCmd = &cobra.Command{
 	Long:              "Send a security Session Status Request after initiating a session with a FIX acceptor.",
 	Args:              cobra.ExactArgs(0),
 	ValidArgsFunction: cobra.NoFileCompletions,
+	Annotations:       utils.MessageAnnotations(string(enum.MsgType_SECURITY_STATUS_REQUEST)),
 	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
 		err := Validate(cmd, args)
 		if err != nil {
@@ -64,7 +67,7 @@ var StatusSecurityCmd = &cobra.Command{
 func init() {
 	StatusSecurityCmd.Flags().StringVar(&optionSymbol, "symbol", "", "Symbol")
 	StatusSecurityCmd.Flags().StringVar(&optionSubType, "subscription-type", "snapshot", "Subscription type")
-	StatusSecurityCmd.Flags().StringVar(&optionSecurityStatReqID, "security-status-request-id", uuid.NewString(), "Security Status Request id")
+	securityStatReqIDOptions = options.NewIDOptions(StatusSecurityCmd, &optionSecurityStatReqID, "security-status-request-id", "SecurityStatusReqID", "Security Status Request id")
 	StatusSecurityCmd.RegisterFlagCompletionFunc("subscription-type", complete.SubscriptionRequestTypes)
 }
 
@@ -73,8 +76,8 @@ func Validate(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("%w: --symbol can not be empty", errors.Options)
 	}
 
-	if len(optionSecurityStatReqID) == 0 {
-		return fmt.Errorf("%w: --security-status-request-id can not be empty", errors.Options)
+	if err := securityStatReqIDOptions.Validate(); err != nil {
+		return err
 	}
 
 	var ok bool
@@ -87,6 +90,8 @@ func Validate(cmd *cobra.Command, args []string) error {
 
 func Execute(cmd *cobra.Command, args []string) error {
 	options := config.GetOptions()
+	ctx, cancel := utils.WithMaxRuntime(cmd.Context(), options.MaxRuntime)
+	defer cancel()
 	logger := config.GetLogger()
 
 	context, err := config.GetCurrentContext()
@@ -153,6 +158,8 @@ func Execute(cmd *cobra.Command, args []string) error {
 
 	// Wait for session connection
 	select {
+	case <-ctx.Done():
+		return errors.MaxRuntimeExceeded
 	case <-time.After(timeout):
 		return errors.ConnectionTimeout
 	case _, ok := <-app.Connected:
@@ -179,6 +186,8 @@ func Execute(cmd *cobra.Command, args []string) error {
 LOOP:
 	for {
 		select {
+		case <-ctx.Done():
+			return errors.MaxRuntimeExceeded
 		case signal := <-interrupt:
 			logger.Debug().Msgf("Received signal: %s", signal)
 
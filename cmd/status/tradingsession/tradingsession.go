@@ -35,6 +35,7 @@ var StatusTradingSessionCmd = &cobra.Command{
 	Long:              "Send a Trading Session Status Request after initiating a session with a FIX acceptor.",
 	Args:              cobra.ExactArgs(0),
 	ValidArgsFunction: cobra.NoFileCompletions,
+	Annotations:       utils.MessageAnnotations(string(enum.MsgType_TRADING_SESSION_STATUS_REQUEST)),
 	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
 		err := Validate(cmd, args)
 		if err != nil {
@@ -77,6 +78,8 @@ func Validate(cmd *cobra.Command, args []string) error {
 
 func Execute(cmd *cobra.Command, args []string) error {
 	options := config.GetOptions()
+	ctx, cancel := utils.WithMaxRuntime(cmd.Context(), options.MaxRuntime)
+	defer cancel()
 	logger := config.GetLogger()
 
 	context, err := config.GetCurrentContext()
@@ -143,6 +146,8 @@ func Execute(cmd *cobra.Command, args []string) error {
 
 	// Wait for session connection
 	select {
+	case <-ctx.Done():
+		return errors.MaxRuntimeExceeded
 	case <-time.After(timeout):
 		return errors.ConnectionTimeout
 	case _, ok := <-app.Connected:
@@ -168,6 +173,8 @@ func Execute(cmd *cobra.Command, args []string) error {
 	var responseMessage *quickfix.Message
 
 	select {
+	case <-ctx.Done():
+		return errors.MaxRuntimeExceeded
 	case <-time.After(timeout):
 		return errors.ResponseTimeout
 
@@ -0,0 +1,103 @@
+package util_diff
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"sylr.dev/fix/pkg/cli/options"
+	"sylr.dev/fix/pkg/errors"
+	"sylr.dev/fix/pkg/utils"
+)
+
+var (
+	optionDicts      options.DictionaryOptions
+	optionIgnoreTags []int
+)
+
+var UtilDiffCmd = &cobra.Command{
+	Use:   "diff <a> <b>",
+	Short: "Diff two captured FIX messages",
+	Long: "Parse two captured FIX messages (SOH or `|` delimited) and print a field level diff.\n" +
+		"Exits with a non-zero status if the messages differ.",
+	Args: cobra.ExactArgs(2),
+	RunE: Execute,
+}
+
+func init() {
+	options.AddDictionaryFlags(UtilDiffCmd, &optionDicts)
+	UtilDiffCmd.Flags().IntSliceVar(&optionIgnoreTags, "ignore-tag", []int{9, 10, 34, 52}, "Tags to ignore")
+}
+
+func Execute(cmd *cobra.Command, args []string) error {
+	transportDict, appDict, err := optionDicts.GetDictionaries(cmd)
+	if err != nil {
+		return err
+	}
+
+	trees := make([][]utils.MessageFieldPath, len(args))
+	for i, path := range args {
+		raw, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		msg, err := utils.ParseRawMessage(raw, transportDict, appDict)
+		if err != nil {
+			return fmt.Errorf("%w: unable to parse %s: %s", errors.Fix, path, err)
+		}
+
+		trees[i] = utils.FlattenMessageTree(utils.MessageTree(msg, transportDict, appDict))
+	}
+
+	if n := diff(os.Stdout, trees[0], trees[1]); n > 0 {
+		return fmt.Errorf("%w: %d field(s)", errors.MessagesDiffer, n)
+	}
+
+	return nil
+}
+
+// diff writes the differences between a and b to w and returns their count.
+func diff(w io.Writer, a, b []utils.MessageFieldPath) int {
+	bFields := make(map[string]*utils.MessageField, len(b))
+	for _, p := range b {
+		bFields[p.Path] = p.Field
+	}
+
+	aFields := make(map[string]*utils.MessageField, len(a))
+	differences := 0
+
+	for _, p := range a {
+		aFields[p.Path] = p.Field
+		if ignored(p.Field.Tag) {
+			continue
+		}
+
+		bField, ok := bFields[p.Path]
+		switch {
+		case !ok:
+			fmt.Fprintf(w, "- %s=%s\n", p.Path, p.Field.Value)
+			differences++
+		case bField.Value != p.Field.Value:
+			fmt.Fprintf(w, "~ %s=%s => %s\n", p.Path, p.Field.Value, bField.Value)
+			differences++
+		}
+	}
+
+	for _, p := range b {
+		if _, ok := aFields[p.Path]; ok || ignored(p.Field.Tag) {
+			continue
+		}
+
+		fmt.Fprintf(w, "+ %s=%s\n", p.Path, p.Field.Value)
+		differences++
+	}
+
+	return differences
+}
+
+func ignored(tag int) bool {
+	return utils.Search(optionIgnoreTags, tag) >= 0
+}
@@ -0,0 +1,78 @@
+package util_fromjson
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"sylr.dev/fix/pkg/cli/options"
+	"sylr.dev/fix/pkg/errors"
+	"sylr.dev/fix/pkg/utils"
+)
+
+var (
+	optionDicts options.DictionaryOptions
+)
+
+var UtilFromJSONCmd = &cobra.Command{
+	Use:   "fromjson [file]",
+	Short: "Convert JSON to FIX messages",
+	Long: "Convert JSON arrays of fields, as written by `fix util tojson`, read from a file or stdin to raw FIX messages, " +
+		"one per line. Fields are given by tag or by name, BodyLength and CheckSum are computed.",
+	Args: cobra.MaximumNArgs(1),
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return nil, cobra.ShellCompDirectiveDefault
+	},
+	RunE: Execute,
+}
+
+func init() {
+	options.AddDictionaryFlags(UtilFromJSONCmd, &optionDicts)
+}
+
+func Execute(cmd *cobra.Command, args []string) error {
+	transportDict, appDict, err := optionDicts.GetDictionaries(cmd)
+	if err != nil {
+		return err
+	}
+
+	var r io.Reader = os.Stdin
+	if len(args) > 0 && args[0] != "-" {
+		f, err := os.Open(args[0])
+		if err != nil {
+			return fmt.Errorf("%w: %s", errors.Options, err)
+		}
+		defer f.Close()
+		r = f
+	}
+
+	w := bufio.NewWriter(os.Stdout)
+	defer w.Flush()
+
+	decoder := json.NewDecoder(r)
+	for i := 1; ; i++ {
+		fields := []*utils.MessageField{}
+		if err := decoder.Decode(&fields); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("%w: message %d: %s", errors.Options, i, err)
+		}
+
+		raw, err := utils.EncodeMessageTree(fields, appDict, transportDict)
+		if err != nil {
+			return fmt.Errorf("%w: message %d: %s", errors.Fix, i, err)
+		}
+
+		// Make sure the message can be read back
+		if _, err := utils.ParseRawMessage(raw, transportDict, appDict); err != nil {
+			return fmt.Errorf("%w: message %d: %s", errors.Fix, i, err)
+		}
+
+		w.Write(raw)
+		w.WriteByte('\n')
+	}
+}
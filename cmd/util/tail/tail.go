@@ -0,0 +1,205 @@
+package util_tail
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/quickfixgo/quickfix"
+	"github.com/quickfixgo/quickfix/datadictionary"
+	"github.com/spf13/cobra"
+
+	"sylr.dev/fix/config"
+	"sylr.dev/fix/pkg/cli/options"
+	"sylr.dev/fix/pkg/errors"
+	"sylr.dev/fix/pkg/utils"
+)
+
+// followInterval is the interval at which the log file is polled when
+// following it.
+const followInterval = 250 * time.Millisecond
+
+var (
+	optionDicts  options.DictionaryOptions
+	optionFollow bool
+	optionFields []string
+)
+
+var UtilTailCmd = &cobra.Command{
+	Use:   "tail <logfile>",
+	Short: "Pretty-print a quickfix message log",
+	Long: "Print the FIX messages of a quickfix message log file with the tag names and enum labels of the data " +
+		"dictionaries, one message per line. Lines which do not hold a FIX message are skipped.",
+	Args: cobra.ExactArgs(1),
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return nil, cobra.ShellCompDirectiveDefault
+	},
+	RunE: Execute,
+}
+
+func init() {
+	options.AddDictionaryFlags(UtilTailCmd, &optionDicts)
+	UtilTailCmd.Flags().BoolVarP(&optionFollow, "follow", "f", false, "Output appended messages as the file grows")
+	UtilTailCmd.Flags().StringSliceVar(&optionFields, "fields", []string{}, "Only print these fields, given by tag or name")
+}
+
+func Execute(cmd *cobra.Command, args []string) error {
+	ctx, cancel := utils.WithMaxRuntime(cmd.Context(), config.GetOptions().MaxRuntime)
+	defer cancel()
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	logger := config.GetLogger()
+
+	transportDict, appDict, err := optionDicts.GetDictionaries(cmd)
+	if err != nil {
+		return err
+	}
+
+	fields, err := parseFields(optionFields, transportDict, appDict)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Open(args[0])
+	if err != nil {
+		return fmt.Errorf("%w: %s", errors.Options, err)
+	}
+	defer f.Close()
+
+	printer := &utils.QuickFixAppMessageLogger{
+		TransportDataDictionary: transportDict,
+		AppDataDictionary:       appDict,
+	}
+	w := bufio.NewWriter(os.Stdout)
+	defer w.Flush()
+
+	printLine := func(line []byte) {
+		raw := bytes.TrimSpace(utils.RestoreSOH(line))
+		if len(raw) == 0 || raw[0] == '#' {
+			return
+		}
+
+		captured, err := utils.ParseCapturedMessage(raw, transportDict, appDict)
+		if err == utils.ErrNoFIXMessage {
+			return
+		} else if err != nil {
+			logger.Warn().Msgf("Skipping unparsable message: %s", err)
+			return
+		}
+
+		writeMessage(w, printer, captured, fields)
+	}
+
+	reader := bufio.NewReader(f)
+	partial := []byte{}
+	offset := int64(0)
+
+	for {
+		line, err := reader.ReadBytes('\n')
+		offset += int64(len(line))
+
+		if err == nil {
+			printLine(append(partial, line...))
+			partial = partial[:0]
+			continue
+		} else if err != io.EOF {
+			return err
+		}
+
+		// Keep incomplete lines until the rest of them is written
+		partial = append(partial, line...)
+
+		if !optionFollow {
+			if len(partial) > 0 {
+				printLine(partial)
+			}
+			return nil
+		}
+
+		w.Flush()
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(followInterval):
+		}
+
+		// Start over when the file has been truncated, e.g. by log rotation
+		if stat, err := f.Stat(); err == nil && stat.Size() < offset {
+			logger.Info().Msgf("%s truncated", args[0])
+			if _, err := f.Seek(0, io.SeekStart); err != nil {
+				return err
+			}
+			reader.Reset(f)
+			partial = partial[:0]
+			offset = 0
+		}
+	}
+}
+
+// writeMessage writes the message fields joined by the SOH delimiter, prefixed
+// by the time found in the log if any. When fields is not empty only these
+// fields are written.
+func writeMessage(w io.Writer, printer *utils.QuickFixAppMessageLogger, captured utils.CapturedMessage, fields []quickfix.Tag) {
+	if !captured.Time.IsZero() {
+		fmt.Fprintf(w, "%s ", captured.Time.Format(time.RFC3339Nano))
+	}
+
+	written := 0
+	for _, field := range captured.Message.GetFields() {
+		if len(fields) > 0 && utils.Search(fields, field.Tag()) < 0 {
+			continue
+		}
+		if written > 0 {
+			w.Write(utils.SOHDelimiter())
+		}
+		printer.WriteField(w, field)
+		written++
+	}
+
+	fmt.Fprintln(w)
+}
+
+// parseFields returns the tags of the given fields which are either tag
+// numbers or field names known by the dictionaries.
+func parseFields(fields []string, dicts ...*datadictionary.DataDictionary) ([]quickfix.Tag, error) {
+	tags := make([]quickfix.Tag, 0, len(fields))
+
+	for _, f := range fields {
+		if t, err := strconv.Atoi(f); err == nil {
+			tags = append(tags, quickfix.Tag(t))
+			continue
+		}
+
+		t, ok := fieldTag(f, dicts...)
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown field `%s`", errors.Options, f)
+		}
+		tags = append(tags, quickfix.Tag(t))
+	}
+
+	return tags, nil
+}
+
+func fieldTag(name string, dicts ...*datadictionary.DataDictionary) (int, bool) {
+	for _, dict := range dicts {
+		if dict == nil {
+			continue
+		}
+		for tag, ft := range dict.FieldTypeByTag {
+			if strings.EqualFold(ft.Name(), name) {
+				return tag, true
+			}
+		}
+	}
+
+	return 0, false
+}
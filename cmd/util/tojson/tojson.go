@@ -0,0 +1,71 @@
+package util_tojson
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"sylr.dev/fix/pkg/cli/options"
+	"sylr.dev/fix/pkg/errors"
+	"sylr.dev/fix/pkg/utils"
+)
+
+var (
+	optionDicts  options.DictionaryOptions
+	optionPretty bool
+)
+
+var UtilToJSONCmd = &cobra.Command{
+	Use:   "tojson [file]",
+	Short: "Convert FIX messages to JSON",
+	Long: "Convert raw FIX messages (SOH or `|` delimited, one per line) read from a file or stdin to JSON arrays of " +
+		"fields in wire order, named after the data dictionaries, with repeating group entries nested under their counter.",
+	Args: cobra.MaximumNArgs(1),
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return nil, cobra.ShellCompDirectiveDefault
+	},
+	RunE: Execute,
+}
+
+func init() {
+	options.AddDictionaryFlags(UtilToJSONCmd, &optionDicts)
+	UtilToJSONCmd.Flags().BoolVar(&optionPretty, "pretty", false, "Print indented JSON")
+}
+
+func Execute(cmd *cobra.Command, args []string) error {
+	transportDict, appDict, err := optionDicts.GetDictionaries(cmd)
+	if err != nil {
+		return err
+	}
+
+	var r io.Reader = os.Stdin
+	if len(args) > 0 && args[0] != "-" {
+		f, err := os.Open(args[0])
+		if err != nil {
+			return fmt.Errorf("%w: %s", errors.Options, err)
+		}
+		defer f.Close()
+		r = f
+	}
+
+	messages, err := utils.ReadRawMessages(r, transportDict, appDict)
+	if err != nil {
+		return fmt.Errorf("%w: %s", errors.Fix, err)
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	if optionPretty {
+		encoder.SetIndent("", "  ")
+	}
+
+	for _, msg := range messages {
+		if err := encoder.Encode(utils.MessageTree(msg, transportDict, appDict)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,24 @@
+package util
+
+import (
+	"github.com/spf13/cobra"
+
+	util_diff "sylr.dev/fix/cmd/util/diff"
+	util_fromjson "sylr.dev/fix/cmd/util/fromjson"
+	util_tail "sylr.dev/fix/cmd/util/tail"
+	util_tojson "sylr.dev/fix/cmd/util/tojson"
+)
+
+// UtilCmd represents the util command
+var UtilCmd = &cobra.Command{
+	Use:   "util",
+	Short: "FIX utilities",
+	Long:  "FIX utilities working on captured messages and logs.",
+}
+
+func init() {
+	UtilCmd.AddCommand(util_diff.UtilDiffCmd)
+	UtilCmd.AddCommand(util_tail.UtilTailCmd)
+	UtilCmd.AddCommand(util_tojson.UtilToJSONCmd)
+	UtilCmd.AddCommand(util_fromjson.UtilFromJSONCmd)
+}
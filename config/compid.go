@@ -0,0 +1,57 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+
+	"sylr.dev/fix/pkg/errors"
+)
+
+// compIDTemplateData is the data given to CompID templates.
+type compIDTemplateData struct {
+	now time.Time
+}
+
+// Date returns the current UTC date formatted with the given Go time layout.
+func (d compIDTemplateData) Date(layout string) string {
+	return d.now.UTC().Format(layout)
+}
+
+// ExpandCompIDs expands the templates found in the Sender/Target Comp/Sub IDs
+// of all sessions, e.g. `CLIENT_{{.Date "20060102"}}`.
+func (f *fixConfig) ExpandCompIDs(now time.Time) error {
+	data := compIDTemplateData{now: now}
+
+	for _, session := range f.Sessions {
+		for _, id := range []*string{&session.SenderCompID, &session.SenderSubID, &session.TargetCompID, &session.TargetSubID} {
+			expanded, err := expandCompID(*id, data)
+			if err != nil {
+				return fmt.Errorf("%w: session %s: %s", errors.Config, session.Name, err)
+			}
+			*id = expanded
+		}
+	}
+
+	return nil
+}
+
+func expandCompID(value string, data compIDTemplateData) (string, error) {
+	if !strings.Contains(value, "{{") {
+		return value, nil
+	}
+
+	tpl, err := template.New("compid").Option("missingkey=error").Parse(value)
+	if err != nil {
+		return "", err
+	}
+
+	buf := bytes.Buffer{}
+	if err := tpl.Execute(&buf, data); err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
@@ -0,0 +1,96 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"sylr.dev/fix/pkg/errors"
+)
+
+func TestExpandCompIDs(t *testing.T) {
+	// 23:30 in New York is already the next day in UTC
+	now := time.Date(2022, time.December, 31, 23, 30, 0, 0, time.FixedZone("EST", -5*3600))
+
+	tests := []struct {
+		name    string
+		session Session
+		want    Session
+		wantErr bool
+	}{
+		{
+			name: "no template",
+			session: Session{
+				Name:         "plain",
+				SenderCompID: "CLIENT",
+				TargetCompID: "VENUE",
+			},
+			want: Session{
+				Name:         "plain",
+				SenderCompID: "CLIENT",
+				TargetCompID: "VENUE",
+			},
+		},
+		{
+			name: "utc date",
+			session: Session{
+				Name:         "daily",
+				SenderCompID: `CLIENT_{{.Date "20060102"}}`,
+				SenderSubID:  `{{.Date "2006"}}`,
+				TargetCompID: "VENUE",
+				TargetSubID:  `DESK-{{.Date "01-02"}}`,
+			},
+			want: Session{
+				Name:         "daily",
+				SenderCompID: "CLIENT_20230101",
+				SenderSubID:  "2023",
+				TargetCompID: "VENUE",
+				TargetSubID:  "DESK-01-01",
+			},
+		},
+		{
+			name: "unknown function",
+			session: Session{
+				Name:         "broken",
+				SenderCompID: `CLIENT_{{.Time}}`,
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid template",
+			session: Session{
+				Name:         "broken",
+				TargetCompID: `VENUE_{{.Date "2006"`,
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			session := tt.session
+			f := &fixConfig{Sessions: []*Session{&session}}
+
+			err := f.ExpandCompIDs(now)
+			if tt.wantErr {
+				if !errors.Is(err, errors.Config) {
+					t.Fatalf("ExpandCompIDs() error = %v, want %v", err, errors.Config)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ExpandCompIDs() error = %v", err)
+			}
+
+			for _, id := range []struct{ name, got, want string }{
+				{"SenderCompID", session.SenderCompID, tt.want.SenderCompID},
+				{"SenderSubID", session.SenderSubID, tt.want.SenderSubID},
+				{"TargetCompID", session.TargetCompID, tt.want.TargetCompID},
+				{"TargetSubID", session.TargetSubID, tt.want.TargetSubID},
+			} {
+				if id.got != id.want {
+					t.Errorf("%s = %q, want %q", id.name, id.got, id.want)
+				}
+			}
+		})
+	}
+}
@@ -2,19 +2,28 @@ package config
 
 import (
 	"fmt"
+	"net"
 	"os"
 	"path/filepath"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/quickfixgo/quickfix"
 	qconfig "github.com/quickfixgo/quickfix/config"
 	"github.com/quickfixgo/quickfix/datadictionary"
 
+	"sylr.dev/fix/pkg/dict"
 	"sylr.dev/fix/pkg/errors"
 	"sylr.dev/fix/pkg/utils"
 )
 
+// Session settings which are not part of quickfix/config.
+const (
+	SocketConnectLocalHost = "SocketConnectLocalHost"
+	SocketConnectLocalPort = "SocketConnectLocalPort"
+)
+
 var (
 	fixDict = make(map[string]*datadictionary.DataDictionary)
 	options = cliOptions{}
@@ -37,7 +46,7 @@ func GetCurrentContext() (*Context, error) {
 	}
 
 	if len(currentContext) == 0 {
-		return nil, fmt.Errorf("%w:no current-context set and no --context given", errors.Config)
+		return nil, fmt.Errorf("%w: no current-context set and no --context given", errors.Config)
 	}
 
 	return GetContext(currentContext)
@@ -92,19 +101,46 @@ func GetSession(name string) (*Session, error) {
 }
 
 type cliOptions struct {
-	Config          string
-	Context         string
-	Session         string
-	Acceptor        string
-	Initiator       string
-	Timeout         time.Duration
-	Verbose         int
-	Interactive     bool
-	LogCaller       bool
-	QuickFixLogging bool
-	Metrics         bool
-	PProf           bool
-	HTTPPort        int
+	Config            string
+	Context           string
+	Session           string
+	Acceptor          string
+	Initiator         string
+	Timeout           time.Duration
+	MaxRuntime        time.Duration
+	Verbose           int
+	Interactive       bool
+	LogCaller         bool
+	LogFormat         string
+	LogLevel          string
+	SOHChar           string
+	MaskTags          []int
+	PasswordFile      string
+	PasswordEnv       string
+	QuickFixLogging   bool
+	ShowFraming       bool
+	AutoApplVerID     bool
+	TransportDict     string
+	AppDict           string
+	SelfDescribing    bool
+	SelfDescribingTag int
+	LogoutText        string
+	BeginString       string
+	DefaultApplVerID  string
+	Metrics           bool
+	PProf             bool
+	PProfAddr         string
+	HTTPPort          int
+
+	ReconnectInterval time.Duration
+
+	// SendingTime is the SendingTime set on the app messages sent, either
+	// RFC3339 or `now`, instead of the current clock.
+	SendingTime string
+
+	// OTelEndpoint is the OTLP/HTTP endpoint the spans of the command are
+	// exported to, tracing being disabled when empty.
+	OTelEndpoint string
 }
 
 type fixConfig struct {
@@ -131,6 +167,85 @@ func (f *fixConfig) Validate() error {
 		return err
 	}
 
+	for _, session := range f.Sessions {
+		switch strings.ToUpper(session.TimeStampPrecision) {
+		case "", "SECONDS", "MILLIS", "MICROS", "NANOS":
+		default:
+			return fmt.Errorf("%w: session %s: unknown TimeStampPrecision `%s`, expecting SECONDS, MILLIS, MICROS or NANOS", errors.Config, session.Name, session.TimeStampPrecision)
+		}
+		if len(session.BeginString) > 0 {
+			if err := ValidateVersions(session.BeginString, session.DefaultApplVerID); err != nil {
+				return fmt.Errorf("session %s: %w", session.Name, err)
+			}
+		}
+		if session.ReconnectInterval < 0 {
+			return fmt.Errorf("%w: session %s: ReconnectInterval must be positive", errors.Config, session.Name)
+		}
+		if session.MaxLatency < 0 || session.MaxLatency%time.Second != 0 {
+			return fmt.Errorf("%w: session %s: MaxLatency must be a positive number of seconds", errors.Config, session.Name)
+		}
+		if session.MaxLatency > 0 && session.CheckLatency != nil && !*session.CheckLatency {
+			return fmt.Errorf("%w: session %s: MaxLatency can't be set when CheckLatency is disabled", errors.Config, session.Name)
+		}
+	}
+
+	for _, acceptor := range f.Acceptors {
+		if err := acceptor.validateTLS(); err != nil {
+			return err
+		}
+	}
+
+	for _, initiator := range f.Initiators {
+		if err := initiator.validateTLS(); err != nil {
+			return err
+		}
+
+		tcp := len(initiator.SocketConnectHost) > 0 || initiator.SocketConnectPort > 0
+		unix := len(initiator.SocketUnixPath) > 0
+		if tcp == unix {
+			return fmt.Errorf("%w: initiator %s: either SocketConnectHost/SocketConnectPort or SocketUnixPath must be set", errors.Config, initiator.Name)
+		}
+		if len(initiator.SocketLocalHost) > 0 && net.ParseIP(initiator.SocketLocalHost) == nil {
+			return fmt.Errorf("%w: initiator %s: invalid SocketLocalHost `%s`, expecting an IP address", errors.Config, initiator.Name, initiator.SocketLocalHost)
+		}
+		if initiator.SocketLocalPort < 0 || initiator.SocketLocalPort > 65535 {
+			return fmt.Errorf("%w: initiator %s: invalid SocketLocalPort `%d`", errors.Config, initiator.Name, initiator.SocketLocalPort)
+		}
+	}
+
+	for _, context := range f.Contexts {
+		if err := validateNames(context.GroupTemplates, errors.ConfigDuplicateGroupTemplate); err != nil {
+			return err
+		}
+		for _, t := range context.GroupTemplates {
+			if err := t.validate(); err != nil {
+				return err
+			}
+		}
+		for _, name := range context.MarketDataGroupTemplates {
+			if _, err := context.GetGroupTemplate(name); err != nil {
+				return fmt.Errorf("context %s: marketDataGroupTemplates: %w", context.Name, err)
+			}
+		}
+		seen := make(map[string]struct{}, len(context.MDEntryTypeOrder))
+		for _, t := range context.MDEntryTypeOrder {
+			if _, ok := dict.MDEntryTypes[strings.ToUpper(t)]; !ok {
+				return fmt.Errorf("%w: context %s: mdEntryTypeOrder: unknown type `%s`", errors.Config, context.Name, t)
+			}
+			if _, ok := seen[strings.ToLower(t)]; ok {
+				return fmt.Errorf("%w: context %s: mdEntryTypeOrder: duplicate type `%s`", errors.Config, context.Name, t)
+			}
+			seen[strings.ToLower(t)] = struct{}{}
+		}
+
+		if len(context.DefaultSubscriptionType) == 0 {
+			continue
+		}
+		if _, ok := dict.SubscriptionRequestTypes[strings.ToUpper(context.DefaultSubscriptionType)]; !ok {
+			return fmt.Errorf("%w: context %s: unknown defaultSubscriptionType `%s`", errors.Config, context.Name, context.DefaultSubscriptionType)
+		}
+	}
+
 	return nil
 }
 
@@ -153,10 +268,42 @@ func validateNames[T HasName](slice []T, err error) error {
 }
 
 type Context struct {
-	Name      string   `yaml:"name"`
-	Initiator string   `yaml:"initiator"`
-	Acceptor  string   `yaml:"acceptor"`
-	Sessions  []string `yaml:"sessions"`
+	Name                  string   `yaml:"name"`
+	Initiator             string   `yaml:"initiator"`
+	Acceptor              string   `yaml:"acceptor"`
+	Sessions              []string `yaml:"sessions"`
+	SupportedMDEntryTypes []string `yaml:"supportedMDEntryTypes"`
+	InstrumentFile        string   `yaml:"instrumentFile"`
+	// DefaultSubscriptionType is used by commands when no subscription type
+	// is given on the command line.
+	DefaultSubscriptionType string `yaml:"defaultSubscriptionType"`
+	// GroupSubscriptionWithSymbols allows a security group or market segment
+	// subscription to be narrowed down to symbols, for venues supporting it.
+	GroupSubscriptionWithSymbols bool `yaml:"groupSubscriptionWithSymbols"`
+	// GroupTemplates declares venue specific repeating groups.
+	GroupTemplates []*GroupTemplate `yaml:"groupTemplates"`
+	// ConformanceProfile is the path of a file listing the fields the venue
+	// requires or forbids per message type.
+	ConformanceProfile string `yaml:"conformanceProfile"`
+	// MarketDataGroupTemplates names the group templates replacing the
+	// standard layout of the MarketDataRequest repeating groups.
+	MarketDataGroupTemplates []string `yaml:"marketDataGroupTemplates"`
+	// EnumOverrides replaces the values sent for enum names, by field name,
+	// for venues using non-standard values (e.g. Side: {buy: B}).
+	EnumOverrides map[string]map[string]string `yaml:"enumOverrides"`
+	// MDEntryTypeOrder is the order of the entry types of the requests sent
+	// with --sort-types, for venues sensitive to it (e.g. [offer, bid]).
+	MDEntryTypeOrder []string `yaml:"mdEntryTypeOrder"`
+}
+
+// ApplyEnumOverrides replaces the builtin enum values with the ones of the
+// context for the rest of the run.
+func (c Context) ApplyEnumOverrides() error {
+	if err := dict.OverrideEnums(c.EnumOverrides); err != nil {
+		return fmt.Errorf("%w: context %s: enumOverrides: %s", errors.Config, c.Name, err)
+	}
+
+	return nil
 }
 
 func (c *Context) GetName() string {
@@ -179,6 +326,12 @@ type common struct {
 	SQLStoreDriver           string        `yaml:"SQLStoreDriver"`
 	SQLStoreDataSourceName   string        `yaml:"SQLStoreDataSourceName"`
 	RejectInvalidMessage     *bool         `yaml:"RejectInvalidMessage,omitempty"`
+
+	// SocketPrivateKeyData, SocketCertificateData and SocketCAData hold base64
+	// encoded PEM material as an alternative to the files.
+	SocketPrivateKeyData  string `yaml:"SocketPrivateKeyData"`
+	SocketCertificateData string `yaml:"SocketCertificateData"`
+	SocketCAData          string `yaml:"SocketCAData"`
 }
 
 func (c *common) GetName() string {
@@ -193,7 +346,7 @@ func (c *common) GetSQLStoreDataSourceName() string {
 	return c.SQLStoreDataSourceName
 }
 
-func (c *common) setQuickFixGlobalSettings(globalSettings *quickfix.SessionSettings, session *quickfix.SessionSettings) {
+func (c *common) setQuickFixGlobalSettings(globalSettings *quickfix.SessionSettings, session *quickfix.SessionSettings) error {
 	session.Set(qconfig.SocketUseSSL, FixBoolString(c.SocketUseSSL))
 	session.Set(qconfig.SocketInsecureSkipVerify, FixBoolString(c.SocketInsecureSkipVerify))
 
@@ -209,14 +362,14 @@ func (c *common) setQuickFixGlobalSettings(globalSettings *quickfix.SessionSetti
 		globalSettings.Set(qconfig.SQLStoreDataSourceName, c.SQLStoreDataSourceName)
 	}
 
-	if len(c.SocketPrivateKeyFile) != 0 {
-		session.Set(qconfig.SocketPrivateKeyFile, c.SocketPrivateKeyFile)
-	}
-	if len(c.SocketCertificateFile) != 0 {
-		session.Set(qconfig.SocketCertificateFile, c.SocketCertificateFile)
-	}
-	if len(c.SocketCAFile) != 0 {
-		session.Set(qconfig.SocketCAFile, c.SocketCAFile)
+	for _, cred := range c.tlsCredentials() {
+		file, err := cred.tlsFile()
+		if err != nil {
+			return err
+		}
+		if len(file) != 0 {
+			session.Set(cred.setting, file)
+		}
 	}
 
 	if options.Timeout != time.Duration(0) {
@@ -226,6 +379,8 @@ func (c *common) setQuickFixGlobalSettings(globalSettings *quickfix.SessionSetti
 	} else {
 		session.Set(qconfig.SocketTimeout, "5s")
 	}
+
+	return nil
 }
 
 type Acceptor struct {
@@ -250,6 +405,16 @@ type Initiator struct {
 	SocketConnectHost string `yaml:"SocketConnectHost"`
 	SocketConnectPort int    `yaml:"SocketConnectPort"`
 	SocketServerName  string `yaml:"SocketServerName"`
+	// SocketLocalHost and SocketLocalPort pin the local address the
+	// connection originates from, for venues whitelisting source addresses.
+	SocketLocalHost string `yaml:"SocketLocalHost"`
+	SocketLocalPort int    `yaml:"SocketLocalPort"`
+	// SocketUnixPath connects to an acceptor listening on a Unix domain socket
+	// instead of SocketConnectHost/SocketConnectPort.
+	SocketUnixPath string `yaml:"SocketUnixPath"`
+	// AppChanBuffer is the number of received application messages buffered
+	// before the session blocks until the command processes them.
+	AppChanBuffer int `yaml:"AppChanBuffer"`
 }
 
 type Session struct {
@@ -273,12 +438,58 @@ type Session struct {
 	ResetOnLogon            bool   `yaml:"ResetOnLogon"`
 	ResetOnLogout           bool   `yaml:"ResetOnLogout"`
 	ResetOnDisconnect       bool   `yaml:"ResetOnDisconnect"`
+	TimeStampPrecision      string `yaml:"TimeStampPrecision"`
+	MarketDataResume        bool   `yaml:"MarketDataResume"`
+	MarketDataResumeTag     int    `yaml:"MarketDataResumeTag"`
+	MaxSymbolsPerRequest    int    `yaml:"MaxSymbolsPerRequest"`
+
+	// ReconnectInterval is the time waited between attempts to reconnect a
+	// dropped initiator connection, quickfix defaults to 30s.
+	ReconnectInterval time.Duration `yaml:"ReconnectInterval"`
+
+	// ValidateFieldsOutOfOrder, CheckLatency and MaxLatency relax the quickfix
+	// validation of incoming messages for venues which do not conform to the
+	// specification, quickfix checks them by default.
+	ValidateFieldsOutOfOrder *bool         `yaml:"ValidateFieldsOutOfOrder,omitempty"`
+	CheckLatency             *bool         `yaml:"CheckLatency,omitempty"`
+	MaxLatency               time.Duration `yaml:"MaxLatency"`
+}
+
+// setQuickFixValidationSettings sets the validation relaxations of the session.
+func (s *Session) setQuickFixValidationSettings(session *quickfix.SessionSettings) {
+	setSessionSetting(session, qconfig.ValidateFieldsOutOfOrder, s.ValidateFieldsOutOfOrder)
+	setSessionSetting(session, qconfig.CheckLatency, s.CheckLatency)
+	setSessionSetting(session, qconfig.MaxLatency, s.MaxLatency)
 }
 
 func (s *Session) GetName() string {
 	return s.Name
 }
 
+// GetInstruments returns the symbols listed in the context instrument file, one
+// per line. Empty lines and lines starting with # are ignored.
+func (c Context) GetInstruments() ([]string, error) {
+	if len(c.InstrumentFile) == 0 {
+		return nil, nil
+	}
+
+	b, err := os.ReadFile(os.ExpandEnv(c.InstrumentFile))
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to read instrument file: %s", errors.Config, err)
+	}
+
+	instruments := []string{}
+	for _, line := range strings.Split(string(b), "\n") {
+		line = strings.TrimSpace(line)
+		if len(line) == 0 || strings.HasPrefix(line, "#") {
+			continue
+		}
+		instruments = append(instruments, line)
+	}
+
+	return instruments, nil
+}
+
 func (c Context) GetInitiator() (*Initiator, error) {
 	return GetInitiator(c.Initiator)
 }
@@ -335,11 +546,28 @@ func (c Context) ToQuickFixInitiatorSettings() (*quickfix.Settings, error) {
 	session := sessions[0]
 
 	sessionSettings := quickfix.NewSessionSettings()
-	initiator.setQuickFixGlobalSettings(globalSettings, sessionSettings)
+	if err := initiator.setQuickFixGlobalSettings(globalSettings, sessionSettings); err != nil {
+		return nil, err
+	}
 
-	setSessionSetting(sessionSettings, qconfig.SocketConnectHost, initiator.SocketConnectHost)
-	setSessionSetting(sessionSettings, qconfig.SocketConnectPort, initiator.SocketConnectPort)
+	// quickfix only dials TCP so Unix domain sockets are reached through a
+	// local proxy
+	if len(initiator.SocketUnixPath) > 0 {
+		addr, err := utils.ListenUnixProxy(os.ExpandEnv(initiator.SocketUnixPath))
+		if err != nil {
+			return nil, err
+		}
+		setSessionSetting(sessionSettings, qconfig.SocketConnectHost, addr.IP.String())
+		setSessionSetting(sessionSettings, qconfig.SocketConnectPort, addr.Port)
+	} else {
+		setSessionSetting(sessionSettings, qconfig.SocketConnectHost, initiator.SocketConnectHost)
+		setSessionSetting(sessionSettings, qconfig.SocketConnectPort, initiator.SocketConnectPort)
+	}
 	setSessionSetting(sessionSettings, qconfig.SocketServerName, initiator.SocketServerName)
+	setSessionSetting(sessionSettings, SocketConnectLocalHost, initiator.SocketLocalHost)
+	if initiator.SocketLocalPort > 0 {
+		setSessionSetting(sessionSettings, SocketConnectLocalPort, initiator.SocketLocalPort)
+	}
 	setSessionSetting(sessionSettings, qconfig.HeartBtInt, session.HeartBtInt)
 	setSessionSetting(sessionSettings, qconfig.BeginString, session.BeginString)
 	setSessionSetting(sessionSettings, qconfig.DefaultApplVerID, session.DefaultApplVerID)
@@ -360,9 +588,18 @@ func (c Context) ToQuickFixInitiatorSettings() (*quickfix.Settings, error) {
 	setSessionSetting(sessionSettings, qconfig.ResetOnLogon, session.ResetOnLogon)
 	setSessionSetting(sessionSettings, qconfig.ResetOnLogout, session.ResetOnLogout)
 	setSessionSetting(sessionSettings, qconfig.ResetOnDisconnect, session.ResetOnDisconnect)
+	setSessionSetting(sessionSettings, qconfig.TimeStampPrecision, strings.ToUpper(session.TimeStampPrecision))
 	setSessionSetting(sessionSettings, qconfig.SQLStoreDriver, initiator.SQLStoreDriver)
 	setSessionSetting(sessionSettings, qconfig.SQLStoreDataSourceName, os.ExpandEnv(initiator.SQLStoreDataSourceName))
 	setSessionSetting(sessionSettings, qconfig.RejectInvalidMessage, initiator.RejectInvalidMessage)
+	session.setQuickFixValidationSettings(sessionSettings)
+
+	// quickfix accepts either a number of seconds or a duration
+	if options.ReconnectInterval != time.Duration(0) {
+		sessionSettings.Set(qconfig.ReconnectInterval, options.ReconnectInterval.String())
+	} else if session.ReconnectInterval != time.Duration(0) {
+		sessionSettings.Set(qconfig.ReconnectInterval, session.ReconnectInterval.String())
+	}
 
 	if options.Timeout != time.Duration(0) {
 		sessionSettings.Set(qconfig.LogonTimeout, FixIntString(int(options.Timeout.Seconds())))
@@ -432,7 +669,9 @@ func (c Context) ToQuickFixAcceptorSettings() (*quickfix.Settings, error) {
 
 	for _, session := range sessions {
 		sessionSettings := quickfix.NewSessionSettings()
-		acceptor.setQuickFixGlobalSettings(globalSettings, sessionSettings)
+		if err := acceptor.setQuickFixGlobalSettings(globalSettings, sessionSettings); err != nil {
+			return nil, err
+		}
 
 		sessionSettings.Set(qconfig.SocketAcceptHost, acceptor.SocketAcceptHost)
 		sessionSettings.Set(qconfig.SocketAcceptPort, strconv.Itoa(acceptor.SocketAcceptPort))
@@ -455,9 +694,11 @@ func (c Context) ToQuickFixAcceptorSettings() (*quickfix.Settings, error) {
 		setSessionSetting(sessionSettings, qconfig.ResetOnLogon, session.ResetOnLogon)
 		setSessionSetting(sessionSettings, qconfig.ResetOnLogout, session.ResetOnLogout)
 		setSessionSetting(sessionSettings, qconfig.ResetOnDisconnect, session.ResetOnDisconnect)
+		setSessionSetting(sessionSettings, qconfig.TimeStampPrecision, strings.ToUpper(session.TimeStampPrecision))
 		setSessionSetting(sessionSettings, qconfig.SQLStoreDriver, acceptor.SQLStoreDriver)
 		setSessionSetting(sessionSettings, qconfig.SQLStoreDataSourceName, os.ExpandEnv(acceptor.SQLStoreDataSourceName))
 		setSessionSetting(sessionSettings, qconfig.RejectInvalidMessage, acceptor.RejectInvalidMessage)
+		session.setQuickFixValidationSettings(sessionSettings)
 
 		if options.Timeout != time.Duration(0) {
 			sessionSettings.Set(qconfig.LogonTimeout, FixIntString(int(options.Timeout.Seconds())))
@@ -481,30 +722,49 @@ func (c Context) ToQuickFixAcceptorSettings() (*quickfix.Settings, error) {
 }
 
 func (s Session) GetFIXDictionaries() (*datadictionary.DataDictionary, *datadictionary.DataDictionary, error) {
+	var transportDict, appDict *datadictionary.DataDictionary
 	var err error
-	var ok bool
 
 	if len(s.TransportDataDictionary) > 0 {
-		if _, ok = fixDict[s.TransportDataDictionary]; !ok {
-			path := os.ExpandEnv(s.TransportDataDictionary)
-			fixDict[s.TransportDataDictionary], err = datadictionary.Parse(path)
-			if err != nil {
-				return nil, nil, err
-			}
+		transportDict, err = ParseFIXDictionary(s.TransportDataDictionary)
+		if err != nil {
+			return nil, nil, fmt.Errorf("session %s: TransportDataDictionary: %w", s.Name, err)
 		}
 	}
 
 	if len(s.AppDataDictionary) > 0 {
-		if _, ok = fixDict[s.AppDataDictionary]; !ok {
-			path := os.ExpandEnv(s.AppDataDictionary)
-			fixDict[s.AppDataDictionary], err = datadictionary.Parse(path)
-			if err != nil {
-				return nil, nil, err
-			}
+		appDict, err = ParseFIXDictionary(s.AppDataDictionary)
+		if err != nil {
+			return nil, nil, fmt.Errorf("session %s: AppDataDictionary: %w", s.Name, err)
 		}
 	}
 
-	return fixDict[s.TransportDataDictionary], fixDict[s.AppDataDictionary], nil
+	return transportDict, appDict, nil
+}
+
+// ParseFIXDictionary parses the FIX data dictionary located at path (which can
+// contain environment variables) and caches the result. Errors wrap
+// errors.ConfigInvalidDictionary and name the file.
+func ParseFIXDictionary(path string) (*datadictionary.DataDictionary, error) {
+	if dd, ok := fixDict[path]; ok {
+		return dd, nil
+	}
+
+	file := os.ExpandEnv(path)
+	if _, err := os.Stat(file); os.IsNotExist(err) {
+		return nil, fmt.Errorf("%w: %s: file not found", errors.ConfigInvalidDictionary, file)
+	} else if err != nil {
+		return nil, fmt.Errorf("%w: %s", errors.ConfigInvalidDictionary, err)
+	}
+
+	dd, err := datadictionary.Parse(file)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to parse %s: %s", errors.ConfigInvalidDictionary, file, err)
+	}
+
+	fixDict[path] = dd
+
+	return dd, nil
 }
 
 func FixBoolString(b bool) string {
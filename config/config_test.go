@@ -0,0 +1,327 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/quickfixgo/quickfix"
+	qconfig "github.com/quickfixgo/quickfix/config"
+
+	"sylr.dev/fix/pkg/errors"
+)
+
+// setConfig replaces the configuration and the command line options for the
+// duration of the test.
+func setConfig(t *testing.T, cfg fixConfig, opts cliOptions) {
+	t.Helper()
+
+	oldConfig, oldOptions := config, options
+	t.Cleanup(func() {
+		config, options = oldConfig, oldOptions
+	})
+
+	config, options = cfg, opts
+}
+
+// newTestSession returns a valid session with the fields quickfix requires.
+func newTestSession() *Session {
+	return &Session{
+		Name:             "session",
+		BeginString:      quickfix.BeginStringFIXT11,
+		DefaultApplVerID: "FIX.5.0SP2",
+		SenderCompID:     "CLIENT",
+		TargetCompID:     "VENUE",
+	}
+}
+
+// initiatorSettings returns the quickfix settings of the single session of a
+// context made of initiator and session.
+func initiatorSettings(t *testing.T, initiator *Initiator, session *Session, opts cliOptions) *quickfix.SessionSettings {
+	t.Helper()
+
+	initiator.Name = "initiator"
+	setConfig(t, fixConfig{
+		Initiators: []*Initiator{initiator},
+		Sessions:   []*Session{session},
+	}, opts)
+
+	context := Context{Name: "context", Initiator: initiator.Name, Sessions: []string{session.Name}}
+	settings, err := context.ToQuickFixInitiatorSettings()
+	if err != nil {
+		t.Fatalf("ToQuickFixInitiatorSettings() error = %v", err)
+	}
+
+	return singleSessionSettings(t, settings)
+}
+
+// acceptorSettings returns the quickfix settings of the single session of a
+// context made of acceptor and session.
+func acceptorSettings(t *testing.T, acceptor *Acceptor, session *Session) *quickfix.SessionSettings {
+	t.Helper()
+
+	acceptor.Name = "acceptor"
+	setConfig(t, fixConfig{
+		Acceptors: []*Acceptor{acceptor},
+		Sessions:  []*Session{session},
+	}, cliOptions{})
+
+	context := Context{Name: "context", Acceptor: acceptor.Name, Sessions: []string{session.Name}}
+	settings, err := context.ToQuickFixAcceptorSettings()
+	if err != nil {
+		t.Fatalf("ToQuickFixAcceptorSettings() error = %v", err)
+	}
+
+	return singleSessionSettings(t, settings)
+}
+
+func singleSessionSettings(t *testing.T, settings *quickfix.Settings) *quickfix.SessionSettings {
+	t.Helper()
+
+	sessions := settings.SessionSettings()
+	if len(sessions) != 1 {
+		t.Fatalf("got %d sessions, want 1", len(sessions))
+	}
+	for _, s := range sessions {
+		return s
+	}
+
+	return nil
+}
+
+// checkSetting checks the value of setting, an empty want meaning the
+// setting must not be set.
+func checkSetting(t *testing.T, settings *quickfix.SessionSettings, setting string, want string) {
+	t.Helper()
+
+	if len(want) == 0 {
+		if settings.HasSetting(setting) {
+			got, _ := settings.Setting(setting)
+			t.Errorf("%s = %q, want it unset", setting, got)
+		}
+		return
+	}
+
+	got, err := settings.Setting(setting)
+	if err != nil {
+		t.Errorf("%s: %s", setting, err)
+	} else if got != want {
+		t.Errorf("%s = %q, want %q", setting, got, want)
+	}
+}
+
+func TestTimeStampPrecision(t *testing.T) {
+	tests := []struct {
+		precision string
+		want      string
+		wantErr   bool
+	}{
+		{precision: "", want: ""},
+		{precision: "SECONDS", want: "SECONDS"},
+		{precision: "millis", want: "MILLIS"},
+		{precision: "Micros", want: "MICROS"},
+		{precision: "NANOS", want: "NANOS"},
+		{precision: "PICOS", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		name := tt.precision
+		if len(name) == 0 {
+			name = "default"
+		}
+
+		t.Run(name, func(t *testing.T) {
+			session := newTestSession()
+			session.TimeStampPrecision = tt.precision
+
+			cfg := fixConfig{Sessions: []*Session{session}}
+			err := cfg.Validate()
+			if tt.wantErr {
+				if !errors.Is(err, errors.Config) {
+					t.Fatalf("Validate() error = %v, want %v", err, errors.Config)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Validate() error = %v", err)
+			}
+
+			settings := initiatorSettings(t, &Initiator{SocketConnectHost: "127.0.0.1", SocketConnectPort: 5001}, session, cliOptions{})
+			checkSetting(t, settings, qconfig.TimeStampPrecision, tt.want)
+
+			settings = acceptorSettings(t, &Acceptor{SocketAcceptPort: 5001}, session)
+			checkSetting(t, settings, qconfig.TimeStampPrecision, tt.want)
+		})
+	}
+}
+
+func TestSocketLocalAddress(t *testing.T) {
+	tests := []struct {
+		name     string
+		host     string
+		port     int
+		wantHost string
+		wantPort string
+		wantErr  bool
+	}{
+		{name: "unset"},
+		{name: "host", host: "10.0.0.1", wantHost: "10.0.0.1"},
+		{name: "host and port", host: "::1", port: 40000, wantHost: "::1", wantPort: "40000"},
+		{name: "hostname", host: "localhost", wantErr: true},
+		{name: "negative port", host: "10.0.0.1", port: -1, wantErr: true},
+		{name: "port out of range", port: 65536, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			initiator := &Initiator{
+				SocketConnectHost: "127.0.0.1",
+				SocketConnectPort: 5001,
+				SocketLocalHost:   tt.host,
+				SocketLocalPort:   tt.port,
+			}
+			initiator.Name = "initiator"
+
+			cfg := fixConfig{Initiators: []*Initiator{initiator}}
+			err := cfg.Validate()
+			if tt.wantErr {
+				if !errors.Is(err, errors.Config) {
+					t.Fatalf("Validate() error = %v, want %v", err, errors.Config)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Validate() error = %v", err)
+			}
+
+			settings := initiatorSettings(t, initiator, newTestSession(), cliOptions{})
+			checkSetting(t, settings, SocketConnectLocalHost, tt.wantHost)
+			checkSetting(t, settings, SocketConnectLocalPort, tt.wantPort)
+		})
+	}
+}
+
+func TestReconnectInterval(t *testing.T) {
+	tests := []struct {
+		name    string
+		session time.Duration
+		option  time.Duration
+		want    string
+		wantErr bool
+	}{
+		{name: "quickfix default"},
+		{name: "session", session: 10 * time.Second, want: "10s"},
+		{name: "option overriding the session", session: 10 * time.Second, option: 1500 * time.Millisecond, want: "1.5s"},
+		{name: "option", option: time.Minute, want: "1m0s"},
+		{name: "negative", session: -time.Second, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			session := newTestSession()
+			session.ReconnectInterval = tt.session
+
+			cfg := fixConfig{Sessions: []*Session{session}}
+			err := cfg.Validate()
+			if tt.wantErr {
+				if !errors.Is(err, errors.Config) {
+					t.Fatalf("Validate() error = %v, want %v", err, errors.Config)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Validate() error = %v", err)
+			}
+
+			settings := initiatorSettings(t, &Initiator{SocketConnectHost: "127.0.0.1", SocketConnectPort: 5001}, session, cliOptions{ReconnectInterval: tt.option})
+			checkSetting(t, settings, qconfig.ReconnectInterval, tt.want)
+		})
+	}
+}
+
+func TestValidationSettings(t *testing.T) {
+	yes, no := true, false
+
+	tests := []struct {
+		name             string
+		fieldsOutOfOrder *bool
+		checkLatency     *bool
+		maxLatency       time.Duration
+		want             map[string]string
+		wantErr          bool
+	}{
+		{
+			name: "quickfix defaults",
+			want: map[string]string{
+				qconfig.ValidateFieldsOutOfOrder: "",
+				qconfig.CheckLatency:             "",
+				qconfig.MaxLatency:               "",
+			},
+		},
+		{
+			name:             "relaxed",
+			fieldsOutOfOrder: &no,
+			checkLatency:     &no,
+			want: map[string]string{
+				qconfig.ValidateFieldsOutOfOrder: "N",
+				qconfig.CheckLatency:             "N",
+				qconfig.MaxLatency:               "",
+			},
+		},
+		{
+			name:             "strict with max latency",
+			fieldsOutOfOrder: &yes,
+			checkLatency:     &yes,
+			maxLatency:       5 * time.Second,
+			want: map[string]string{
+				qconfig.ValidateFieldsOutOfOrder: "Y",
+				qconfig.CheckLatency:             "Y",
+				qconfig.MaxLatency:               "5",
+			},
+		},
+		{
+			name:       "max latency under a second",
+			maxLatency: 500 * time.Millisecond,
+			wantErr:    true,
+		},
+		{
+			name:       "negative max latency",
+			maxLatency: -time.Second,
+			wantErr:    true,
+		},
+		{
+			name:         "max latency with latency unchecked",
+			checkLatency: &no,
+			maxLatency:   time.Second,
+			wantErr:      true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			session := newTestSession()
+			session.ValidateFieldsOutOfOrder = tt.fieldsOutOfOrder
+			session.CheckLatency = tt.checkLatency
+			session.MaxLatency = tt.maxLatency
+
+			cfg := fixConfig{Sessions: []*Session{session}}
+			err := cfg.Validate()
+			if tt.wantErr {
+				if !errors.Is(err, errors.Config) {
+					t.Fatalf("Validate() error = %v, want %v", err, errors.Config)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Validate() error = %v", err)
+			}
+
+			initiator := initiatorSettings(t, &Initiator{SocketConnectHost: "127.0.0.1", SocketConnectPort: 5001}, session, cliOptions{})
+			acceptor := acceptorSettings(t, &Acceptor{SocketAcceptPort: 5001}, session)
+
+			for setting, want := range tt.want {
+				checkSetting(t, initiator, setting, want)
+				checkSetting(t, acceptor, setting, want)
+			}
+		})
+	}
+}
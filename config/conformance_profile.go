@@ -0,0 +1,180 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/quickfixgo/quickfix"
+	"github.com/quickfixgo/quickfix/datadictionary"
+	yaml "sylr.dev/yaml/v3"
+
+	"sylr.dev/fix/pkg/errors"
+)
+
+// ConformanceProfile lists the fields a venue requires or forbids per message
+// type, on top of the data dictionaries. Message types are given by value or
+// by name and tags by number or by field name, e.g.:
+//
+//	messages:
+//	  MarketDataRequest:
+//	    required: [MarketDepth, MDUpdateType]
+//	    forbidden: [AggregatedBook]
+type ConformanceProfile struct {
+	Messages map[string]*ConformanceRules `yaml:"messages"`
+}
+
+// ConformanceRules are the required and forbidden fields of a message type.
+type ConformanceRules struct {
+	Required  []string `yaml:"required"`
+	Forbidden []string `yaml:"forbidden"`
+}
+
+// ConformanceProfileSchema returns the JSON Schema of conformance profiles.
+func ConformanceProfileSchema() map[string]interface{} {
+	schema := typeSchema(reflect.TypeOf(ConformanceProfile{}))
+	schema["$schema"] = jsonSchemaDraft
+	schema["title"] = "fix conformance profile"
+
+	return schema
+}
+
+// ReadConformanceProfile reads the conformance profile at path.
+func ReadConformanceProfile(path string) (*ConformanceProfile, error) {
+	file, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to read conformance profile: %s", errors.Config, err)
+	}
+
+	profile := ConformanceProfile{}
+	decoder := yaml.NewDecoder(bytes.NewBuffer(file))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&profile); err != nil {
+		return nil, fmt.Errorf("%w: conformance profile %s: %s", errors.Config, path, err)
+	}
+
+	return &profile, nil
+}
+
+// GetConformanceProfile returns the conformance profile of the context, nil if
+// it does not reference any.
+func (c Context) GetConformanceProfile() (*ConformanceProfile, error) {
+	if len(c.ConformanceProfile) == 0 {
+		return nil, nil
+	}
+
+	return ReadConformanceProfile(os.ExpandEnv(c.ConformanceProfile))
+}
+
+// Check returns the violations of the profile found in the message, fields of
+// repeating groups included. It fails if the rules of the message type refer
+// to tags not defined in the dictionaries.
+func (p *ConformanceProfile) Check(message *quickfix.Message, dicts ...*datadictionary.DataDictionary) ([]string, error) {
+	if p == nil {
+		return nil, nil
+	}
+
+	msgType, err := message.MsgType()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", errors.FixInvalidOutboundMessage, err)
+	}
+
+	rules := p.rules(msgType, dicts)
+	if rules == nil {
+		return nil, nil
+	}
+
+	present := make(map[quickfix.Tag]struct{})
+	for _, pair := range strings.Split(message.String(), "\x01") {
+		if i := strings.IndexByte(pair, '='); i > 0 {
+			if t, err := strconv.Atoi(pair[:i]); err == nil {
+				present[quickfix.Tag(t)] = struct{}{}
+			}
+		}
+	}
+
+	violations := []string{}
+	for _, raw := range rules.Required {
+		t, err := resolveProfileTag(raw, dicts)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := present[t]; !ok {
+			violations = append(violations, fmt.Sprintf("required field %s missing", describeProfileTag(t, dicts)))
+		}
+	}
+	for _, raw := range rules.Forbidden {
+		t, err := resolveProfileTag(raw, dicts)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := present[t]; ok {
+			violations = append(violations, fmt.Sprintf("forbidden field %s present", describeProfileTag(t, dicts)))
+		}
+	}
+
+	sort.Strings(violations)
+
+	return violations, nil
+}
+
+// Validate checks the message against the profile and returns an error
+// listing all the violations found.
+func (p *ConformanceProfile) Validate(message *quickfix.Message, dicts ...*datadictionary.DataDictionary) error {
+	violations, err := p.Check(message, dicts...)
+	if err != nil {
+		return err
+	}
+
+	if len(violations) > 0 {
+		return fmt.Errorf("%w: %s", errors.FixConformanceViolation, strings.Join(violations, ", "))
+	}
+
+	return nil
+}
+
+// rules returns the rules of the message type given by value or by the name
+// the dictionaries define for it.
+func (p *ConformanceProfile) rules(msgType string, dicts []*datadictionary.DataDictionary) *ConformanceRules {
+	if rules, ok := p.Messages[msgType]; ok {
+		return rules
+	}
+
+	for _, d := range dicts {
+		if d == nil {
+			continue
+		}
+		if def, ok := d.Messages[msgType]; ok {
+			if rules, ok := p.Messages[def.Name]; ok {
+				return rules
+			}
+		}
+	}
+
+	return nil
+}
+
+func resolveProfileTag(raw string, dicts []*datadictionary.DataDictionary) (quickfix.Tag, error) {
+	if t, ok := lookupTag(raw, dicts); ok {
+		return t, nil
+	}
+
+	return 0, fmt.Errorf("%w: conformance profile: tag `%s` not defined in the dictionaries", errors.FixDictionaryMismatch, raw)
+}
+
+func describeProfileTag(t quickfix.Tag, dicts []*datadictionary.DataDictionary) string {
+	for _, d := range dicts {
+		if d == nil {
+			continue
+		}
+		if ft, ok := d.FieldTypeByTag[int(t)]; ok {
+			return fmt.Sprintf("%d(%s)", t, ft.Name())
+		}
+	}
+
+	return strconv.Itoa(int(t))
+}
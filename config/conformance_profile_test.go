@@ -0,0 +1,162 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/quickfixgo/enum"
+	"github.com/quickfixgo/field"
+	"github.com/quickfixgo/quickfix"
+	"github.com/quickfixgo/tag"
+
+	"sylr.dev/fix/pkg/errors"
+	"sylr.dev/fix/pkg/testutils"
+)
+
+func TestReadConformanceProfile(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    *ConformanceProfile
+		wantErr bool
+	}{
+		{
+			name: "valid",
+			content: `messages:
+  MarketDataRequest:
+    required: [MarketDepth, 265]
+    forbidden: [AggregatedBook]
+  D:
+    required: [Account]
+`,
+			want: &ConformanceProfile{
+				Messages: map[string]*ConformanceRules{
+					"MarketDataRequest": {Required: []string{"MarketDepth", "265"}, Forbidden: []string{"AggregatedBook"}},
+					"D":                 {Required: []string{"Account"}},
+				},
+			},
+		},
+		{
+			name: "unknown key",
+			content: `messages:
+  MarketDataRequest:
+    mandatory: [MarketDepth]
+`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "profile.yaml")
+			if err := os.WriteFile(path, []byte(tt.content), 0600); err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := ReadConformanceProfile(path)
+			if tt.wantErr {
+				if !errors.Is(err, errors.Config) {
+					t.Fatalf("ReadConformanceProfile() error = %v, want %v", err, errors.Config)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ReadConformanceProfile() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ReadConformanceProfile() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("missing file", func(t *testing.T) {
+		if _, err := ReadConformanceProfile(filepath.Join(t.TempDir(), "missing.yaml")); !errors.Is(err, errors.Config) {
+			t.Errorf("ReadConformanceProfile() error = %v, want %v", err, errors.Config)
+		}
+	})
+}
+
+func TestConformanceProfileCheck(t *testing.T) {
+	transportDict, appDict := testutils.Dictionaries(t)
+
+	request := quickfix.NewMessage()
+	request.Header.Set(field.NewMsgType(enum.MsgType_MARKET_DATA_REQUEST))
+	request.Body.Set(field.NewMDReqID("req-1"))
+	request.Body.Set(field.NewSubscriptionRequestType(enum.SubscriptionRequestType_SNAPSHOT))
+	request.Body.Set(field.NewMarketDepth(0))
+	request.Body.Set(field.NewAggregatedBook(true))
+
+	symbols := quickfix.NewRepeatingGroup(tag.NoRelatedSym, quickfix.GroupTemplate{quickfix.GroupElement(tag.Symbol)})
+	symbols.Add().Set(field.NewSymbol("EUR/USD"))
+	request.Body.SetGroup(symbols)
+
+	tests := []struct {
+		name    string
+		profile *ConformanceProfile
+		want    []string
+		wantErr error
+	}{
+		{
+			name: "no profile",
+		},
+		{
+			name: "conforming message",
+			profile: &ConformanceProfile{Messages: map[string]*ConformanceRules{
+				"V": {Required: []string{"MarketDepth", "Symbol"}, Forbidden: []string{"MDUpdateType"}},
+			}},
+			want: []string{},
+		},
+		{
+			name: "violations by message name",
+			profile: &ConformanceProfile{Messages: map[string]*ConformanceRules{
+				"MarketDataRequest": {Required: []string{"265", "SecurityGroup"}, Forbidden: []string{"AggregatedBook", "Symbol"}},
+			}},
+			want: []string{
+				"forbidden field 266(AggregatedBook) present",
+				"forbidden field 55(Symbol) present",
+				"required field 1151(SecurityGroup) missing",
+				"required field 265(MDUpdateType) missing",
+			},
+		},
+		{
+			name: "rules of other messages",
+			profile: &ConformanceProfile{Messages: map[string]*ConformanceRules{
+				"NewOrderSingle": {Required: []string{"Account"}},
+			}},
+		},
+		{
+			name: "unknown tag",
+			profile: &ConformanceProfile{Messages: map[string]*ConformanceRules{
+				"V": {Required: []string{"NotAField"}},
+			}},
+			wantErr: errors.FixDictionaryMismatch,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.profile.Check(request, transportDict, appDict)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Check() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Check() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Check() = %q, want %q", got, tt.want)
+			}
+
+			err = tt.profile.Validate(request, transportDict, appDict)
+			if len(tt.want) > 0 && !errors.Is(err, errors.FixConformanceViolation) {
+				t.Errorf("Validate() error = %v, want %v", err, errors.FixConformanceViolation)
+			} else if len(tt.want) == 0 && err != nil {
+				t.Errorf("Validate() error = %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/quickfixgo/quickfix"
+	"github.com/quickfixgo/quickfix/datadictionary"
+
+	"sylr.dev/fix/pkg/errors"
+)
+
+// GroupTemplate describes a venue specific repeating group which commands can
+// reference by name when building messages. Tags are given by number or by
+// field name.
+type GroupTemplate struct {
+	Name     string   `yaml:"name"`
+	CountTag string   `yaml:"countTag"`
+	Members  []string `yaml:"members"`
+}
+
+func (t *GroupTemplate) GetName() string {
+	return t.Name
+}
+
+func (t *GroupTemplate) validate() error {
+	if len(t.CountTag) == 0 {
+		return fmt.Errorf("%w: group template %s: no countTag", errors.Config, t.Name)
+	}
+	if len(t.Members) == 0 {
+		return fmt.Errorf("%w: group template %s: no members", errors.Config, t.Name)
+	}
+
+	return nil
+}
+
+// Resolve returns the count tag and the member tags of the group template. It
+// fails if any of them is not defined in the given dictionaries.
+func (t *GroupTemplate) Resolve(dicts ...*datadictionary.DataDictionary) (quickfix.Tag, []quickfix.Tag, error) {
+	countTag, err := resolveTemplateTag(t, t.CountTag, dicts)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	members := make([]quickfix.Tag, 0, len(t.Members))
+	for _, m := range t.Members {
+		member, err := resolveTemplateTag(t, m, dicts)
+		if err != nil {
+			return 0, nil, err
+		}
+		members = append(members, member)
+	}
+
+	return countTag, members, nil
+}
+
+func resolveTemplateTag(t *GroupTemplate, raw string, dicts []*datadictionary.DataDictionary) (quickfix.Tag, error) {
+	if tag, ok := lookupTag(raw, dicts); ok {
+		return tag, nil
+	}
+
+	return 0, fmt.Errorf("%w: group template %s: tag `%s` not defined in the dictionaries", errors.FixDictionaryMismatch, t.Name, raw)
+}
+
+// lookupTag returns the tag given by number or by field name if it is defined
+// in any of the dictionaries.
+func lookupTag(raw string, dicts []*datadictionary.DataDictionary) (quickfix.Tag, bool) {
+	n, err := strconv.Atoi(raw)
+
+	for _, d := range dicts {
+		if d == nil {
+			continue
+		}
+		if err == nil {
+			if _, ok := d.FieldTypeByTag[n]; ok {
+				return quickfix.Tag(n), true
+			}
+		} else if ft, ok := d.FieldTypeByName[raw]; ok {
+			return quickfix.Tag(ft.Tag()), true
+		}
+	}
+
+	return 0, false
+}
+
+// GetGroupTemplate returns the group template of the context with the given
+// name.
+func (c Context) GetGroupTemplate(name string) (*GroupTemplate, error) {
+	for _, t := range c.GroupTemplates {
+		if t.Name == name {
+			return t, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: %s", errors.ConfigGroupTemplateNotFound, name)
+}
@@ -13,3 +13,10 @@ func GetLogger() *zerolog.Logger {
 func SetLogger(l *zerolog.Logger) {
 	logger = l
 }
+
+// GetChildLogger returns a child of the global logger carrying the given
+// fields on every log line.
+func GetChildLogger(fields map[string]interface{}) *zerolog.Logger {
+	child := logger.With().Fields(fields).Logger()
+	return &child
+}
@@ -0,0 +1,102 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"time"
+)
+
+const jsonSchemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+var (
+	durationType = reflect.TypeOf(time.Duration(0))
+	hasNameType  = reflect.TypeOf((*HasName)(nil)).Elem()
+)
+
+// JSONSchema returns a JSON Schema describing the configuration file format.
+// It is derived from the configuration structs and their yaml tags.
+func JSONSchema() map[string]interface{} {
+	schema := typeSchema(reflect.TypeOf(fixConfig{}))
+	schema["$schema"] = jsonSchemaDraft
+	schema["title"] = "fix configuration"
+
+	return schema
+}
+
+func typeSchema(t reflect.Type) map[string]interface{} {
+	if t == durationType {
+		return map[string]interface{}{
+			"type":        "string",
+			"description": "Go duration (e.g. 5s, 1m30s)",
+		}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		return typeSchema(t.Elem())
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		return map[string]interface{}{
+			"type":  "array",
+			"items": typeSchema(t.Elem()),
+		}
+	case reflect.Map:
+		return map[string]interface{}{
+			"type":                 "object",
+			"additionalProperties": typeSchema(t.Elem()),
+		}
+	case reflect.Struct:
+		properties := make(map[string]interface{})
+		required := []string{}
+		structProperties(t, properties)
+
+		// Items identified by their name must have one
+		if reflect.PointerTo(t).Implements(hasNameType) {
+			required = append(required, "name")
+		}
+
+		schema := map[string]interface{}{
+			"type":                 "object",
+			"properties":           properties,
+			"additionalProperties": false,
+		}
+
+		if len(required) > 0 {
+			schema["required"] = required
+		}
+
+		return schema
+	default:
+		return map[string]interface{}{}
+	}
+}
+
+func structProperties(t reflect.Type, properties map[string]interface{}) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+
+		name, opts, _ := strings.Cut(f.Tag.Get("yaml"), ",")
+		if name == "-" {
+			continue
+		}
+
+		if strings.Contains(opts, "inline") {
+			structProperties(f.Type, properties)
+			continue
+		}
+
+		if !f.IsExported() || len(name) == 0 {
+			continue
+		}
+
+		properties[name] = typeSchema(f.Type)
+	}
+}
@@ -0,0 +1,84 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"sylr.dev/fix/pkg/errors"
+)
+
+// SecurityCache holds the symbols of the last SecurityList received on a
+// session, it is written by `fix list security` and read by the commands
+// taking symbols.
+type SecurityCache struct {
+	Context string    `json:"context"`
+	Session string    `json:"session"`
+	Updated time.Time `json:"updated"`
+	Symbols []string  `json:"symbols"`
+}
+
+// SecurityCachePath returns the path of the security cache of the session,
+// stored in the cache directory next to the configuration file.
+func (c Context) SecurityCachePath(session *Session) string {
+	name := fmt.Sprintf("securities-%s-%s.json", c.Name, session.Name)
+	name = strings.NewReplacer("/", "_", string(os.PathSeparator), "_").Replace(name)
+
+	return filepath.Join(filepath.Dir(os.ExpandEnv(options.Config)), "cache", name)
+}
+
+// ReadSecurityCache reads the security cache at path, it returns nil if the
+// cache does not exist.
+func ReadSecurityCache(path string) (*SecurityCache, error) {
+	file, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("%w: unable to read security cache: %s", errors.Config, err)
+	}
+
+	cache := SecurityCache{}
+	if err := json.Unmarshal(file, &cache); err != nil {
+		return nil, fmt.Errorf("%w: security cache %s: %s", errors.Config, path, err)
+	}
+
+	return &cache, nil
+}
+
+// Write writes the cache to path, creating its directory if needed.
+func (s *SecurityCache) Write(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("%w: unable to create security cache directory: %s", errors.Config, err)
+	}
+
+	b, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(path, append(b, '\n'), 0o644); err != nil {
+		return fmt.Errorf("%w: unable to write security cache: %s", errors.Config, err)
+	}
+
+	return nil
+}
+
+// Unknown returns the symbols which are not in the cache.
+func (s *SecurityCache) Unknown(symbols []string) []string {
+	known := make(map[string]struct{}, len(s.Symbols))
+	for _, sym := range s.Symbols {
+		known[sym] = struct{}{}
+	}
+
+	unknown := []string{}
+	for _, sym := range symbols {
+		if _, ok := known[sym]; !ok {
+			unknown = append(unknown, sym)
+		}
+	}
+
+	return unknown
+}
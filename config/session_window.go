@@ -0,0 +1,135 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"sylr.dev/fix/pkg/errors"
+)
+
+// sessionWindow is the daily or weekly time window of a session as configured
+// with StartTime, EndTime, StartDay, EndDay and TimeZone.
+type sessionWindow struct {
+	start, end       time.Duration
+	startDay, endDay time.Weekday
+	weekly           bool
+	location         *time.Location
+}
+
+// HasWindow tells whether the session is only available within a time window.
+func (s *Session) HasWindow() bool {
+	return len(s.StartTime) > 0 && len(s.EndTime) > 0
+}
+
+func (s *Session) window() (*sessionWindow, error) {
+	if !s.HasWindow() {
+		return nil, fmt.Errorf("%w: session %s: StartTime and EndTime are required", errors.Config, s.Name)
+	}
+
+	w := sessionWindow{location: time.UTC}
+
+	var err error
+	if w.start, err = parseTimeOfDay(s.StartTime); err != nil {
+		return nil, fmt.Errorf("%w: session %s: invalid StartTime: %s", errors.Config, s.Name, err)
+	}
+	if w.end, err = parseTimeOfDay(s.EndTime); err != nil {
+		return nil, fmt.Errorf("%w: session %s: invalid EndTime: %s", errors.Config, s.Name, err)
+	}
+
+	if len(s.StartDay) > 0 || len(s.EndDay) > 0 {
+		w.weekly = true
+		if w.startDay, err = parseWeekday(s.StartDay); err != nil {
+			return nil, fmt.Errorf("%w: session %s: invalid StartDay: %s", errors.Config, s.Name, err)
+		}
+		if w.endDay, err = parseWeekday(s.EndDay); err != nil {
+			return nil, fmt.Errorf("%w: session %s: invalid EndDay: %s", errors.Config, s.Name, err)
+		}
+	}
+
+	if len(s.TimeZone) > 0 {
+		if w.location, err = time.LoadLocation(s.TimeZone); err != nil {
+			return nil, fmt.Errorf("%w: session %s: invalid TimeZone: %s", errors.Config, s.Name, err)
+		}
+	}
+
+	return &w, nil
+}
+
+// InWindow tells whether t is within the time window of the session.
+func (s *Session) InWindow(t time.Time) (bool, error) {
+	w, err := s.window()
+	if err != nil {
+		return false, err
+	}
+
+	t = t.In(w.location)
+	day := 24 * time.Hour
+	now := sinceMidnight(t)
+	start, end := w.start, w.end
+
+	if w.weekly {
+		now += time.Duration(t.Weekday()) * day
+		start += time.Duration(w.startDay) * day
+		end += time.Duration(w.endDay) * day
+	}
+
+	if start <= end {
+		return start <= now && now <= end, nil
+	}
+
+	return now >= start || now <= end, nil
+}
+
+// NextWindowStart returns the next time after t the time window of the session
+// opens.
+func (s *Session) NextWindowStart(t time.Time) (time.Time, error) {
+	w, err := s.window()
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	t = t.In(w.location)
+	hour, minute, second := int(w.start/time.Hour), int(w.start%time.Hour/time.Minute), int(w.start%time.Minute/time.Second)
+
+	for i := 0; i <= 7; i++ {
+		start := time.Date(t.Year(), t.Month(), t.Day()+i, hour, minute, second, 0, w.location)
+		if w.weekly && start.Weekday() != w.startDay {
+			continue
+		}
+
+		if start.After(t) {
+			return start, nil
+		}
+	}
+
+	// Unreachable as the start day occurs within a week
+	return time.Time{}, fmt.Errorf("%w: session %s: unable to compute next window start", errors.Config, s.Name)
+}
+
+func sinceMidnight(t time.Time) time.Duration {
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute + time.Duration(t.Second())*time.Second
+}
+
+func parseTimeOfDay(s string) (time.Duration, error) {
+	t, err := time.Parse("15:04:05", s)
+	if err != nil {
+		return 0, err
+	}
+
+	return sinceMidnight(t), nil
+}
+
+func parseWeekday(s string) (time.Weekday, error) {
+	if len(s) < 2 {
+		return 0, fmt.Errorf("unknown day `%s`", s)
+	}
+
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		if strings.HasPrefix(strings.ToLower(d.String()), strings.ToLower(s)) {
+			return d, nil
+		}
+	}
+
+	return 0, fmt.Errorf("unknown day `%s`", s)
+}
@@ -0,0 +1,134 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	qconfig "github.com/quickfixgo/quickfix/config"
+
+	"sylr.dev/fix/pkg/errors"
+)
+
+var (
+	tlsDataDir   string
+	tlsDataFiles = make(map[string]string)
+	tlsDataMux   sync.Mutex
+)
+
+// tlsCredential is a TLS setting given either as a file or inline as base64
+// encoded PEM data.
+type tlsCredential struct {
+	setting string
+	file    string
+	data    string
+}
+
+func (c *common) tlsCredentials() []tlsCredential {
+	return []tlsCredential{
+		{qconfig.SocketPrivateKeyFile, c.SocketPrivateKeyFile, c.SocketPrivateKeyData},
+		{qconfig.SocketCertificateFile, c.SocketCertificateFile, c.SocketCertificateData},
+		{qconfig.SocketCAFile, c.SocketCAFile, c.SocketCAData},
+	}
+}
+
+// validateTLS checks that TLS settings are given either as files or inline,
+// inline data being valid base64 encoded PEM.
+func (c *common) validateTLS() error {
+	for _, cred := range c.tlsCredentials() {
+		data := strings.TrimSuffix(cred.setting, "File") + "Data"
+
+		if len(cred.file) > 0 && len(cred.data) > 0 {
+			return fmt.Errorf("%w: %s: %s and %s are mutually exclusive", errors.Config, c.Name, cred.setting, data)
+		}
+
+		if len(cred.data) > 0 {
+			if _, err := decodePEMData(cred.data); err != nil {
+				return fmt.Errorf("%w: %s: %s: %s", errors.Config, c.Name, data, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// tlsFile returns the file to give quickfix for the credential. quickfix only
+// reads TLS material from files so inline data is written to a private
+// temporary file, removed by RemoveTLSDataFiles.
+func (cred tlsCredential) tlsFile() (string, error) {
+	if len(cred.data) == 0 {
+		return cred.file, nil
+	}
+
+	pemBytes, err := decodePEMData(cred.data)
+	if err != nil {
+		return "", fmt.Errorf("%w: %s: %s", errors.Config, cred.setting, err)
+	}
+
+	return writeTLSDataFile(pemBytes)
+}
+
+// decodePEMData decodes base64 encoded PEM data, environment variables being
+// expanded first.
+func decodePEMData(data string) ([]byte, error) {
+	data = strings.Join(strings.Fields(os.ExpandEnv(data)), "")
+
+	pemBytes, err := base64.StdEncoding.DecodeString(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid base64: %s", err)
+	}
+
+	if block, _ := pem.Decode(pemBytes); block == nil {
+		return nil, fmt.Errorf("no PEM block found")
+	}
+
+	return pemBytes, nil
+}
+
+func writeTLSDataFile(pemBytes []byte) (string, error) {
+	tlsDataMux.Lock()
+	defer tlsDataMux.Unlock()
+
+	sum := sha256.Sum256(pemBytes)
+	key := hex.EncodeToString(sum[:])
+	if path, ok := tlsDataFiles[key]; ok {
+		return path, nil
+	}
+
+	if len(tlsDataDir) == 0 {
+		dir, err := os.MkdirTemp("", "fix-tls-")
+		if err != nil {
+			return "", fmt.Errorf("%w: unable to create TLS data directory: %s", errors.Config, err)
+		}
+		tlsDataDir = dir
+	}
+
+	path := filepath.Join(tlsDataDir, key+".pem")
+	if err := os.WriteFile(path, pemBytes, 0o600); err != nil {
+		return "", fmt.Errorf("%w: unable to write TLS data: %s", errors.Config, err)
+	}
+
+	tlsDataFiles[key] = path
+
+	return path, nil
+}
+
+// RemoveTLSDataFiles removes the temporary files holding the inline TLS
+// material, it is to be called once the sessions are stopped.
+func RemoveTLSDataFiles() {
+	tlsDataMux.Lock()
+	defer tlsDataMux.Unlock()
+
+	if len(tlsDataDir) > 0 {
+		os.RemoveAll(tlsDataDir)
+	}
+
+	tlsDataDir = ""
+	tlsDataFiles = make(map[string]string)
+}
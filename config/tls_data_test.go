@@ -0,0 +1,122 @@
+package config
+
+import (
+	"encoding/base64"
+	"encoding/pem"
+	"os"
+	"testing"
+
+	qconfig "github.com/quickfixgo/quickfix/config"
+
+	"sylr.dev/fix/pkg/errors"
+)
+
+func TestValidateTLS(t *testing.T) {
+	pemBytes := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: []byte("not a real certificate")})
+	data := base64.StdEncoding.EncodeToString(pemBytes)
+
+	t.Setenv("FIX_TEST_CA", data)
+
+	tests := []struct {
+		name    string
+		common  common
+		wantErr bool
+	}{
+		{
+			name: "none",
+		},
+		{
+			name:   "file",
+			common: common{SocketCAFile: "/etc/ssl/ca.pem"},
+		},
+		{
+			name:   "inline",
+			common: common{SocketCAData: data},
+		},
+		{
+			name:   "inline wrapped",
+			common: common{SocketCAData: data[:20] + "\n  " + data[20:]},
+		},
+		{
+			name:   "inline from the environment",
+			common: common{SocketCAData: "${FIX_TEST_CA}"},
+		},
+		{
+			name:    "file and inline",
+			common:  common{SocketCertificateFile: "/etc/ssl/cert.pem", SocketCertificateData: data},
+			wantErr: true,
+		},
+		{
+			name:    "invalid base64",
+			common:  common{SocketPrivateKeyData: "not base64!"},
+			wantErr: true,
+		},
+		{
+			name:    "not PEM",
+			common:  common{SocketPrivateKeyData: base64.StdEncoding.EncodeToString([]byte("plain text"))},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.common.Name = "initiator"
+
+			err := tt.common.validateTLS()
+			if tt.wantErr {
+				if !errors.Is(err, errors.Config) {
+					t.Errorf("validateTLS() error = %v, want %v", err, errors.Config)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("validateTLS() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestTLSDataFiles(t *testing.T) {
+	t.Cleanup(RemoveTLSDataFiles)
+
+	pemBytes := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: []byte("not a real certificate")})
+
+	initiator := &Initiator{SocketConnectHost: "127.0.0.1", SocketConnectPort: 5001}
+	initiator.SocketUseSSL = true
+	initiator.SocketCAData = base64.StdEncoding.EncodeToString(pemBytes)
+	initiator.SocketCertificateFile = "/etc/ssl/cert.pem"
+
+	settings := initiatorSettings(t, initiator, newTestSession(), cliOptions{})
+	checkSetting(t, settings, qconfig.SocketCertificateFile, "/etc/ssl/cert.pem")
+	checkSetting(t, settings, qconfig.SocketPrivateKeyFile, "")
+
+	path, err := settings.Setting(qconfig.SocketCAFile)
+	if err != nil {
+		t.Fatalf("%s: %s", qconfig.SocketCAFile, err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0o600 {
+		t.Errorf("%s mode = %o, want 600", path, perm)
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != string(pemBytes) {
+		t.Errorf("%s = %q, want %q", path, b, pemBytes)
+	}
+
+	// The same data is written once
+	again := initiatorSettings(t, initiator, newTestSession(), cliOptions{})
+	checkSetting(t, again, qconfig.SocketCAFile, path)
+
+	RemoveTLSDataFiles()
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("%s not removed: %v", path, err)
+	}
+}
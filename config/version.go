@@ -0,0 +1,15 @@
+package config
+
+var (
+	version = "dev"
+)
+
+// GetVersion returns the version of the fix tool.
+func GetVersion() string {
+	return version
+}
+
+// SetVersion sets the version of the fix tool.
+func SetVersion(v string) {
+	version = v
+}
@@ -0,0 +1,72 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/quickfixgo/enum"
+	"github.com/quickfixgo/quickfix"
+
+	"sylr.dev/fix/pkg/dict"
+	"sylr.dev/fix/pkg/errors"
+	"sylr.dev/fix/pkg/utils"
+)
+
+// ValidateVersions checks that the transport version (BeginString) and the
+// application version (DefaultApplVerID) form a supported combination.
+//
+// FIXT.1.1 requires a FIX.5.0 or later DefaultApplVerID while FIX.4.x sessions
+// carry the application version in their BeginString and accept no other one.
+func ValidateVersions(beginString string, applVerID string) error {
+	if utils.Search(dict.BeginStrings, beginString) < 0 {
+		return fmt.Errorf("%w: unknown BeginString `%s`", errors.ConfigUnsupportedVersions, beginString)
+	}
+
+	if len(applVerID) == 0 {
+		if beginString == quickfix.BeginStringFIXT11 {
+			return fmt.Errorf("%w: %s requires a DefaultApplVerID", errors.ConfigUnsupportedVersions, beginString)
+		}
+		return nil
+	}
+
+	id, ok := normalizeApplVerID(applVerID)
+	if !ok {
+		return fmt.Errorf("%w: unknown DefaultApplVerID `%s`", errors.ConfigUnsupportedVersions, applVerID)
+	}
+
+	if beginString != quickfix.BeginStringFIXT11 && id != dict.ApplVerIDs[beginString] {
+		return fmt.Errorf("%w: %s can't be used with DefaultApplVerID %s", errors.ConfigUnsupportedVersions, beginString, applVerID)
+	}
+
+	if beginString == quickfix.BeginStringFIXT11 && utils.Search(preFIXTApplVerIDs, id) >= 0 {
+		return fmt.Errorf("%w: %s requires a FIX.5.0 or later DefaultApplVerID, %s sessions use their own BeginString", errors.ConfigUnsupportedVersions, beginString, applVerID)
+	}
+
+	return nil
+}
+
+// preFIXTApplVerIDs are the application versions predating FIXT.1.1 which are
+// carried by the BeginString of their own transport.
+var preFIXTApplVerIDs = []enum.ApplVerID{
+	enum.ApplVerID_FIX27,
+	enum.ApplVerID_FIX30,
+	enum.ApplVerID_FIX40,
+	enum.ApplVerID_FIX41,
+	enum.ApplVerID_FIX42,
+	enum.ApplVerID_FIX43,
+	enum.ApplVerID_FIX44,
+}
+
+// normalizeApplVerID returns the ApplVerID enum value of the given version
+// which can either be given by name (e.g. FIX.5.0SP2) or by value (e.g. 9).
+func normalizeApplVerID(applVerID string) (enum.ApplVerID, bool) {
+	if id, ok := dict.ApplVerIDs[strings.ToUpper(applVerID)]; ok {
+		return id, true
+	}
+
+	if _, err := dict.SearchValue(dict.ApplVerIDs, enum.ApplVerID(applVerID)); err == nil {
+		return enum.ApplVerID(applVerID), true
+	}
+
+	return "", false
+}
@@ -0,0 +1,62 @@
+package config
+
+import (
+	"testing"
+
+	"sylr.dev/fix/pkg/errors"
+)
+
+func TestValidateVersions(t *testing.T) {
+	tests := []struct {
+		beginString string
+		applVerID   string
+		wantErr     bool
+	}{
+		{beginString: "FIXT.1.1", applVerID: "FIX.5.0SP2"},
+		{beginString: "FIXT.1.1", applVerID: "fix.5.0sp1"},
+		{beginString: "FIXT.1.1", applVerID: "9"},
+		{beginString: "FIXT.1.1", applVerID: "FIXLATEST"},
+		{beginString: "FIX.4.4"},
+		{beginString: "FIX.4.4", applVerID: "FIX.4.4"},
+		{beginString: "FIX.4.4", applVerID: "6"},
+		{beginString: "FIX.4.2", applVerID: "FIX.4.2"},
+		{beginString: "FIXT.1.1", wantErr: true},
+		{beginString: "FIXT.1.1", applVerID: "FIX.4.4", wantErr: true},
+		{beginString: "FIXT.1.1", applVerID: "6", wantErr: true},
+		{beginString: "FIXT.1.1", applVerID: "FIX.6.0", wantErr: true},
+		{beginString: "FIX.4.2", applVerID: "FIX.4.4", wantErr: true},
+		{beginString: "FIX.4.4", applVerID: "FIX.5.0SP2", wantErr: true},
+		{beginString: "FIX.5.0", applVerID: "FIX.5.0", wantErr: true},
+		{beginString: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.beginString+"/"+tt.applVerID, func(t *testing.T) {
+			err := ValidateVersions(tt.beginString, tt.applVerID)
+			if tt.wantErr {
+				if !errors.Is(err, errors.ConfigUnsupportedVersions) {
+					t.Errorf("ValidateVersions() error = %v, want %v", err, errors.ConfigUnsupportedVersions)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("ValidateVersions() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestValidateSessionVersions(t *testing.T) {
+	session := newTestSession()
+	session.DefaultApplVerID = "FIX.4.4"
+
+	cfg := fixConfig{Sessions: []*Session{session}}
+	if err := cfg.Validate(); !errors.Is(err, errors.ConfigUnsupportedVersions) {
+		t.Errorf("Validate() error = %v, want %v", err, errors.ConfigUnsupportedVersions)
+	}
+
+	session.DefaultApplVerID = "FIX.5.0SP2"
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+}
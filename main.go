@@ -7,11 +7,17 @@ import (
 	_ "github.com/mattn/go-sqlite3"
 
 	"sylr.dev/fix/cmd"
+	"sylr.dev/fix/config"
 )
 
 func main() {
 	err := cmd.FixCmd.Execute()
 
+	// Cobra skips post run hooks when the command fails
+	cmd.StopPProf()
+	cmd.StopTracing(err)
+	config.RemoveTLSDataFiles()
+
 	if err != nil {
 		os.Exit(1)
 	}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/quickfixgo/quickfix"
 
+	"sylr.dev/fix/config"
 	"sylr.dev/fix/pkg/utils"
 )
 
@@ -27,5 +28,10 @@ func NewAcceptor(app quickfix.Application, settings *quickfix.Settings, logger *
 		msgStoreFactory = quickfix.NewMemoryStoreFactory()
 	}
 
-	return quickfix.NewAcceptor(app, msgStoreFactory, settings, utils.NewQuickFixLogFactory(logger))
+	logFactory := utils.NewQuickFixLogFactory(logger)
+	if config.GetOptions().ShowFraming {
+		logFactory = utils.NewQuickFixFramingLogFactory(logFactory, config.GetLogger())
+	}
+
+	return quickfix.NewAcceptor(app, msgStoreFactory, settings, logFactory)
 }
@@ -0,0 +1,177 @@
+package application
+
+import (
+	"math/rand"
+
+	"github.com/rs/zerolog"
+	"github.com/shopspring/decimal"
+
+	"github.com/quickfixgo/enum"
+	"github.com/quickfixgo/field"
+	"github.com/quickfixgo/quickfix"
+	"github.com/quickfixgo/tag"
+
+	"sylr.dev/fix/pkg/utils"
+)
+
+type MarketDataAcceptorOptions struct {
+	// Price is the mid price of the synthetic book.
+	Price float64
+	// Spread is the difference between the offer and the bid.
+	Spread float64
+	// Size is the size of every entry.
+	Size float64
+	// Jitter is the maximum random variation applied to Price.
+	Jitter float64
+}
+
+func NewMarketDataAcceptor(options *MarketDataAcceptorOptions) *MarketDataAcceptor {
+	s := MarketDataAcceptor{
+		options: *options,
+		router:  quickfix.NewMessageRouter(),
+	}
+
+	s.router.AddRoute(quickfix.ApplVerIDFIX50SP2, string(enum.MsgType_MARKET_DATA_REQUEST), s.onMarketDataRequest)
+
+	return &s
+}
+
+// MarketDataAcceptor answers MarketDataRequests with synthetic
+// MarketDataSnapshotFullRefresh messages, one per requested symbol.
+type MarketDataAcceptor struct {
+	utils.QuickFixAppMessageLogger
+
+	options MarketDataAcceptorOptions
+	router  *quickfix.MessageRouter
+}
+
+var _ quickfix.Application = (*MarketDataAcceptor)(nil)
+
+// Notification of a session begin created.
+func (app *MarketDataAcceptor) OnCreate(sessionID quickfix.SessionID) {
+	app.Logger.Debug().Msgf("New session: %s", sessionID)
+}
+
+// Notification of a session successfully logging on.
+func (app *MarketDataAcceptor) OnLogon(sessionID quickfix.SessionID) {
+	app.Logger.Info().Msgf("Logon: %s", sessionID)
+}
+
+// Notification of a session logging off or disconnecting.
+func (app *MarketDataAcceptor) OnLogout(sessionID quickfix.SessionID) {
+	app.Logger.Info().Msgf("Logout: %s", sessionID)
+}
+
+// Notification of admin message being sent to target.
+func (app *MarketDataAcceptor) ToAdmin(message *quickfix.Message, sessionID quickfix.SessionID) {
+	app.LogMessage(zerolog.TraceLevel, message, sessionID, true)
+}
+
+// Notification of admin message being received from target.
+func (app *MarketDataAcceptor) FromAdmin(message *quickfix.Message, sessionID quickfix.SessionID) quickfix.MessageRejectError {
+	app.LogMessage(zerolog.TraceLevel, message, sessionID, false)
+
+	return nil
+}
+
+// Notification of app message being sent to target.
+func (app *MarketDataAcceptor) ToApp(message *quickfix.Message, sessionID quickfix.SessionID) error {
+	app.LogMessage(zerolog.TraceLevel, message, sessionID, true)
+
+	return nil
+}
+
+// Notification of app message being received from target.
+func (app *MarketDataAcceptor) FromApp(message *quickfix.Message, sessionID quickfix.SessionID) quickfix.MessageRejectError {
+	app.LogMessage(zerolog.TraceLevel, message, sessionID, false)
+
+	return app.router.Route(message, sessionID)
+}
+
+func (app *MarketDataAcceptor) onMarketDataRequest(request *quickfix.Message, sessionID quickfix.SessionID) quickfix.MessageRejectError {
+	mdReqID, ferr := request.Body.GetString(tag.MDReqID)
+	if ferr != nil {
+		return ferr
+	}
+
+	types := quickfix.NewRepeatingGroup(tag.NoMDEntryTypes, quickfix.GroupTemplate{quickfix.GroupElement(tag.MDEntryType)})
+	if ferr := request.Body.GetGroup(types); ferr != nil {
+		return ferr
+	}
+
+	symbols := quickfix.NewRepeatingGroup(tag.NoRelatedSym, relatedSymTemplate)
+	if ferr := request.Body.GetGroup(symbols); ferr != nil {
+		return ferr
+	}
+
+	app.Logger.Info().Msgf("MarketDataRequest %s received for %d instrument(s)", mdReqID, symbols.Len())
+
+	for i := 0; i < symbols.Len(); i++ {
+		if err := app.sendSnapshot(sessionID, mdReqID, symbols.Get(i), types); err != nil {
+			return quickfix.NewMessageRejectError(err.Error(), int(tag.ApplResponseError), nil)
+		}
+	}
+
+	return nil
+}
+
+// relatedSymTemplate is the layout of the NoRelatedSym entries sent by `fix
+// marketdata request`, which subscribes to symbols, security groups or market
+// segments.
+var relatedSymTemplate = quickfix.GroupTemplate{
+	quickfix.GroupElement(tag.Symbol),
+	quickfix.GroupElement(tag.SecurityGroup),
+	quickfix.GroupElement(tag.MarketSegmentID),
+}
+
+// sendSnapshot answers the NoRelatedSym entry instrument, the snapshot
+// carrying the fields identifying it.
+func (app *MarketDataAcceptor) sendSnapshot(sessionID quickfix.SessionID, mdReqID string, instrument *quickfix.Group, types *quickfix.RepeatingGroup) error {
+	message := quickfix.NewMessage()
+	message.Header.Set(field.NewMsgType(enum.MsgType_MARKET_DATA_SNAPSHOT_FULL_REFRESH))
+	message.Body.Set(field.NewMDReqID(mdReqID))
+
+	for _, t := range relatedSymTemplate {
+		if v, err := instrument.GetString(t.Tag()); err == nil {
+			message.Body.SetString(t.Tag(), v)
+		}
+	}
+
+	mid := app.options.Price
+	if app.options.Jitter > 0 {
+		mid += (rand.Float64()*2 - 1) * app.options.Jitter
+	}
+
+	entries := quickfix.NewRepeatingGroup(
+		tag.NoMDEntries,
+		quickfix.GroupTemplate{
+			quickfix.GroupElement(tag.MDEntryType),
+			quickfix.GroupElement(tag.MDEntryPx),
+			quickfix.GroupElement(tag.MDEntrySize),
+		},
+	)
+
+	for i := 0; i < types.Len(); i++ {
+		typ, ferr := types.Get(i).GetString(tag.MDEntryType)
+		if ferr != nil {
+			return ferr
+		}
+
+		price := mid
+		switch enum.MDEntryType(typ) {
+		case enum.MDEntryType_BID:
+			price -= app.options.Spread / 2
+		case enum.MDEntryType_OFFER:
+			price += app.options.Spread / 2
+		}
+
+		entry := entries.Add()
+		entry.Set(field.NewMDEntryType(enum.MDEntryType(typ)))
+		entry.Set(field.NewMDEntryPx(decimal.NewFromFloat(price).Round(4), 4))
+		entry.Set(field.NewMDEntrySize(decimal.NewFromFloat(app.options.Size), 4))
+	}
+
+	message.Body.SetGroup(entries)
+
+	return quickfix.SendToTarget(message, sessionID)
+}
@@ -0,0 +1,214 @@
+package application
+
+import (
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/quickfixgo/enum"
+	"github.com/quickfixgo/field"
+	"github.com/quickfixgo/quickfix"
+	"github.com/quickfixgo/quickfix/config"
+	"github.com/quickfixgo/tag"
+
+	"sylr.dev/fix/pkg/testutils"
+)
+
+// marketDataClient sends a MarketDataRequest once logged on and hands over the
+// snapshots it receives.
+type marketDataClient struct {
+	request   *quickfix.Message
+	snapshots chan *quickfix.Message
+}
+
+func (c *marketDataClient) OnCreate(quickfix.SessionID) {}
+
+func (c *marketDataClient) OnLogout(quickfix.SessionID) {}
+
+func (c *marketDataClient) ToAdmin(*quickfix.Message, quickfix.SessionID) {}
+
+func (c *marketDataClient) ToApp(*quickfix.Message, quickfix.SessionID) error {
+	return nil
+}
+
+func (c *marketDataClient) FromAdmin(*quickfix.Message, quickfix.SessionID) quickfix.MessageRejectError {
+	return nil
+}
+
+func (c *marketDataClient) OnLogon(sessionID quickfix.SessionID) {
+	quickfix.SendToTarget(c.request, sessionID)
+}
+
+func (c *marketDataClient) FromApp(message *quickfix.Message, sessionID quickfix.SessionID) quickfix.MessageRejectError {
+	if msgType, err := message.MsgType(); err == nil && msgType == string(enum.MsgType_MARKET_DATA_SNAPSHOT_FULL_REFRESH) {
+		c.snapshots <- message
+	}
+
+	return nil
+}
+
+// freePort returns a TCP port of the loopback interface nobody listens to.
+func freePort(t *testing.T) int {
+	t.Helper()
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	return l.Addr().(*net.TCPAddr).Port
+}
+
+func sessionSettings(t *testing.T, sender, target string, extra map[string]string) *quickfix.Settings {
+	t.Helper()
+
+	session := quickfix.NewSessionSettings()
+	session.Set(config.BeginString, quickfix.BeginStringFIXT11)
+	session.Set(config.DefaultApplVerID, "FIX.5.0SP2")
+	session.Set(config.SenderCompID, sender)
+	session.Set(config.TargetCompID, target)
+	session.Set(config.HeartBtInt, "30")
+	session.Set(config.ResetOnLogon, "Y")
+	session.Set(config.TransportDataDictionary, testutils.DictionaryFile(t, testutils.TransportDictionary))
+	session.Set(config.AppDataDictionary, testutils.DictionaryFile(t, testutils.AppDictionary))
+	for k, v := range extra {
+		session.Set(k, v)
+	}
+
+	settings := quickfix.NewSettings()
+	if _, err := settings.AddSession(session); err != nil {
+		t.Fatal(err)
+	}
+
+	return settings
+}
+
+func TestMarketDataAcceptor(t *testing.T) {
+	transportDict, appDict := testutils.Dictionaries(t)
+	logger := zerolog.Nop()
+	port := strconv.Itoa(freePort(t))
+
+	app := NewMarketDataAcceptor(&MarketDataAcceptorOptions{
+		Price:  1.1,
+		Spread: 0.0002,
+		Size:   1000000,
+	})
+	app.TransportDataDictionary = transportDict
+	app.AppDataDictionary = appDict
+	app.Logger = &logger
+
+	acceptor, err := quickfix.NewAcceptor(
+		app,
+		quickfix.NewMemoryStoreFactory(),
+		sessionSettings(t, "VENUE", "CLIENT", map[string]string{
+			config.SocketAcceptHost: "127.0.0.1",
+			config.SocketAcceptPort: port,
+		}),
+		quickfix.NewNullLogFactory(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := acceptor.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer acceptor.Stop()
+
+	// One entry per way of subscribing: by symbol, by security group and by
+	// both, which must all be answered with the fields identifying them.
+	instruments := []map[quickfix.Tag]string{
+		{tag.Symbol: "EUR/USD"},
+		{tag.SecurityGroup: "FX"},
+		{tag.Symbol: "GBP/USD", tag.SecurityGroup: "FX"},
+	}
+
+	request := quickfix.NewMessage()
+	request.Header.Set(field.NewMsgType(enum.MsgType_MARKET_DATA_REQUEST))
+	request.Body.Set(field.NewMDReqID("req-1"))
+	request.Body.Set(field.NewSubscriptionRequestType(enum.SubscriptionRequestType_SNAPSHOT))
+	request.Body.Set(field.NewMarketDepth(0))
+
+	types := quickfix.NewRepeatingGroup(tag.NoMDEntryTypes, quickfix.GroupTemplate{quickfix.GroupElement(tag.MDEntryType)})
+	for _, typ := range []enum.MDEntryType{enum.MDEntryType_BID, enum.MDEntryType_OFFER} {
+		types.Add().Set(field.NewMDEntryType(typ))
+	}
+	request.Body.SetGroup(types)
+
+	related := quickfix.NewRepeatingGroup(tag.NoRelatedSym, relatedSymTemplate)
+	for _, instrument := range instruments {
+		entry := related.Add()
+		for k, v := range instrument {
+			entry.SetString(k, v)
+		}
+	}
+	request.Body.SetGroup(related)
+
+	client := &marketDataClient{
+		request:   request,
+		snapshots: make(chan *quickfix.Message, len(instruments)),
+	}
+
+	initiator, err := quickfix.NewInitiator(
+		client,
+		quickfix.NewMemoryStoreFactory(),
+		sessionSettings(t, "CLIENT", "VENUE", map[string]string{
+			config.SocketConnectHost: "127.0.0.1",
+			config.SocketConnectPort: port,
+			config.ReconnectInterval: "1",
+		}),
+		quickfix.NewNullLogFactory(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := initiator.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer initiator.Stop()
+
+	timeout := time.After(10 * time.Second)
+	for i, instrument := range instruments {
+		var snapshot *quickfix.Message
+		select {
+		case snapshot = <-client.snapshots:
+		case <-timeout:
+			t.Fatalf("received %d snapshot(s), want %d", i, len(instruments))
+		}
+
+		if got, _ := snapshot.Body.GetString(tag.MDReqID); got != "req-1" {
+			t.Errorf("snapshot %d: MDReqID = %q, want %q", i, got, "req-1")
+		}
+
+		for _, item := range relatedSymTemplate {
+			got, _ := snapshot.Body.GetString(item.Tag())
+			if want := instrument[item.Tag()]; got != want {
+				t.Errorf("snapshot %d: tag %d = %q, want %q", i, item.Tag(), got, want)
+			}
+		}
+
+		entries := quickfix.NewRepeatingGroup(
+			tag.NoMDEntries,
+			quickfix.GroupTemplate{
+				quickfix.GroupElement(tag.MDEntryType),
+				quickfix.GroupElement(tag.MDEntryPx),
+				quickfix.GroupElement(tag.MDEntrySize),
+			},
+		)
+		if err := snapshot.Body.GetGroup(entries); err != nil {
+			t.Fatalf("snapshot %d: %s", i, err)
+		}
+		if entries.Len() != 2 {
+			t.Fatalf("snapshot %d: NoMDEntries = %d, want 2", i, entries.Len())
+		}
+
+		bid, _ := entries.Get(0).GetString(tag.MDEntryPx)
+		offer, _ := entries.Get(1).GetString(tag.MDEntryPx)
+		if bid != "1.0999" || offer != "1.1001" {
+			t.Errorf("snapshot %d: bid/offer = %s/%s, want 1.0999/1.1001", i, bid, offer)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package acceptor
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/spf13/cobra"
 
@@ -29,6 +30,11 @@ func ValidateOptions(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	err = conf.ExpandCompIDs(time.Now())
+	if err != nil {
+		return err
+	}
+
 	// Retrieve the global config pointer
 	fixConfig := config.GetConfig()
 
@@ -89,6 +95,7 @@ func AddPersistentFlags(cmd *cobra.Command) {
 	cmd.PersistentFlags().StringVar(&options.Session, "session", "", "Session to use (can't be used with --context)")
 	cmd.PersistentFlags().DurationVar(&options.Timeout, "timeout", 0, "Duration for timeouts")
 	cmd.PersistentFlags().BoolVar(&options.QuickFixLogging, "quickfix-logging", false, "Enable quickfix logging")
+	cmd.PersistentFlags().BoolVar(&options.ShowFraming, "show-framing", false, "Log the BodyLength and CheckSum of sent and received messages and whether they are valid")
 }
 
 func AddPersistentFlagCompletions(cmd *cobra.Command) {
@@ -0,0 +1,11 @@
+package complete
+
+import (
+	"github.com/google/uuid"
+	"github.com/spf13/cobra"
+)
+
+// FreshUUID suggests a newly generated UUID.
+func FreshUUID(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return []string{uuid.New().String()}, cobra.ShellCompDirectiveNoFileComp
+}
@@ -1,20 +1,56 @@
 package complete
 
 import (
+	"strings"
+
 	"github.com/spf13/cobra"
 
 	"sylr.dev/fix/pkg/dict"
+	"sylr.dev/fix/pkg/initiator/application"
 	"sylr.dev/fix/pkg/utils"
 )
 
+// MDEntryTypes completes the type after the last comma of toComplete, leaving
+// out the types already given before it.
 func MDEntryTypes(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
-	return utils.PrettyOptionValues(dict.MDEntryTypes), cobra.ShellCompDirectiveNoFileComp
+	i := strings.LastIndexByte(toComplete, ',')
+	if i < 0 {
+		return utils.PrettyOptionValues(dict.MDEntryTypes), cobra.ShellCompDirectiveNoFileComp
+	}
+
+	prefix := toComplete[:i+1]
+	given := []string{}
+	for _, t := range strings.Split(toComplete[:i], ",") {
+		t, _, _ = strings.Cut(t, ":")
+		given = append(given, strings.ToLower(t))
+	}
+
+	values := []string{}
+	for _, v := range utils.PrettyOptionValues(dict.MDEntryTypes) {
+		if utils.Search(given, v) < 0 {
+			values = append(values, prefix+v)
+		}
+	}
+
+	return values, cobra.ShellCompDirectiveNoFileComp
 }
 
 func SubscriptionRequestTypes(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
-	return utils.PrettyOptionValues(dict.SubscriptionRequestTypes), cobra.ShellCompDirectiveNoFileComp
+	return subscriptionRequestTypes(), cobra.ShellCompDirectiveNoFileComp
 }
 
 func MDUpdateTypes(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
-	return utils.PrettyOptionValues(dict.MDUpdateTypes), cobra.ShellCompDirectiveNoFileComp
+	return mdUpdateTypes(), cobra.ShellCompDirectiveNoFileComp
+}
+
+func TimestampFormats(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return []string{"rfc3339", "unix"}, cobra.ShellCompDirectiveNoFileComp
+}
+
+func DisconnectPolicies(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return []string{"exit", "retry"}, cobra.ShellCompDirectiveNoFileComp
+}
+
+func OutputFormats(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return application.OutputFormats, cobra.ShellCompDirectiveNoFileComp
 }
@@ -0,0 +1,21 @@
+package complete
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/iancoleman/strcase"
+	"github.com/spf13/cobra"
+
+	"sylr.dev/fix/pkg/dict"
+)
+
+func MsgTypes(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	names := make([]string, 0, len(dict.MessageTypes))
+	for k := range dict.MessageTypes {
+		names = append(names, strcase.ToCamel(strings.ToLower(k)))
+	}
+	sort.Strings(names)
+
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
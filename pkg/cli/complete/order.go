@@ -24,7 +24,7 @@ func OrderPartyIDSource(cmd *cobra.Command, args []string, toComplete string) ([
 }
 
 func OrderPartySubIDTypes(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
-	return utils.PrettyOptionValues(dict.PartySubIDTypes), cobra.ShellCompDirectiveNoFileComp
+	return partySubIDTypes(), cobra.ShellCompDirectiveNoFileComp
 }
 
 func OrderPartyIDRole(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
@@ -32,7 +32,7 @@ func OrderPartyIDRole(cmd *cobra.Command, args []string, toComplete string) ([]s
 }
 
 func OrderPartyRoleQualifier(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
-	return utils.PrettyOptionValues(dict.PartyRoleQualifiers), cobra.ShellCompDirectiveNoFileComp
+	return partyRoleQualifiers(), cobra.ShellCompDirectiveNoFileComp
 }
 
 func OrderOriginationRole(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
@@ -40,5 +40,5 @@ func OrderOriginationRole(cmd *cobra.Command, args []string, toComplete string)
 }
 
 func OrderAttributeType(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
-	return utils.PrettyOptionValues(dict.OrderAttributeTypes), cobra.ShellCompDirectiveNoFileComp
+	return orderAttributeTypes(), cobra.ShellCompDirectiveNoFileComp
 }
@@ -1,12 +1,47 @@
 package complete
 
 import (
+	"fmt"
+	"os"
+
 	"github.com/spf13/cobra"
 
-	"sylr.dev/fix/pkg/dict"
-	"sylr.dev/fix/pkg/utils"
+	"sylr.dev/fix/config"
 )
 
 func SecurityListRequestType(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
-	return utils.PrettyOptionValues(dict.SecurityListRequestTypes), cobra.ShellCompDirectiveNoFileComp
+	return securityListRequestTypes(), cobra.ShellCompDirectiveNoFileComp
+}
+
+// Symbols completes the symbols cached by `fix list security` for the current
+// context.
+func Symbols(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	options := config.GetOptions()
+	fixConfig := config.GetConfig()
+
+	if conf, err := config.ReadYAMLNoAge(options.Config); err == nil {
+		*fixConfig = *conf
+	} else {
+		if options.Verbose > 0 {
+			fmt.Fprintf(os.Stdout, "%s\n", err)
+		}
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	context, err := config.GetCurrentContext()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	sessions, err := context.GetSessions()
+	if err != nil || len(sessions) == 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	cache, err := config.ReadSecurityCache(context.SecurityCachePath(sessions[0]))
+	if err != nil || cache == nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return cache.Symbols, cobra.ShellCompDirectiveNoFileComp
 }
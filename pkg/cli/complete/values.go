@@ -0,0 +1,38 @@
+package complete
+
+import (
+	"sync"
+
+	"sylr.dev/fix/pkg/dict"
+	"sylr.dev/fix/pkg/utils"
+)
+
+// Completion values of the dictionaries which are never modified at runtime.
+// The enums listed in dict.OverridableEnums must not be memoized as they are
+// replaced by the overrides of the context once it is loaded.
+var (
+	mdUpdateTypes            = staticValues(dict.MDUpdateTypes)
+	orderAttributeTypes      = staticValues(dict.OrderAttributeTypes)
+	partyRoleQualifiers      = staticValues(dict.PartyRoleQualifiers)
+	partySubIDTypes          = staticValues(dict.PartySubIDTypes)
+	securityListRequestTypes = staticValues(dict.SecurityListRequestTypes)
+	subscriptionRequestTypes = staticValues(dict.SubscriptionRequestTypes)
+)
+
+// staticValues returns a function computing the completion values of input
+// once, input must never be modified afterwards.
+func staticValues[T any](input map[string]T) func() []string {
+	var (
+		once   sync.Once
+		values []string
+	)
+
+	return func() []string {
+		once.Do(func() {
+			values = utils.PrettyOptionValues(input)
+		})
+
+		// Completion functions may hand the slice over to be modified
+		return append([]string(nil), values...)
+	}
+}
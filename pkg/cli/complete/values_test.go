@@ -0,0 +1,22 @@
+package complete
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestStaticValues(t *testing.T) {
+	values := staticValues(map[string]int{"SNAPSHOT_PLUS_UPDATES": 1, "SNAPSHOT": 0})
+	want := []string{"snapshot", "snapshot_plus_updates"}
+
+	got := values()
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("staticValues() = %v, want %v", got, want)
+	}
+
+	// The memoized values are not shared with the callers
+	got[0] = "modified"
+	if got := values(); !reflect.DeepEqual(got, want) {
+		t.Errorf("staticValues() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,23 @@
+package complete
+
+import (
+	"sort"
+
+	"github.com/spf13/cobra"
+
+	"sylr.dev/fix/pkg/dict"
+)
+
+func BeginStrings(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return dict.BeginStrings, cobra.ShellCompDirectiveNoFileComp
+}
+
+func ApplVerIDs(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	versions := make([]string, 0, len(dict.ApplVerIDs))
+	for k := range dict.ApplVerIDs {
+		versions = append(versions, k)
+	}
+	sort.Strings(versions)
+
+	return versions, cobra.ShellCompDirectiveNoFileComp
+}
@@ -0,0 +1,50 @@
+package options
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/quickfixgo/quickfix/datadictionary"
+	"github.com/spf13/cobra"
+
+	"sylr.dev/fix/config"
+)
+
+// DictionaryOptions holds the data dictionaries options of commands working on
+// captured messages.
+type DictionaryOptions struct {
+	TransportDict string
+	AppDict       string
+}
+
+// AddDictionaryFlags adds the --transport-dict and --app-dict flags which
+// default to the dictionaries installed by `fix init config`.
+func AddDictionaryFlags(cmd *cobra.Command, o *DictionaryOptions) {
+	cmd.Flags().StringVar(&o.TransportDict, "transport-dict", filepath.Join("$HOME", ".fix", "FIXT11.xml"), "Transport data dictionary")
+	cmd.Flags().StringVar(&o.AppDict, "app-dict", filepath.Join("$HOME", ".fix", "FIX50SP2.xml"), "Application data dictionary")
+}
+
+// GetDictionaries parses the dictionaries. Dictionaries which were not
+// explicitly given and can not be found are ignored.
+func (o *DictionaryOptions) GetDictionaries(cmd *cobra.Command) (*datadictionary.DataDictionary, *datadictionary.DataDictionary, error) {
+	var dicts [2]*datadictionary.DataDictionary
+
+	for i, f := range []struct{ flag, path string }{{"transport-dict", o.TransportDict}, {"app-dict", o.AppDict}} {
+		if len(f.path) == 0 {
+			continue
+		}
+
+		if _, err := os.Stat(os.ExpandEnv(f.path)); err != nil && !cmd.Flags().Changed(f.flag) {
+			continue
+		}
+
+		dd, err := config.ParseFIXDictionary(f.path)
+		if err != nil {
+			return nil, nil, fmt.Errorf("--%s: %w", f.flag, err)
+		}
+		dicts[i] = dd
+	}
+
+	return dicts[0], dicts[1], nil
+}
@@ -0,0 +1,77 @@
+package options
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/spf13/cobra"
+
+	"sylr.dev/fix/pkg/cli/complete"
+	"sylr.dev/fix/pkg/errors"
+)
+
+// IDOptions holds the identifier of the message sent by a command, e.g. its
+// ClOrdID or MDReqID. A UUID is generated when it is not given unless the
+// --no-<field>-autogen flag is set, for automation which must control the
+// identifiers it sends.
+type IDOptions struct {
+	id        *string
+	flag      string
+	field     string
+	noAutogen bool
+}
+
+// NewIDOptions adds the id flag holding the given field to the command, along
+// with its --no-<field>-autogen counterpart.
+func NewIDOptions(command *cobra.Command, id *string, flag string, field string, usage string) *IDOptions {
+	opt := &IDOptions{id: id, flag: flag, field: field}
+
+	command.Flags().StringVar(id, flag, "", usage+" (uuid autogenerated if not given)")
+	command.Flags().BoolVar(&opt.noAutogen, opt.NoAutogenFlag(), false, fmt.Sprintf("Require --%s instead of autogenerating the %s", flag, field))
+
+	command.RegisterFlagCompletionFunc(flag, complete.FreshUUID)
+
+	return opt
+}
+
+// NoAutogenFlag returns the name of the flag disabling the id generation.
+func (o *IDOptions) NoAutogenFlag() string {
+	return fmt.Sprintf("no-%s-autogen", strings.ToLower(o.field))
+}
+
+// Autogen reports whether ids can be generated.
+func (o *IDOptions) Autogen() bool {
+	return !o.noAutogen
+}
+
+// Validate generates the id if it was not given, or fails if its generation
+// is disabled.
+func (o *IDOptions) Validate() error {
+	if len(*o.id) > 0 {
+		return nil
+	}
+
+	if o.noAutogen {
+		return fmt.Errorf("%w: --%s is required with --%s", errors.OptionsNoIDGiven, o.flag, o.NoAutogenFlag())
+	}
+
+	id, err := o.Generate()
+	if err != nil {
+		return err
+	}
+
+	*o.id = id
+
+	return nil
+}
+
+// Generate returns a new id, e.g. to replace one rejected by the
+// counterparty, or fails if the generation is disabled.
+func (o *IDOptions) Generate() (string, error) {
+	if o.noAutogen {
+		return "", fmt.Errorf("%w: %s autogeneration disabled by --%s", errors.OptionsNoIDGiven, o.field, o.NoAutogenFlag())
+	}
+
+	return uuid.NewString(), nil
+}
@@ -0,0 +1,94 @@
+package options
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/spf13/cobra"
+
+	"sylr.dev/fix/pkg/errors"
+)
+
+func TestIDOptions(t *testing.T) {
+	tests := []struct {
+		name       string
+		args       []string
+		wantID     string
+		wantUUID   bool
+		wantErr    bool
+		wantAuto   bool
+		wantGenErr bool
+	}{
+		{
+			name:     "autogenerated",
+			wantUUID: true,
+			wantAuto: true,
+		},
+		{
+			name:     "given",
+			args:     []string{"--mdreqid", "req-1"},
+			wantID:   "req-1",
+			wantAuto: true,
+		},
+		{
+			name:       "given without autogen",
+			args:       []string{"--mdreqid", "req-1", "--no-mdreqid-autogen"},
+			wantID:     "req-1",
+			wantGenErr: true,
+		},
+		{
+			name:       "missing without autogen",
+			args:       []string{"--no-mdreqid-autogen"},
+			wantErr:    true,
+			wantGenErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var id string
+			cmd := &cobra.Command{Use: "test"}
+			opt := NewIDOptions(cmd, &id, "mdreqid", "MDReqID", "Market data request id")
+
+			if err := cmd.ParseFlags(tt.args); err != nil {
+				t.Fatal(err)
+			}
+
+			if got := opt.NoAutogenFlag(); got != "no-mdreqid-autogen" {
+				t.Errorf("NoAutogenFlag() = %q, want %q", got, "no-mdreqid-autogen")
+			}
+			if got := opt.Autogen(); got != tt.wantAuto {
+				t.Errorf("Autogen() = %t, want %t", got, tt.wantAuto)
+			}
+
+			err := opt.Validate()
+			if tt.wantErr {
+				if !errors.Is(err, errors.OptionsNoIDGiven) {
+					t.Errorf("Validate() error = %v, want %v", err, errors.OptionsNoIDGiven)
+				}
+			} else if err != nil {
+				t.Errorf("Validate() error = %v", err)
+			} else if tt.wantUUID {
+				if _, err := uuid.Parse(id); err != nil {
+					t.Errorf("Validate() generated %q, want a uuid", id)
+				}
+			} else if id != tt.wantID {
+				t.Errorf("Validate() id = %q, want %q", id, tt.wantID)
+			}
+
+			generated, err := opt.Generate()
+			if tt.wantGenErr {
+				if !errors.Is(err, errors.OptionsNoIDGiven) {
+					t.Errorf("Generate() error = %v, want %v", err, errors.OptionsNoIDGiven)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Generate() error = %v", err)
+			}
+			if _, err := uuid.Parse(generated); err != nil || generated == id {
+				t.Errorf("Generate() = %q, want a new uuid", generated)
+			}
+		})
+	}
+}
@@ -0,0 +1,19 @@
+package dict
+
+import (
+	"github.com/quickfixgo/enum"
+)
+
+var ApplVerIDs = map[string]enum.ApplVerID{
+	"FIX.2.7":    enum.ApplVerID_FIX27,
+	"FIX.3.0":    enum.ApplVerID_FIX30,
+	"FIX.4.0":    enum.ApplVerID_FIX40,
+	"FIX.4.1":    enum.ApplVerID_FIX41,
+	"FIX.4.2":    enum.ApplVerID_FIX42,
+	"FIX.4.3":    enum.ApplVerID_FIX43,
+	"FIX.4.4":    enum.ApplVerID_FIX44,
+	"FIX.5.0":    enum.ApplVerID_FIX50,
+	"FIX.5.0SP1": enum.ApplVerID_FIX50SP1,
+	"FIX.5.0SP2": enum.ApplVerID_FIX50SP2,
+	"FIXLATEST":  enum.ApplVerID_FIXLATEST,
+}
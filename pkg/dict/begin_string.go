@@ -0,0 +1,14 @@
+package dict
+
+import (
+	"github.com/quickfixgo/quickfix"
+)
+
+var BeginStrings = []string{
+	quickfix.BeginStringFIX40,
+	quickfix.BeginStringFIX41,
+	quickfix.BeginStringFIX42,
+	quickfix.BeginStringFIX43,
+	quickfix.BeginStringFIX44,
+	quickfix.BeginStringFIXT11,
+}
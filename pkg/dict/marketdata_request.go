@@ -94,3 +94,37 @@ var MDUpdateTypes = map[string]enum.MDUpdateType{
 	"FULL_REFRESH":        enum.MDUpdateType_FULL_REFRESH,
 	"INCREMENTAL_REFRESH": enum.MDUpdateType_INCREMENTAL_REFRESH,
 }
+
+var MDReqRejReasons = map[string]enum.MDReqRejReason{
+	"UNKNOWN_SYMBOL":                      enum.MDReqRejReason_UNKNOWN_SYMBOL,
+	"DUPLICATE_MDREQID":                   enum.MDReqRejReason_DUPLICATE_MDREQID,
+	"INSUFFICIENT_BANDWIDTH":              enum.MDReqRejReason_INSUFFICIENT_BANDWIDTH,
+	"INSUFFICIENT_PERMISSIONS":            enum.MDReqRejReason_INSUFFICIENT_PERMISSIONS,
+	"UNSUPPORTED_SUBSCRIPTIONREQUESTTYPE": enum.MDReqRejReason_UNSUPPORTED_SUBSCRIPTIONREQUESTTYPE,
+	"UNSUPPORTED_MARKETDEPTH":             enum.MDReqRejReason_UNSUPPORTED_MARKETDEPTH,
+	"UNSUPPORTED_MDUPDATETYPE":            enum.MDReqRejReason_UNSUPPORTED_MDUPDATETYPE,
+	"UNSUPPORTED_AGGREGATEDBOOK":          enum.MDReqRejReason_UNSUPPORTED_AGGREGATEDBOOK,
+	"UNSUPPORTED_MDENTRYTYPE":             enum.MDReqRejReason_UNSUPPORTED_MDENTRYTYPE,
+	"UNSUPPORTED_TRADINGSESSIONID":        enum.MDReqRejReason_UNSUPPORTED_TRADINGSESSIONID,
+	"UNSUPPORTED_SCOPE":                   enum.MDReqRejReason_UNSUPPORTED_SCOPE,
+	"UNSUPPORTED_OPENCLOSESETTLEFLAG":     enum.MDReqRejReason_UNSUPPORTED_OPENCLOSESETTLEFLAG,
+	"UNSUPPORTED_MDIMPLICITDELETE":        enum.MDReqRejReason_UNSUPPORTED_MDIMPLICITDELETE,
+	"INSUFFICIENT_CREDIT":                 enum.MDReqRejReason_INSUFFICIENT_CREDIT,
+}
+
+var MDReqRejReasonsReversed = map[enum.MDReqRejReason]string{
+	enum.MDReqRejReason_UNKNOWN_SYMBOL:                      "UNKNOWN_SYMBOL",
+	enum.MDReqRejReason_DUPLICATE_MDREQID:                   "DUPLICATE_MDREQID",
+	enum.MDReqRejReason_INSUFFICIENT_BANDWIDTH:              "INSUFFICIENT_BANDWIDTH",
+	enum.MDReqRejReason_INSUFFICIENT_PERMISSIONS:            "INSUFFICIENT_PERMISSIONS",
+	enum.MDReqRejReason_UNSUPPORTED_SUBSCRIPTIONREQUESTTYPE: "UNSUPPORTED_SUBSCRIPTIONREQUESTTYPE",
+	enum.MDReqRejReason_UNSUPPORTED_MARKETDEPTH:             "UNSUPPORTED_MARKETDEPTH",
+	enum.MDReqRejReason_UNSUPPORTED_MDUPDATETYPE:            "UNSUPPORTED_MDUPDATETYPE",
+	enum.MDReqRejReason_UNSUPPORTED_AGGREGATEDBOOK:          "UNSUPPORTED_AGGREGATEDBOOK",
+	enum.MDReqRejReason_UNSUPPORTED_MDENTRYTYPE:             "UNSUPPORTED_MDENTRYTYPE",
+	enum.MDReqRejReason_UNSUPPORTED_TRADINGSESSIONID:        "UNSUPPORTED_TRADINGSESSIONID",
+	enum.MDReqRejReason_UNSUPPORTED_SCOPE:                   "UNSUPPORTED_SCOPE",
+	enum.MDReqRejReason_UNSUPPORTED_OPENCLOSESETTLEFLAG:     "UNSUPPORTED_OPENCLOSESETTLEFLAG",
+	enum.MDReqRejReason_UNSUPPORTED_MDIMPLICITDELETE:        "UNSUPPORTED_MDIMPLICITDELETE",
+	enum.MDReqRejReason_INSUFFICIENT_CREDIT:                 "INSUFFICIENT_CREDIT",
+}
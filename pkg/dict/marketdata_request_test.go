@@ -0,0 +1,58 @@
+package dict
+
+import (
+	"testing"
+
+	"github.com/quickfixgo/enum"
+)
+
+func TestMDEntryTypes(t *testing.T) {
+	tests := []struct {
+		name string
+		want enum.MDEntryType
+	}{
+		{name: "BID", want: "0"},
+		{name: "OFFER", want: "1"},
+		{name: "TRADE", want: "2"},
+		{name: "OPENING_PRICE", want: "4"},
+		{name: "CLOSING_PRICE", want: "5"},
+		{name: "SETTLEMENT_PRICE", want: "6"},
+		{name: "TRADING_SESSION_HIGH_PRICE", want: "7"},
+		{name: "TRADING_SESSION_LOW_PRICE", want: "8"},
+		{name: "VOLUME_WEIGHTED_AVERAGE_PRICE", want: "9"},
+		{name: "IMBALANCE", want: "A"},
+		{name: "TRADE_VOLUME", want: "B"},
+		{name: "OPEN_INTEREST", want: "C"},
+		{name: "MID_PRICE", want: "H"},
+		{name: "EMPTY_BOOK", want: "J"},
+		{name: "AUCTION_CLEARING_PRICE", want: "Q"},
+		{name: "TRADE_HISTORY", want: "101"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := MDEntryTypes[tt.name]
+			if !ok {
+				t.Fatalf("MDEntryTypes[%s] not found", tt.name)
+			}
+			if got != tt.want {
+				t.Errorf("MDEntryTypes[%s] = %q, want %q", tt.name, got, tt.want)
+			}
+			if name := MDEntryTypesReversed[got]; name != tt.name {
+				t.Errorf("MDEntryTypesReversed[%s] = %q, want %q", got, name, tt.name)
+			}
+		})
+	}
+}
+
+func TestMDEntryTypesReversed(t *testing.T) {
+	if len(MDEntryTypes) != len(MDEntryTypesReversed) {
+		t.Errorf("len(MDEntryTypes) = %d, len(MDEntryTypesReversed) = %d", len(MDEntryTypes), len(MDEntryTypesReversed))
+	}
+
+	for name, value := range MDEntryTypes {
+		if got := MDEntryTypesReversed[value]; got != name {
+			t.Errorf("MDEntryTypesReversed[%s] = %q, want %q", value, got, name)
+		}
+	}
+}
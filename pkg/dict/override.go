@@ -0,0 +1,153 @@
+package dict
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// OverridableEnums are the fields whose enum values can be overridden, for
+// venues using non-standard values for standard concepts.
+var OverridableEnums = []string{
+	"MDEntryType",
+	"OrdType",
+	"OrderOrigination",
+	"PartyIDSource",
+	"PartyRole",
+	"Side",
+	"TimeInForce",
+}
+
+// Standard enums the overrides are overlaid on, they are never modified.
+var (
+	standardMDEntryTypes         = MDEntryTypes
+	standardMDEntryTypesReversed = MDEntryTypesReversed
+	standardOrderTypes           = OrderTypes
+	standardOrderTypesReversed   = OrderTypesReversed
+	standardOrderOriginations    = OrderOriginations
+	standardPartyIDSources       = PartyIDSources
+	standardPartyRoles           = PartyRoles
+	standardOrderSides           = OrderSides
+	standardOrderSidesReversed   = OrderSidesReversed
+	standardOrderTimeInForces    = OrderTimeInForces
+)
+
+// OverrideEnums replaces the values sent for the enum names of the given
+// fields, e.g. {Side: {buy: B}}. The enums of the run are overlays of the
+// standard ones, which are left untouched, so that the overrides of a run
+// never leak into another. Overrides giving the same value to several names of
+// an enum are rejected as the value could not be decoded back.
+func OverrideEnums(overrides map[string]map[string]string) error {
+	fields := make([]string, 0, len(overrides))
+	for field := range overrides {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+fields:
+	for _, field := range fields {
+		for _, overridable := range OverridableEnums {
+			if field == overridable {
+				continue fields
+			}
+		}
+
+		return fmt.Errorf("enum of field `%s` can't be overridden, expecting one of %s", field, strings.Join(OverridableEnums, ", "))
+	}
+
+	mdEntryTypes, mdEntryTypesReversed, err := overlayEnum(standardMDEntryTypes, standardMDEntryTypesReversed, overrides["MDEntryType"])
+	if err != nil {
+		return fmt.Errorf("MDEntryType: %w", err)
+	}
+	orderTypes, orderTypesReversed, err := overlayEnum(standardOrderTypes, standardOrderTypesReversed, overrides["OrdType"])
+	if err != nil {
+		return fmt.Errorf("OrdType: %w", err)
+	}
+	orderOriginations, _, err := overlayEnum(standardOrderOriginations, nil, overrides["OrderOrigination"])
+	if err != nil {
+		return fmt.Errorf("OrderOrigination: %w", err)
+	}
+	partyIDSources, _, err := overlayEnum(standardPartyIDSources, nil, overrides["PartyIDSource"])
+	if err != nil {
+		return fmt.Errorf("PartyIDSource: %w", err)
+	}
+	partyRoles, _, err := overlayEnum(standardPartyRoles, nil, overrides["PartyRole"])
+	if err != nil {
+		return fmt.Errorf("PartyRole: %w", err)
+	}
+	orderSides, orderSidesReversed, err := overlayEnum(standardOrderSides, standardOrderSidesReversed, overrides["Side"])
+	if err != nil {
+		return fmt.Errorf("Side: %w", err)
+	}
+	orderTimeInForces, _, err := overlayEnum(standardOrderTimeInForces, nil, overrides["TimeInForce"])
+	if err != nil {
+		return fmt.Errorf("TimeInForce: %w", err)
+	}
+
+	MDEntryTypes, MDEntryTypesReversed = mdEntryTypes, mdEntryTypesReversed
+	OrderTypes, OrderTypesReversed = orderTypes, orderTypesReversed
+	OrderOriginations = orderOriginations
+	PartyIDSources = partyIDSources
+	PartyRoles = partyRoles
+	OrderSides, OrderSidesReversed = orderSides, orderSidesReversed
+	OrderTimeInForces = orderTimeInForces
+
+	return nil
+}
+
+// overlayEnum returns copies of values and reversed with the overrides
+// applied, or the standard maps themselves if there is no override.
+func overlayEnum[T ~string](values map[string]T, reversed map[T]string, overrides map[string]string) (map[string]T, map[T]string, error) {
+	if len(overrides) == 0 {
+		return values, reversed, nil
+	}
+
+	overlay := make(map[string]T, len(values))
+	for name, value := range values {
+		overlay[name] = value
+	}
+
+	overridden := make([]string, 0, len(overrides))
+	isOverridden := make(map[string]bool, len(overrides))
+	for name, value := range overrides {
+		name = strings.ToUpper(name)
+		if _, ok := overlay[name]; !ok {
+			return nil, nil, fmt.Errorf("unknown value `%s`", strings.ToLower(name))
+		}
+		if len(value) == 0 {
+			return nil, nil, fmt.Errorf("empty value for `%s`", strings.ToLower(name))
+		}
+
+		overlay[name] = T(value)
+		overridden = append(overridden, name)
+		isOverridden[name] = true
+	}
+	sort.Strings(overridden)
+
+	// Overridden values must not be shared with any other name once all the
+	// overrides are applied, values can be swapped between names
+	for _, name := range overridden {
+		for other, value := range overlay {
+			if other != name && value == overlay[name] {
+				return nil, nil, fmt.Errorf("`%s` and `%s` would both be sent as `%s`", strings.ToLower(name), strings.ToLower(other), value)
+			}
+		}
+	}
+
+	if reversed == nil {
+		return overlay, nil, nil
+	}
+
+	overlayReversed := make(map[T]string, len(reversed))
+	for value, name := range reversed {
+		if isOverridden[name] {
+			continue
+		}
+		overlayReversed[value] = name
+	}
+	for _, name := range overridden {
+		overlayReversed[overlay[name]] = name
+	}
+
+	return overlay, overlayReversed, nil
+}
@@ -0,0 +1,56 @@
+package errors
+
+// CodedError is implemented by the errors of this package, it allows callers
+// to handle errors programmatically without relying on their messages.
+type CodedError interface {
+	error
+	// Code returns a machine readable code unique to the error.
+	Code() string
+	// Category returns the code of the root error of the error chain.
+	Category() string
+}
+
+// Error is an error carrying a code and wrapping an optional parent error.
+type Error struct {
+	code   string
+	msg    string
+	parent *Error
+}
+
+var _ CodedError = (*Error)(nil)
+
+func newError(parent *Error, code string, msg string) *Error {
+	return &Error{
+		code:   code,
+		msg:    msg,
+		parent: parent,
+	}
+}
+
+func (e *Error) Error() string {
+	if e.parent == nil {
+		return e.msg
+	}
+
+	return e.parent.Error() + ": " + e.msg
+}
+
+func (e *Error) Code() string {
+	return e.code
+}
+
+func (e *Error) Category() string {
+	if e.parent == nil {
+		return e.code
+	}
+
+	return e.parent.Category()
+}
+
+func (e *Error) Unwrap() error {
+	if e.parent == nil {
+		return nil
+	}
+
+	return e.parent
+}
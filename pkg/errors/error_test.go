@@ -0,0 +1,87 @@
+package errors
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestError(t *testing.T) {
+	tests := []struct {
+		name         string
+		err          *Error
+		wantMessage  string
+		wantCode     string
+		wantCategory string
+	}{
+		{
+			name:         "root error",
+			err:          Config,
+			wantMessage:  "configuration",
+			wantCode:     "CONFIG",
+			wantCategory: "CONFIG",
+		},
+		{
+			name:         "child error",
+			err:          ConfigContextNotFound,
+			wantMessage:  "configuration: context not found",
+			wantCode:     "CONFIG_CONTEXT_NOT_FOUND",
+			wantCategory: "CONFIG",
+		},
+		{
+			name:         "fix error",
+			err:          FixOrderRejected,
+			wantMessage:  "FIX: rejected order",
+			wantCode:     "FIX_ORDER_REJECTED",
+			wantCategory: "FIX",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.err.Error(); got != tt.wantMessage {
+				t.Errorf("Error() = %q, want %q", got, tt.wantMessage)
+			}
+			if got := tt.err.Code(); got != tt.wantCode {
+				t.Errorf("Code() = %q, want %q", got, tt.wantCode)
+			}
+			if got := tt.err.Category(); got != tt.wantCategory {
+				t.Errorf("Category() = %q, want %q", got, tt.wantCategory)
+			}
+		})
+	}
+}
+
+func TestErrorChain(t *testing.T) {
+	err := fmt.Errorf("%w: context `foo`", ConfigContextNotFound)
+
+	tests := []struct {
+		name   string
+		target error
+		want   bool
+	}{
+		{name: "itself", target: ConfigContextNotFound, want: true},
+		{name: "parent", target: Config, want: true},
+		{name: "sibling", target: ConfigSessionNotFound, want: false},
+		{name: "other category", target: Options, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Is(err, tt.target); got != tt.want {
+				t.Errorf("Is(%q, %q) = %t, want %t", err, tt.target, got, tt.want)
+			}
+		})
+	}
+
+	var coded CodedError
+	if !As(err, &coded) {
+		t.Fatalf("As(%q) found no CodedError", err)
+	}
+	if coded.Code() != "CONFIG_CONTEXT_NOT_FOUND" || coded.Category() != "CONFIG" {
+		t.Errorf("As(%q) = %s/%s, want CONFIG_CONTEXT_NOT_FOUND/CONFIG", err, coded.Code(), coded.Category())
+	}
+
+	if Config.Unwrap() != nil {
+		t.Errorf("Config.Unwrap() = %v, want nil", Config.Unwrap())
+	}
+}
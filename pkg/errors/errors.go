@@ -2,7 +2,6 @@ package errors
 
 import (
 	"errors"
-	"fmt"
 )
 
 var (
@@ -11,39 +10,55 @@ var (
 )
 
 var (
-	Config                          = errors.New("configuration")
-	ConfigAcceptorNotFound          = fmt.Errorf("%w: acceptor not found", Config)
-	ConfigAlreadyExists             = fmt.Errorf("%w: already exists", Config)
-	ConfigCanNotBeCreated           = fmt.Errorf("%w: file can not be created", Config)
-	ConfigContextMultipleSessions   = fmt.Errorf("%w: multiple sessions in initiator context", Config)
-	ConfigContextNoSession          = fmt.Errorf("%w: context has no session", Config)
-	ConfigContextNotFound           = fmt.Errorf("%w: context not found", Config)
-	ConfigDuplicateContextName      = fmt.Errorf("%w: duplicate context name", Config)
-	ConfigDuplicateInitiatorName    = fmt.Errorf("%w: duplicate acceptor name", Config)
-	ConfigDuplicateSessionName      = fmt.Errorf("%w: duplicate session name", Config)
-	ConfigInitiatorNotFound         = fmt.Errorf("%w: initiator not found", Config)
-	ConfigSessionNotFound           = fmt.Errorf("%w: session not found", Config)
-	ConfigSessionNotInContext       = fmt.Errorf("%w: session name not in context", Config)
-	ConnectionTimeout               = errors.New("connection timeout")
-	Fix                             = errors.New("FIX")
-	FixLogout                       = fmt.Errorf("%w: logout received", Fix)
-	FixOrderRejected                = fmt.Errorf("%w: rejected order", Fix)
-	FixVersionNotImplemented        = fmt.Errorf("%w: version not implemented", Fix)
-	FixOrderStatusUnknown           = fmt.Errorf("%w: unknown order status", Fix)
-	NotImplemented                  = errors.New("not implemented")
-	Options                         = errors.New("options")
-	OptionsInvalidMarketPrice       = fmt.Errorf("%w: can't give price for market order", Options)
-	OptionsNoSymbolGiven            = fmt.Errorf("%w: no symbol given", Options)
-	OptionsNoTypeGiven              = fmt.Errorf("%w: no type given", Options)
-	OptionsNoPriceGiven             = fmt.Errorf("%w: no price given", Options)
-	OptionsInconsistentValues       = fmt.Errorf("%w: inconsistent values", Options)
-	OptionOrderSideUnknown          = fmt.Errorf("%w: unknown order side", Options)
-	OptionOrderTypeUnknown          = fmt.Errorf("%w: unknown order type", Options)
-	OptionOrderOriginationUnknown   = fmt.Errorf("%w: unknown order origination", Options)
-	OptionOrderAttributeTypeUnkonwn = fmt.Errorf("%w: unknown order attribute type", Options)
-	OptionOrderRoleUnknown          = fmt.Errorf("%w: unknown order role", Options)
-	OptionOrderRoleQualifierUnknown = fmt.Errorf("%w: unknown order role qualifier", Options)
-	OptionOrderIDSourceUnknown      = fmt.Errorf("%w: unknown order id source", Options)
-	OptionPartySubIDTypeUnknown     = fmt.Errorf("%w: unknown party sub id type", Options)
-	ResponseTimeout                 = errors.New("timeout while waiting for response")
+	Config                          = newError(nil, "CONFIG", "configuration")
+	ConfigAcceptorNotFound          = newError(Config, "CONFIG_ACCEPTOR_NOT_FOUND", "acceptor not found")
+	ConfigAlreadyExists             = newError(Config, "CONFIG_ALREADY_EXISTS", "already exists")
+	ConfigCanNotBeCreated           = newError(Config, "CONFIG_CAN_NOT_BE_CREATED", "file can not be created")
+	ConfigContextMultipleSessions   = newError(Config, "CONFIG_CONTEXT_MULTIPLE_SESSIONS", "multiple sessions in initiator context")
+	ConfigContextNoSession          = newError(Config, "CONFIG_CONTEXT_NO_SESSION", "context has no session")
+	ConfigContextNotFound           = newError(Config, "CONFIG_CONTEXT_NOT_FOUND", "context not found")
+	ConfigDuplicateContextName      = newError(Config, "CONFIG_DUPLICATE_CONTEXT_NAME", "duplicate context name")
+	ConfigDuplicateGroupTemplate    = newError(Config, "CONFIG_DUPLICATE_GROUP_TEMPLATE", "duplicate group template name")
+	ConfigDuplicateInitiatorName    = newError(Config, "CONFIG_DUPLICATE_INITIATOR_NAME", "duplicate acceptor name")
+	ConfigDuplicateSessionName      = newError(Config, "CONFIG_DUPLICATE_SESSION_NAME", "duplicate session name")
+	ConfigGroupTemplateNotFound     = newError(Config, "CONFIG_GROUP_TEMPLATE_NOT_FOUND", "group template not found")
+	ConfigInitiatorNotFound         = newError(Config, "CONFIG_INITIATOR_NOT_FOUND", "initiator not found")
+	ConfigInvalidDictionary         = newError(Config, "CONFIG_INVALID_DICTIONARY", "invalid data dictionary")
+	ConfigSessionNotFound           = newError(Config, "CONFIG_SESSION_NOT_FOUND", "session not found")
+	ConfigSessionNotInContext       = newError(Config, "CONFIG_SESSION_NOT_IN_CONTEXT", "session name not in context")
+	ConfigUnsupportedVersions       = newError(Config, "CONFIG_UNSUPPORTED_VERSIONS", "unsupported BeginString/DefaultApplVerID combination")
+	ConnectionTimeout               = newError(nil, "CONNECTION_TIMEOUT", "connection timeout")
+	Fix                             = newError(nil, "FIX", "FIX")
+	FixApplVerIDMismatch            = newError(Fix, "FIX_APPL_VER_ID_MISMATCH", "ApplVerID mismatch")
+	FixConformanceViolation         = newError(Fix, "FIX_CONFORMANCE_VIOLATION", "message violates conformance profile")
+	FixDictionaryMismatch           = newError(Fix, "FIX_DICTIONARY_MISMATCH", "message not supported by dictionary")
+	FixInvalidOutboundMessage       = newError(Fix, "FIX_INVALID_OUTBOUND_MESSAGE", "invalid outbound message")
+	FixLogout                       = newError(Fix, "FIX_LOGOUT", "logout received")
+	FixMarketDataRequestRejected    = newError(Fix, "FIX_MARKET_DATA_REQUEST_REJECTED", "market data request rejected")
+	FixOrderRejected                = newError(Fix, "FIX_ORDER_REJECTED", "rejected order")
+	FixSeqNumMismatch               = newError(Fix, "FIX_SEQ_NUM_MISMATCH", "sequence number mismatch at logon")
+	FixUnexpectedResponse           = newError(Fix, "FIX_UNEXPECTED_RESPONSE", "response does not match expectations")
+	FixUnknownTags                  = newError(Fix, "FIX_UNKNOWN_TAGS", "tags not defined in dictionary")
+	FixVersionNotImplemented        = newError(Fix, "FIX_VERSION_NOT_IMPLEMENTED", "version not implemented")
+	FixOrderStatusUnknown           = newError(Fix, "FIX_ORDER_STATUS_UNKNOWN", "unknown order status")
+	IdleTimeout                     = newError(nil, "IDLE_TIMEOUT", "no message received within idle timeout")
+	MaxRuntimeExceeded              = newError(nil, "MAX_RUNTIME_EXCEEDED", "max runtime exceeded")
+	MessagesDiffer                  = newError(nil, "MESSAGES_DIFFER", "messages differ")
+	NotImplemented                  = newError(nil, "NOT_IMPLEMENTED", "not implemented")
+	Options                         = newError(nil, "OPTIONS", "options")
+	OptionsInvalidMarketPrice       = newError(Options, "OPTIONS_INVALID_MARKET_PRICE", "can't give price for market order")
+	OptionsNoSymbolGiven            = newError(Options, "OPTIONS_NO_SYMBOL_GIVEN", "no symbol given")
+	OptionsNoTypeGiven              = newError(Options, "OPTIONS_NO_TYPE_GIVEN", "no type given")
+	OptionsNoPriceGiven             = newError(Options, "OPTIONS_NO_PRICE_GIVEN", "no price given")
+	OptionsNoIDGiven                = newError(Options, "OPTIONS_NO_ID_GIVEN", "no id given")
+	OptionsInconsistentValues       = newError(Options, "OPTIONS_INCONSISTENT_VALUES", "inconsistent values")
+	OptionOrderSideUnknown          = newError(Options, "OPTION_ORDER_SIDE_UNKNOWN", "unknown order side")
+	OptionOrderTypeUnknown          = newError(Options, "OPTION_ORDER_TYPE_UNKNOWN", "unknown order type")
+	OptionOrderOriginationUnknown   = newError(Options, "OPTION_ORDER_ORIGINATION_UNKNOWN", "unknown order origination")
+	OptionOrderAttributeTypeUnkonwn = newError(Options, "OPTION_ORDER_ATTRIBUTE_TYPE_UNKNOWN", "unknown order attribute type")
+	OptionOrderRoleUnknown          = newError(Options, "OPTION_ORDER_ROLE_UNKNOWN", "unknown order role")
+	OptionOrderRoleQualifierUnknown = newError(Options, "OPTION_ORDER_ROLE_QUALIFIER_UNKNOWN", "unknown order role qualifier")
+	OptionOrderIDSourceUnknown      = newError(Options, "OPTION_ORDER_ID_SOURCE_UNKNOWN", "unknown order id source")
+	OptionPartySubIDTypeUnknown     = newError(Options, "OPTION_PARTY_SUB_ID_TYPE_UNKNOWN", "unknown party sub id type")
+	ResponseTimeout                 = newError(nil, "RESPONSE_TIMEOUT", "timeout while waiting for response")
 )
@@ -0,0 +1,95 @@
+package initiator
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/spf13/cobra"
+
+	"github.com/quickfixgo/enum"
+	"github.com/quickfixgo/quickfix"
+	"github.com/quickfixgo/tag"
+
+	"sylr.dev/fix/config"
+	"sylr.dev/fix/pkg/dict"
+	"sylr.dev/fix/pkg/errors"
+	"sylr.dev/fix/pkg/utils"
+)
+
+// negotiatedApplVerIDs holds the DefaultApplVerID advertised by the acceptor
+// in its Logon, by session.
+var negotiatedApplVerIDs sync.Map
+
+// applVerIDApplication wraps a quickfix.Application and records the
+// DefaultApplVerID of the Logon messages received.
+type applVerIDApplication struct {
+	quickfix.Application
+}
+
+func newApplVerIDApplication(app quickfix.Application) *applVerIDApplication {
+	return &applVerIDApplication{
+		Application: app,
+	}
+}
+
+func (app *applVerIDApplication) FromAdmin(message *quickfix.Message, sessionID quickfix.SessionID) quickfix.MessageRejectError {
+	if message.IsMsgTypeOf(string(enum.MsgType_LOGON)) {
+		if applVerID, err := message.Body.GetString(tag.DefaultApplVerID); err == nil {
+			negotiatedApplVerIDs.Store(sessionID, applVerID)
+		}
+	}
+
+	return app.Application.FromAdmin(message, sessionID)
+}
+
+// AddAutoApplVerIDFlag adds the --auto-appl-ver flag to commands building
+// version specific messages.
+func AddAutoApplVerIDFlag(cmd *cobra.Command) {
+	options := config.GetOptions()
+
+	cmd.Flags().BoolVar(&options.AutoApplVerID, "auto-appl-ver", false, "Build the message for the DefaultApplVerID advertised by the acceptor in its Logon instead of the session's one")
+}
+
+// ApplyNegotiatedApplVerID sets the DefaultApplVerID of the session to the one
+// advertised by the acceptor when --auto-appl-ver is given. It fails if the
+// command does not support the negotiated version.
+func ApplyNegotiatedApplVerID(cmd *cobra.Command, session *config.Session) error {
+	if !config.GetOptions().AutoApplVerID {
+		return nil
+	}
+
+	var negotiated string
+	negotiatedApplVerIDs.Range(func(key, value interface{}) bool {
+		sessionID := key.(quickfix.SessionID)
+		if sessionID.BeginString == session.BeginString && sessionID.SenderCompID == session.SenderCompID && sessionID.TargetCompID == session.TargetCompID {
+			negotiated = value.(string)
+			return false
+		}
+		return true
+	})
+
+	logger := config.GetLogger()
+	if len(negotiated) == 0 {
+		logger.Warn().Msgf("Acceptor did not advertise any DefaultApplVerID, using %s", session.DefaultApplVerID)
+		return nil
+	}
+
+	applVerID, err := dict.SearchValue(dict.ApplVerIDs, enum.ApplVerID(negotiated))
+	if err != nil {
+		return fmt.Errorf("%w: unknown negotiated DefaultApplVerID `%s`", errors.FixVersionNotImplemented, negotiated)
+	}
+
+	if versions := cmd.Annotations[utils.AnnotationVersions]; len(versions) > 0 {
+		if utils.Search(strings.Split(versions, ","), session.BeginString+"/"+applVerID) < 0 {
+			return fmt.Errorf("%w: negotiated DefaultApplVerID %s not supported by `%s` (%s)", errors.FixVersionNotImplemented, applVerID, cmd.CommandPath(), versions)
+		}
+	}
+
+	if applVerID != session.DefaultApplVerID {
+		logger.Info().Msgf("Using DefaultApplVerID %s negotiated with the acceptor instead of %s", applVerID, session.DefaultApplVerID)
+	}
+	session.DefaultApplVerID = applVerID
+
+	return nil
+}
@@ -0,0 +1,523 @@
+package application
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/iancoleman/strcase"
+	"github.com/olekukonko/tablewriter"
+
+	"github.com/quickfixgo/enum"
+	"github.com/quickfixgo/quickfix"
+	"github.com/quickfixgo/quickfix/datadictionary"
+	"github.com/quickfixgo/tag"
+
+	"sylr.dev/fix/pkg/dict"
+	"sylr.dev/fix/pkg/utils"
+)
+
+const (
+	OutputTable = "table"
+	OutputJSON  = "json"
+	OutputCSV   = "csv"
+)
+
+var OutputFormats = []string{OutputTable, OutputJSON, OutputCSV}
+
+type Messager interface {
+	GetSymbol() (string, quickfix.MessageRejectError)
+}
+
+var (
+	type2sym = map[string]string{
+		"Bid":   "+",
+		"Trade": "=",
+		"Offer": "-",
+	}
+
+	// outMux serializes the writes to the outputs. Requests are printed from
+	// the command goroutine and responses from the quickfix ones, and several
+	// applications may share the same output.
+	outMux sync.Mutex
+)
+
+// MarketData is the printable form of a market data message.
+type MarketData struct {
+	Direction      string    `json:"direction"`
+	Kind           string    `json:"kind"`
+	SeqNum         int       `json:"seqnum,omitempty"`
+	MDReqID        string    `json:"mdreqid,omitempty"`
+	TargetSubID    string    `json:"target_sub_id,omitempty"`
+	LastUpdateTime string    `json:"last_update_time,omitempty"`
+	Entries        []MDEntry `json:"entries"`
+
+	// message is the whole message with nested repeating groups, printed
+	// instead of the entries in GroupJSON mode.
+	message map[string]interface{}
+}
+
+// MarketDataMessage is the printable form of a whole market data message.
+type MarketDataMessage struct {
+	Direction   string                 `json:"direction"`
+	Kind        string                 `json:"kind"`
+	SeqNum      int                    `json:"seqnum,omitempty"`
+	MDReqID     string                 `json:"mdreqid,omitempty"`
+	TargetSubID string                 `json:"target_sub_id,omitempty"`
+	Message     map[string]interface{} `json:"message"`
+}
+
+// MDEntry is the printable form of a market data entry.
+type MDEntry struct {
+	Symbol  string `json:"symbol"`
+	ID      string `json:"id,omitempty"`
+	Action  string `json:"action,omitempty"`
+	Type    string `json:"type,omitempty"`
+	OrdType string `json:"ord_type,omitempty"`
+	Price   string `json:"price,omitempty"`
+	Size    string `json:"size,omitempty"`
+	Time    string `json:"time,omitempty"`
+}
+
+const (
+	mdKindRequest     = "request"
+	mdKindSnapshot    = "snapshot"
+	mdKindIncremental = "incremental"
+
+	mdDirectionIn  = "in"
+	mdDirectionOut = "out"
+)
+
+// MarketDataFilterFields are the fields --filter expressions can refer to,
+// aliases being named after the FIX fields.
+var MarketDataFilterFields = []string{
+	"kind", "mdreqid", "symbol", "id", "action", "type", "ord_type", "price", "size", "time",
+	"mdentryid", "mdupdateaction", "mdentrytype", "mdentrypx", "mdentrysize",
+}
+
+// filterFields returns the values of e matched by --filter expressions.
+func (e MDEntry) filterFields(md MarketData) map[string]string {
+	return map[string]string{
+		"kind":           md.Kind,
+		"mdreqid":        md.MDReqID,
+		"symbol":         e.Symbol,
+		"id":             e.ID,
+		"mdentryid":      e.ID,
+		"action":         e.Action,
+		"mdupdateaction": e.Action,
+		"type":           e.Type,
+		"mdentrytype":    e.Type,
+		"ordtype":        e.OrdType,
+		"price":          e.Price,
+		"mdentrypx":      e.Price,
+		"size":           e.Size,
+		"mdentrysize":    e.Size,
+		"time":           e.Time,
+	}
+}
+
+var mdEntryCSVHeader = []string{"kind", "symbol", "id", "action", "type", "ord_type", "price", "size", "time"}
+
+// formatTimestamp formats t according to format which can be "rfc3339",
+// "unix" or a Go time layout.
+func formatTimestamp(t time.Time, format string) string {
+	switch strings.ToLower(format) {
+	case "", "rfc3339":
+		return t.Format(time.RFC3339Nano)
+	case "unix":
+		return fmt.Sprintf("%d.%09d", t.Unix(), t.Nanosecond())
+	default:
+		return t.Format(format)
+	}
+}
+
+// formatDate formats the date-only t according to format, like
+// formatTimestamp does.
+func formatDate(t time.Time, format string) string {
+	switch strings.ToLower(format) {
+	case "", "rfc3339":
+		return t.Format("2006-01-02")
+	default:
+		return formatTimestamp(t, format)
+	}
+}
+
+// formatTimeOfDay formats the time-only t according to format, "unix" giving
+// the seconds elapsed since midnight.
+func formatTimeOfDay(t time.Time, format string) string {
+	switch strings.ToLower(format) {
+	case "", "rfc3339":
+		return t.Format("15:04:05.999999999")
+	case "unix":
+		midnight := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+		d := t.Sub(midnight)
+		return fmt.Sprintf("%d.%09d", d/time.Second, d%time.Second)
+	default:
+		return t.Format(format)
+	}
+}
+
+func enumDescription(dict *datadictionary.DataDictionary, t quickfix.Tag, value string) string {
+	tagField := dict.FieldTypeByTag[int(t)]
+	return strcase.ToCamel(strings.ToLower(tagField.Enums[value].Description))
+}
+
+// newMDEntry converts a NoMDEntries group entry.
+func newMDEntry(s *quickfix.Group, dict *datadictionary.DataDictionary, timestampFormat string) MDEntry {
+	var entry MDEntry
+
+	if updateAction, err := s.GetString(tag.MDUpdateAction); err == nil {
+		entry.Action = enumDescription(dict, tag.MDUpdateAction, updateAction)
+	}
+
+	if entryType, err := s.GetString(tag.MDEntryType); err == nil {
+		entry.Type = enumDescription(dict, tag.MDEntryType, entryType)
+	}
+
+	if orderType, err := s.GetString(tag.OrdType); err == nil {
+		entry.OrdType = enumDescription(dict, tag.OrdType, orderType)
+	}
+
+	entry.ID, _ = s.GetString(tag.OrderID)
+	if len(entry.ID) == 0 {
+		entry.ID, _ = s.GetString(tag.TradeID)
+	}
+
+	entry.Symbol, _ = s.GetString(tag.Symbol)
+	entry.Price, _ = s.GetString(tag.MDEntryPx)
+	entry.Size, _ = s.GetString(tag.MDEntrySize)
+
+	stringDate, errDate := s.GetString(tag.MDEntryDate)
+	stringTime, errTime := s.GetString(tag.MDEntryTime)
+
+	if errDate == nil && errTime == nil {
+		timeDate, _ := time.Parse("20060102", stringDate)
+		timeTime, _ := time.Parse("15:04:05.999999999", stringTime)
+		entry.Time = formatTimestamp(utils.CombineDateAndTime(timeDate, timeTime), timestampFormat)
+	} else if errDate == nil {
+		timeDate, _ := time.Parse("20060102", stringDate)
+		entry.Time = formatDate(timeDate, timestampFormat)
+	} else if errTime == nil {
+		timeTime, _ := time.Parse("15:04:05.999999999", stringTime)
+		entry.Time = formatTimeOfDay(timeTime, timestampFormat)
+	}
+
+	return entry
+}
+
+// newMarketData converts the NoMDEntries group of a snapshot or incremental
+// refresh message.
+func newMarketData(kind string, group *quickfix.RepeatingGroup, msg *quickfix.Message, dict *datadictionary.DataDictionary, timestampFormat string) MarketData {
+	md := MarketData{
+		Direction: mdDirectionIn,
+		Kind:      kind,
+		Entries:   make([]MDEntry, 0, group.Len()),
+	}
+
+	md.MDReqID, _ = msg.Body.GetString(tag.MDReqID)
+	md.SeqNum, _ = msg.Header.GetInt(tag.MsgSeqNum)
+
+	// Responses come from the TargetSubID the request was sent to
+	md.TargetSubID, _ = msg.Header.GetString(tag.SenderSubID)
+
+	// Snapshots carry the symbol at the message level
+	symbol, _ := msg.Body.GetString(tag.Symbol)
+
+	for i := 0; i < group.Len(); i++ {
+		entry := newMDEntry(group.Get(i), dict, timestampFormat)
+		switch {
+		case kind == mdKindSnapshot:
+			entry.Symbol = symbol
+		case len(entry.Symbol) > 0:
+			// Incremental entries without a symbol refer to the one of the
+			// previous entry, or to the message level one
+			symbol = entry.Symbol
+		default:
+			entry.Symbol = symbol
+		}
+		md.Entries = append(md.Entries, entry)
+	}
+
+	if last, err := msg.Body.GetTime(tag.LastUpdateTime); err == nil {
+		md.LastUpdateTime = formatTimestamp(last, timestampFormat)
+	}
+
+	return md
+}
+
+// inboundMarketData converts a snapshot or incremental refresh message, keeping
+// the whole message in GroupJSON mode. The timestamp format only applies to the
+// table and CSV outputs, JSON ones always being RFC3339.
+func (app *MarketDataRequest) inboundMarketData(kind string, group *quickfix.RepeatingGroup, msg *quickfix.Message) MarketData {
+	timestampFormat := app.TimestampFormat
+	if app.Output == OutputJSON {
+		timestampFormat = "rfc3339"
+	}
+
+	md := newMarketData(kind, group, msg, app.AppDataDictionary, timestampFormat)
+
+	if app.GroupJSON {
+		md.message = utils.MessageTreeJSON(utils.MessageTree(msg, app.TransportDataDictionary, app.AppDataDictionary))
+	}
+
+	return md
+}
+
+// newMarketDataRequest converts an outgoing MarketDataRequest, one entry per
+// requested symbol and entry type.
+func newMarketDataRequest(msg *quickfix.Message) MarketData {
+	md := MarketData{
+		Direction: mdDirectionOut,
+		Kind:      mdKindRequest,
+	}
+
+	id, _ := msg.Body.GetString(tag.MDReqID)
+	md.MDReqID = id
+	md.TargetSubID, _ = msg.Header.GetString(tag.TargetSubID)
+
+	// quickfix sets the MsgSeqNum of the request when sending it
+	md.SeqNum, _ = msg.Header.GetInt(tag.MsgSeqNum)
+
+	types := quickfix.NewRepeatingGroup(tag.NoMDEntryTypes, quickfix.GroupTemplate{quickfix.GroupElement(tag.MDEntryType)})
+	msg.Body.GetGroup(types)
+
+	symbols := quickfix.NewRepeatingGroup(tag.NoRelatedSym, quickfix.GroupTemplate{quickfix.GroupElement(tag.Symbol)})
+	msg.Body.GetGroup(symbols)
+
+	for i := 0; i < symbols.Len(); i++ {
+		symbol, _ := symbols.Get(i).GetString(tag.Symbol)
+
+		for j := 0; j < types.Len(); j++ {
+			entry := MDEntry{Symbol: symbol, ID: id}
+
+			if t, err := types.Get(j).GetString(tag.MDEntryType); err == nil {
+				entry.Type = t
+				if name, ok := dict.MDEntryTypesReversed[enum.MDEntryType(t)]; ok {
+					entry.Type = strcase.ToCamel(strings.ToLower(name))
+				}
+			}
+
+			md.Entries = append(md.Entries, entry)
+		}
+	}
+
+	return md
+}
+
+// PrintRequest prints the outgoing MarketDataRequest msg with the configured
+// output format so that it can be correlated with the responses.
+func (app *MarketDataRequest) PrintRequest(msg *quickfix.Message) {
+	app.printMarketData(newMarketDataRequest(msg))
+}
+
+func orNil(s string) string {
+	if len(s) == 0 {
+		return nilstr
+	}
+
+	return s
+}
+
+// printMarketData prints md according to the configured output format. In
+// trace mode md is buffered until FlushTrace is called. Inbound entries not
+// matching the filter are left out, messages without any entry left being
+// counted and dropped.
+func (app *MarketDataRequest) printMarketData(md MarketData) {
+	if !app.LabelTargetSubID {
+		md.TargetSubID = ""
+	}
+	if !app.ShowSeqNum {
+		md.SeqNum = 0
+	}
+
+	if app.Filter != nil && md.Direction == mdDirectionIn {
+		entries := make([]MDEntry, 0, len(md.Entries))
+		for _, e := range md.Entries {
+			if app.Filter.Match(e.filterFields(md)) {
+				entries = append(entries, e)
+			}
+		}
+
+		if len(entries) == 0 {
+			atomic.AddInt64(&app.filtered, 1)
+			return
+		}
+		md.Entries = entries
+	}
+
+	if app.Trace {
+		app.traceMux.Lock()
+		defer app.traceMux.Unlock()
+
+		if _, ok := app.traces[md.MDReqID]; !ok {
+			app.traceOrder = append(app.traceOrder, md.MDReqID)
+		}
+		app.traces[md.MDReqID] = append(app.traces[md.MDReqID], md)
+
+		return
+	}
+
+	app.writeMarketData(md)
+}
+
+// FlushTrace prints the buffered market data grouped by MDReqID, requests
+// first in the order they were sent.
+func (app *MarketDataRequest) FlushTrace() {
+	app.traceMux.Lock()
+	defer app.traceMux.Unlock()
+
+	outMux.Lock()
+	defer outMux.Unlock()
+
+	for _, id := range app.traceOrder {
+		if app.Output == OutputTable {
+			fmt.Fprintf(app.Out, "# MDReqID: %s\n", orNil(id))
+		}
+
+		for _, md := range app.traces[id] {
+			app.writeMarketDataLocked(md)
+		}
+	}
+
+	app.traces = make(map[string][]MarketData)
+	app.traceOrder = nil
+}
+
+func (app *MarketDataRequest) writeMarketData(md MarketData) {
+	outMux.Lock()
+	defer outMux.Unlock()
+
+	app.writeMarketDataLocked(md)
+}
+
+// writeMarketDataLocked prints md, outMux must be held.
+func (app *MarketDataRequest) writeMarketDataLocked(md MarketData) {
+	var err error
+
+	switch app.Output {
+	case OutputJSON:
+		err = app.printMarketDataJSON(md)
+	case OutputCSV:
+		err = app.printMarketDataCSV(md)
+	default:
+		if app.ShowSeqNum {
+			fmt.Fprintf(app.Out, "# MsgSeqNum: %d\n", md.SeqNum)
+		}
+		if app.LabelTargetSubID {
+			fmt.Fprintf(app.Out, "# TargetSubID: %s\n", orNil(md.TargetSubID))
+		}
+		printMarketDataTable(app.Out, md)
+	}
+
+	if err != nil {
+		app.Logger.Error().Err(err).Msg("Unable to print market data")
+	}
+}
+
+func (app *MarketDataRequest) printMarketDataJSON(md MarketData) error {
+	encoder := json.NewEncoder(app.Out)
+	if app.JSONPretty {
+		encoder.SetIndent("", "  ")
+	}
+
+	if md.message != nil {
+		return encoder.Encode(MarketDataMessage{
+			Direction:   md.Direction,
+			Kind:        md.Kind,
+			SeqNum:      md.SeqNum,
+			MDReqID:     md.MDReqID,
+			TargetSubID: md.TargetSubID,
+			Message:     md.message,
+		})
+	}
+
+	return encoder.Encode(md)
+}
+
+func (app *MarketDataRequest) printMarketDataCSV(md MarketData) error {
+	w := csv.NewWriter(app.Out)
+
+	if !app.csvHeaderWritten {
+		header := mdEntryCSVHeader
+		if app.LabelTargetSubID {
+			header = append(header[:len(header):len(header)], "target_sub_id")
+		}
+		if app.ShowSeqNum {
+			header = append(header[:len(header):len(header)], "seqnum")
+		}
+		if err := w.Write(header); err != nil {
+			return err
+		}
+		app.csvHeaderWritten = true
+	}
+
+	for _, e := range md.Entries {
+		record := []string{md.Kind, e.Symbol, e.ID, e.Action, e.Type, e.OrdType, e.Price, e.Size, e.Time}
+		if app.LabelTargetSubID {
+			record = append(record, md.TargetSubID)
+		}
+		if app.ShowSeqNum {
+			record = append(record, strconv.Itoa(md.SeqNum))
+		}
+		if err := w.Write(record); err != nil {
+			return err
+		}
+	}
+
+	w.Flush()
+
+	return w.Error()
+}
+
+func printMarketDataTable(w io.Writer, md MarketData) {
+	table := tablewriter.NewWriter(w)
+	table.SetBorders(tablewriter.Border{false, false, false, true})
+	table.SetColumnSeparator(" ")
+	table.SetCenterSeparator("-")
+
+	if md.Kind == mdKindRequest {
+		table.SetHeader([]string{"SYMBOL", "MDREQID", "TYPE"})
+		for _, e := range md.Entries {
+			table.Append([]string{fmt.Sprintf("%s %s", type2sym[e.Type], orNil(e.Symbol)), orNil(e.ID), orNil(e.Type)})
+		}
+		table.Render()
+
+		return
+	}
+
+	incremental := md.Kind == mdKindIncremental
+	if incremental {
+		table.SetHeader([]string{"SYMBOL", "ID", "ACTION", "TYPE", "PRICE", "SIZE", "TIME"})
+		table.SetColMinWidth(2, 8)
+		table.SetColMinWidth(3, 14)
+	} else {
+		table.SetHeader([]string{"SYMBOL", "ORDER ID", "TYPE", "PRICE", "SIZE", "TIME"})
+	}
+
+	for _, e := range md.Entries {
+		typ := orNil(e.Type)
+		if len(e.OrdType) > 0 {
+			typ = fmt.Sprintf("%s (%s)", typ, e.OrdType)
+		}
+
+		symbol := fmt.Sprintf("%s %s", type2sym[e.Type], orNil(e.Symbol))
+
+		if incremental {
+			table.Append([]string{symbol, orNil(e.ID), orNil(e.Action), typ, orNil(e.Price), orNil(e.Size), e.Time})
+		} else {
+			table.Append([]string{symbol, orNil(e.ID), typ, orNil(e.Price), orNil(e.Size), e.Time})
+		}
+	}
+
+	if !incremental && len(md.LastUpdateTime) > 0 {
+		table.SetFooter([]string{"", "", "", "", "Last Time", md.LastUpdateTime})
+	}
+
+	table.Render()
+}
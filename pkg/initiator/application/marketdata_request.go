@@ -2,51 +2,137 @@ package application
 
 import (
 	"fmt"
+	"io"
 	"os"
+	"regexp"
 	"strings"
 	"sync"
-	"time"
+	"sync/atomic"
 
-	"github.com/iancoleman/strcase"
-	"github.com/olekukonko/tablewriter"
 	"github.com/rs/zerolog"
 
 	"github.com/quickfixgo/enum"
 	"github.com/quickfixgo/quickfix"
-	"github.com/quickfixgo/quickfix/datadictionary"
 	"github.com/quickfixgo/tag"
 
+	"sylr.dev/fix/pkg/dict"
 	"sylr.dev/fix/pkg/utils"
 )
 
 const nilstr = "<nil>"
 
-func NewMarketDataRequest(printData bool) *MarketDataRequest {
+// marketDataRequestLabel labels the metrics of the application.
+const marketDataRequestLabel = "marketdata_request"
+
+// NewMarketDataRequest returns the application, chanBuffer being the size of
+// the buffer of FromAppMessages. Once full, quickfix blocks until messages
+// are consumed.
+func NewMarketDataRequest(printData bool, chanBuffer int) *MarketDataRequest {
 	mdr := MarketDataRequest{
 		Connected:       make(chan interface{}),
-		FromAppMessages: make(chan quickfix.Messagable, 1),
+		FromAppMessages: make(chan quickfix.Messagable, chanBuffer),
+		SecurityLists:   make(chan *quickfix.Message, 1),
 		router:          quickfix.NewMessageRouter(),
 		printData:       printData,
+		Out:             os.Stdout,
+		Output:          OutputTable,
+		traces:          make(map[string][]MarketData),
 	}
 
 	mdr.router.AddRoute(quickfix.ApplVerIDFIX50SP2, string(enum.MsgType_MARKET_DATA_INCREMENTAL_REFRESH), mdr.onMarketDataIncrementalRefresh)
 	mdr.router.AddRoute(quickfix.ApplVerIDFIX50SP2, string(enum.MsgType_MARKET_DATA_SNAPSHOT_FULL_REFRESH), mdr.onMarketDataSnapshotFullRefresh)
+	mdr.router.AddRoute(quickfix.ApplVerIDFIX50SP2, string(enum.MsgType_MARKET_DATA_REQUEST_REJECT), mdr.onMarketDataRequestReject)
+	mdr.router.AddRoute(quickfix.ApplVerIDFIX50SP2, string(enum.MsgType_SECURITY_LIST), mdr.onSecurityList)
+
+	metricAppChanCapacity.WithLabelValues(marketDataRequestLabel).Set(float64(chanBuffer))
+	metricAppChanDepth.WithLabelValues(marketDataRequestLabel).Set(0)
 
 	return &mdr
 }
 
+// LogonInfo holds the session parameters exchanged in the Logon messages.
+type LogonInfo struct {
+	// HeartBtInt is the heartbeat interval acknowledged by the acceptor.
+	HeartBtInt int
+	// SentSeqNum is the MsgSeqNum of the Logon we sent.
+	SentSeqNum int
+	// ReceivedSeqNum is the MsgSeqNum of the Logon we received.
+	ReceivedSeqNum int
+	// ApplVerID is the DefaultApplVerID advertised by the acceptor.
+	ApplVerID string
+}
+
 type MarketDataRequest struct {
 	utils.QuickFixAppMessageLogger
 
 	Settings        *quickfix.Settings
+	SessionID       quickfix.SessionID
 	Connected       chan interface{}
 	FromAppMessages chan quickfix.Messagable
 	stopped         bool
 	mux             sync.RWMutex
 	router          *quickfix.MessageRouter
 	printData       bool
+
+	// SecurityLists receives the SecurityList responses to the requests sent
+	// to refresh the security cache.
+	SecurityLists chan *quickfix.Message
+
+	// Out is where market data is printed, defaults to os.Stdout.
+	Out io.Writer
+	// Output is the format used to print market data (table, json, csv).
+	Output string
+	// JSONPretty indents JSON output.
+	JSONPretty       bool
+	csvHeaderWritten bool
+
+	// GroupJSON prints inbound messages whole in JSON, repeating groups being
+	// nested, instead of their entries.
+	GroupJSON bool
+
+	// Trace groups printed requests and responses by MDReqID, they are
+	// buffered until FlushTrace is called.
+	Trace      bool
+	traces     map[string][]MarketData
+	traceOrder []string
+	traceMux   sync.Mutex
+
+	// TimestampFormat is the format used to print timestamps in the table and
+	// CSV outputs: "rfc3339", "unix" or a Go time layout. Defaults to
+	// "rfc3339".
+	TimestampFormat string
+
+	// LabelTargetSubID labels the printed market data with the TargetSubID
+	// the requests were sent to.
+	LabelTargetSubID bool
+
+	// ShowSeqNum prints the MsgSeqNum of the sent and received messages.
+	ShowSeqNum bool
+
+	// Filter, if set, drops the inbound market data entries not matching it.
+	Filter   *utils.Filter
+	filtered int64
+
+	// LogonInfo holds the parameters exchanged during Logon.
+	LogonInfo LogonInfo
+
+	// RawOut, if set, receives the exact bytes of every message sent and
+	// received, admin ones included, SOH delimiters included, one message per
+	// line.
+	RawOut io.Writer
+	rawMux sync.Mutex
+
+	// seqNumMismatch holds the quickfix event reporting a sequence number
+	// mismatch while logging on.
+	seqNumMismatch string
+	loggedOn       bool
+	eventMux       sync.Mutex
 }
 
+// seqNumMismatchPattern matches the quickfix events and Logout texts reporting
+// sequence number mismatches.
+var seqNumMismatchPattern = regexp.MustCompile(`(?i)(msg ?seq ?num|sequence number).*(too (low|high)|expect|mismatch)`)
+
 var _ quickfix.Application = (*MarketDataRequest)(nil)
 
 // Stop ensures the app chans are emptied so that quickfix can carry on with
@@ -63,20 +149,59 @@ func (app *MarketDataRequest) Stop() {
 	for len(app.FromAppMessages) > 0 {
 		<-app.FromAppMessages
 	}
+	for len(app.SecurityLists) > 0 {
+		<-app.SecurityLists
+	}
+
+	if app.Trace {
+		app.FlushTrace()
+	}
+
+	if filtered := atomic.LoadInt64(&app.filtered); filtered > 0 {
+		app.Logger.Info().Msgf("%d market data message(s) filtered out", filtered)
+	}
 }
 
 // Notification of a session begin created.
 func (app *MarketDataRequest) OnCreate(sessionID quickfix.SessionID) {
 	app.Logger.Debug().Msgf("New session: %s", sessionID)
+
+	app.SessionID = sessionID
 }
 
 // Notification of a session successfully logging on.
 func (app *MarketDataRequest) OnLogon(sessionID quickfix.SessionID) {
 	app.Logger.Debug().Msgf("Logon: %s", sessionID)
 
+	app.eventMux.Lock()
+	app.loggedOn = true
+	app.seqNumMismatch = ""
+	app.eventMux.Unlock()
+
 	app.Connected <- struct{}{}
 }
 
+// OnQuickFixEvent records the sequence number mismatches reported before the
+// session is logged on, either detected by quickfix or sent by the acceptor in
+// a Logout.
+func (app *MarketDataRequest) OnQuickFixEvent(event string) {
+	app.eventMux.Lock()
+	defer app.eventMux.Unlock()
+
+	if !app.loggedOn && seqNumMismatchPattern.MatchString(event) {
+		app.seqNumMismatch = event
+	}
+}
+
+// SeqNumMismatch returns the event reporting the sequence number mismatch which
+// prevented the session from logging on, if any.
+func (app *MarketDataRequest) SeqNumMismatch() string {
+	app.eventMux.Lock()
+	defer app.eventMux.Unlock()
+
+	return app.seqNumMismatch
+}
+
 // Notification of a session logging off or disconnecting.
 func (app *MarketDataRequest) OnLogout(sessionID quickfix.SessionID) {
 	app.Logger.Debug().Msgf("Logout: %s", sessionID)
@@ -96,6 +221,10 @@ func (app *MarketDataRequest) ToAdmin(message *quickfix.Message, sessionID quick
 
 	// Logon
 	if err == nil && typ == string(enum.MsgType_LOGON) {
+		if seqNum, err := message.Header.GetInt(tag.MsgSeqNum); err == nil {
+			app.LogonInfo.SentSeqNum = seqNum
+		}
+
 		sets := app.Settings.SessionSettings()
 		if session, ok := sets[sessionID]; ok {
 			if session.HasSetting("Username") {
@@ -116,6 +245,7 @@ func (app *MarketDataRequest) ToAdmin(message *quickfix.Message, sessionID quick
 	}
 
 	app.LogMessage(zerolog.TraceLevel, message, sessionID, true)
+	app.writeRaw(message)
 }
 
 // Notification of admin message being received from target.
@@ -128,10 +258,21 @@ func (app *MarketDataRequest) FromAdmin(message *quickfix.Message, sessionID qui
 	}
 
 	app.LogMessage(zerolog.TraceLevel, message, sessionID, false)
+	app.writeRaw(message)
 
 	switch typ {
+	case string(enum.MsgType_LOGON):
+		if seqNum, err := message.Header.GetInt(tag.MsgSeqNum); err == nil {
+			app.LogonInfo.ReceivedSeqNum = seqNum
+		}
+		if heartBtInt, err := message.Body.GetInt(tag.HeartBtInt); err == nil {
+			app.LogonInfo.HeartBtInt = heartBtInt
+		}
+		if applVerID, err := message.Body.GetString(tag.DefaultApplVerID); err == nil {
+			app.LogonInfo.ApplVerID = applVerID
+		}
 	case string(enum.MsgType_REJECT):
-		app.FromAppMessages <- message
+		app.pushFromApp(message)
 	}
 
 	return nil
@@ -147,6 +288,7 @@ func (app *MarketDataRequest) ToApp(message *quickfix.Message, sessionID quickfi
 	}
 
 	app.LogMessage(zerolog.TraceLevel, message, sessionID, true)
+	app.writeRaw(message)
 
 	return nil
 }
@@ -161,10 +303,25 @@ func (app *MarketDataRequest) FromApp(message *quickfix.Message, sessionID quick
 	}
 
 	app.LogMessage(zerolog.TraceLevel, message, sessionID, false)
+	app.writeRaw(message)
 
 	return app.router.Route(message, sessionID)
 }
 
+// writeRaw writes the raw message to RawOut followed by a newline.
+func (app *MarketDataRequest) writeRaw(message *quickfix.Message) {
+	if app.RawOut == nil {
+		return
+	}
+
+	app.rawMux.Lock()
+	defer app.rawMux.Unlock()
+
+	if _, err := io.WriteString(app.RawOut, message.String()+"\n"); err != nil {
+		app.Logger.Error().Err(err).Msg("Unable to write raw message")
+	}
+}
+
 func (app *MarketDataRequest) onMarketDataSnapshotFullRefresh(msg *quickfix.Message, sessionID quickfix.SessionID) quickfix.MessageRejectError {
 	group := quickfix.NewRepeatingGroup(
 		tag.NoMDEntries,
@@ -183,7 +340,7 @@ func (app *MarketDataRequest) onMarketDataSnapshotFullRefresh(msg *quickfix.Mess
 	msg.Body.GetGroup(group)
 
 	if app.printData {
-		printFIX50NoMDEntriesFull(group, msg, app.AppDataDictionary)
+		app.printMarketData(app.inboundMarketData(mdKindSnapshot, group, msg))
 	}
 
 	app.mux.RLock()
@@ -193,7 +350,7 @@ func (app *MarketDataRequest) onMarketDataSnapshotFullRefresh(msg *quickfix.Mess
 	}
 	app.mux.RUnlock()
 
-	app.FromAppMessages <- msg
+	app.pushFromApp(msg)
 
 	return nil
 }
@@ -221,7 +378,7 @@ func (app *MarketDataRequest) onMarketDataIncrementalRefresh(msg *quickfix.Messa
 	msg.Body.GetGroup(group)
 
 	if app.printData {
-		printFIX50NoMDEntriesInc(group, app.AppDataDictionary)
+		app.printMarketData(app.inboundMarketData(mdKindIncremental, group, msg))
 	}
 
 	app.mux.RLock()
@@ -231,186 +388,76 @@ func (app *MarketDataRequest) onMarketDataIncrementalRefresh(msg *quickfix.Messa
 	}
 	app.mux.RUnlock()
 
-	app.FromAppMessages <- msg
+	app.pushFromApp(msg)
 
 	return nil
 }
 
-type Messager interface {
-	GetSymbol() (string, quickfix.MessageRejectError)
-}
-
-var (
-	type2sym = map[string]string{
-		"Bid":   "+",
-		"Trade": "=",
-		"Offer": "-",
-	}
-)
-
-func printFIX50NoMDEntriesFull(group *quickfix.RepeatingGroup, msg *quickfix.Message, dict *datadictionary.DataDictionary) {
-	table := tablewriter.NewWriter(os.Stdout)
-	table.SetHeader([]string{"SYMBOL", "ORDER ID", "TYPE", "PRICE", "SIZE", "TIME"})
-	table.SetBorders(tablewriter.Border{false, false, false, true})
-	table.SetColumnSeparator(" ")
-	table.SetCenterSeparator("-")
+func (app *MarketDataRequest) onMarketDataRequestReject(msg *quickfix.Message, sessionID quickfix.SessionID) quickfix.MessageRejectError {
+	event := app.Logger.Warn()
 
-	symbol, err := msg.Body.GetString(tag.Symbol)
-	if err != nil {
-		symbol = nilstr
+	if id, err := msg.Body.GetString(tag.MDReqID); err == nil {
+		event = event.Str("mdreqid", id)
 	}
 
-	for i := 0; i < group.Len(); i++ {
-		var typSign, typ, orderTradeID, price, size, tim string
-
-		s := group.Get(i)
-
-		entryType, err := s.GetString(tag.MDEntryType)
-		if err != nil {
-			typ = nilstr
+	if reason, err := msg.Body.GetString(tag.MDReqRejReason); err == nil {
+		if name, ok := dict.MDReqRejReasonsReversed[enum.MDReqRejReason(reason)]; ok {
+			event = event.Str("reason", fmt.Sprintf("%s(%s)", reason, strings.ToLower(name)))
 		} else {
-			tagField := dict.FieldTypeByTag[int(tag.MDEntryType)]
-			typ = strcase.ToCamel(strings.ToLower(tagField.Enums[entryType].Description))
-			typSign = type2sym[typ]
-		}
-
-		orderType, err := s.GetString(tag.OrdType)
-		if err == nil {
-			tagField := dict.FieldTypeByTag[int(tag.OrdType)]
-			ordTyp := strcase.ToCamel(strings.ToLower(tagField.Enums[orderType].Description))
-			typ = fmt.Sprintf("%s (%s)", typ, ordTyp)
-		}
-
-		orderTradeID, err = s.GetString(tag.OrderID)
-		if err != nil {
-			orderTradeID = nilstr
-		}
-
-		if len(orderTradeID) == 0 || orderTradeID == nilstr {
-			orderTradeID, err = s.GetString(tag.TradeID)
-			if err != nil {
-				orderTradeID = nilstr
-			}
-		}
-
-		price, err = s.GetString(tag.MDEntryPx)
-		if err != nil {
-			price = nilstr
+			event = event.Str("reason", reason)
 		}
-
-		size, err = s.GetString(tag.MDEntrySize)
-		if err != nil {
-			size = nilstr
-		}
-
-		stringDate, errDate := s.GetString(tag.MDEntryDate)
-		stringTime, errTime := s.GetString(tag.MDEntryTime)
-
-		if errDate == nil && errTime == nil {
-			timeDate, _ := time.Parse("20060102", stringDate)
-			timeTime, _ := time.Parse("15:04:05.999999999", stringTime)
-			tim = utils.CombineDateAndTime(timeDate, timeTime).Format(time.RFC3339Nano)
-		} else if errDate == nil {
-			timeDate, _ := time.Parse("20060102", stringDate)
-			tim = timeDate.Format("2006-01-02")
-		} else if errTime == nil {
-			timeTime, _ := time.Parse("15:04:05.999999999", stringTime)
-			tim = timeTime.Format("15:04:05.999")
-		}
-
-		table.Append([]string{fmt.Sprintf("%s %s", typSign, symbol), orderTradeID, typ, price, size, tim})
 	}
 
-	last, err := msg.Body.GetTime(tag.LastUpdateTime)
-	if err == nil {
-		table.SetFooter([]string{"", "", "", "", "Last Time", last.Format(time.RFC3339Nano)})
+	if text, err := msg.Body.GetString(tag.Text); err == nil {
+		event = event.Str("text", text)
 	}
 
-	table.Render()
-}
-
-func printFIX50NoMDEntriesInc(group *quickfix.RepeatingGroup, dict *datadictionary.DataDictionary) {
-	table := tablewriter.NewWriter(os.Stdout)
-	table.SetHeader([]string{"SYMBOL", "ID", "ACTION", "TYPE", "PRICE", "SIZE", "TIME"})
-	table.SetBorders(tablewriter.Border{false, false, false, true})
-	table.SetColMinWidth(2, 8)
-	table.SetColMinWidth(3, 14)
-	table.SetColumnSeparator(" ")
-	table.SetCenterSeparator("-")
-
-	for i := 0; i < group.Len(); i++ {
-		var typSign, typ, orderTradeID, action, symbol, price, size, tim string
+	event.Msg("MarketDataRequest rejected")
 
-		s := group.Get(i)
-
-		updateAction, err := s.GetString(tag.MDUpdateAction)
-		if err != nil {
-			action = nilstr
-		} else {
-			tagField := dict.FieldTypeByTag[int(tag.MDUpdateAction)]
-			action = strcase.ToCamel(strings.ToLower(tagField.Enums[updateAction].Description))
-		}
+	app.mux.RLock()
+	if app.stopped {
+		app.mux.RUnlock()
+		return nil
+	}
+	app.mux.RUnlock()
 
-		entryType, err := s.GetString(tag.MDEntryType)
-		if err != nil {
-			typ = nilstr
-		} else {
-			tagField := dict.FieldTypeByTag[int(tag.MDEntryType)]
-			typ = strcase.ToCamel(strings.ToLower(tagField.Enums[entryType].Description))
-			typSign = type2sym[typ]
-		}
+	app.pushFromApp(msg)
 
-		orderType, err := s.GetString(tag.OrdType)
-		if err == nil {
-			tagField := dict.FieldTypeByTag[int(tag.OrdType)]
-			ordTyp := strcase.ToCamel(strings.ToLower(tagField.Enums[orderType].Description))
-			typ = fmt.Sprintf("%s (%s)", typ, ordTyp)
-		}
+	return nil
+}
 
-		orderTradeID, err = s.GetString(tag.OrderID)
-		if err != nil {
-			orderTradeID = nilstr
-		}
+// pushFromApp relays msg to FromAppMessages, blocking while it is full, and
+// reports the depth of the channel.
+func (app *MarketDataRequest) pushFromApp(msg *quickfix.Message) {
+	if len(app.FromAppMessages) == cap(app.FromAppMessages) {
+		metricAppChanFull.WithLabelValues(marketDataRequestLabel).Inc()
+	}
 
-		if len(orderTradeID) == 0 || orderTradeID == nilstr {
-			orderTradeID, err = s.GetString(tag.TradeID)
-			if err != nil {
-				orderTradeID = nilstr
-			}
-		}
+	app.FromAppMessages <- msg
 
-		symbol, err = s.GetString(tag.Symbol)
-		if err != nil {
-			symbol = nilstr
-		}
+	app.ObserveChanDepth()
+}
 
-		price, err = s.GetString(tag.MDEntryPx)
-		if err != nil {
-			price = nilstr
-		}
+// ObserveChanDepth reports the number of messages waiting in FromAppMessages,
+// it is to be called by the consumer after each message.
+func (app *MarketDataRequest) ObserveChanDepth() {
+	metricAppChanDepth.WithLabelValues(marketDataRequestLabel).Set(float64(len(app.FromAppMessages)))
+}
 
-		size, err = s.GetString(tag.MDEntrySize)
-		if err != nil {
-			size = nilstr
-		}
+func (app *MarketDataRequest) onSecurityList(msg *quickfix.Message, sessionID quickfix.SessionID) quickfix.MessageRejectError {
+	app.mux.RLock()
+	defer app.mux.RUnlock()
 
-		stringDate, errDate := s.GetString(tag.MDEntryDate)
-		stringTime, errTime := s.GetString(tag.MDEntryTime)
-
-		if errDate == nil && errTime == nil {
-			timeDate, _ := time.Parse("20060102", stringDate)
-			timeTime, _ := time.Parse("15:04:05.999999999", stringTime)
-			tim = utils.CombineDateAndTime(timeDate, timeTime).Format(time.RFC3339Nano)
-		} else if errDate == nil {
-			timeDate, _ := time.Parse("20060102", stringDate)
-			tim = timeDate.Format("2006-01-02")
-		} else if errTime == nil {
-			timeTime, _ := time.Parse("15:04:05.999999999", stringTime)
-			tim = timeTime.Format("15:04:05.999")
-		}
+	if app.stopped {
+		return nil
+	}
 
-		table.Append([]string{fmt.Sprintf("%s %s", typSign, symbol), orderTradeID, action, typ, price, size, tim})
+	// Unsolicited security lists are dropped
+	select {
+	case app.SecurityLists <- msg:
+	default:
+		app.Logger.Debug().Msg("Dropping unexpected SecurityList")
 	}
 
-	table.Render()
+	return nil
 }
@@ -0,0 +1,183 @@
+package application
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/rs/zerolog"
+
+	"github.com/quickfixgo/enum"
+	"github.com/quickfixgo/field"
+	"github.com/quickfixgo/quickfix"
+	"github.com/quickfixgo/quickfix/config"
+	"github.com/quickfixgo/tag"
+)
+
+func TestOnMarketDataRequestReject(t *testing.T) {
+	tests := []struct {
+		name       string
+		reason     string
+		text       string
+		wantReason string
+	}{
+		{
+			name:       "unknown symbol",
+			reason:     string(enum.MDReqRejReason_UNKNOWN_SYMBOL),
+			wantReason: "0(unknown_symbol)",
+		},
+		{
+			name:       "unsupported market depth with text",
+			reason:     string(enum.MDReqRejReason_UNSUPPORTED_MARKETDEPTH),
+			text:       "depth 10 not available",
+			wantReason: "5(unsupported_marketdepth)",
+		},
+		{
+			name:       "insufficient credit",
+			reason:     string(enum.MDReqRejReason_INSUFFICIENT_CREDIT),
+			wantReason: "D(insufficient_credit)",
+		},
+		{
+			name:       "venue specific reason",
+			reason:     "99",
+			wantReason: "99",
+		},
+		{
+			name: "no reason",
+			text: "rejected",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			logger := zerolog.New(buf)
+
+			app := NewMarketDataRequest(false, 1)
+			app.Logger = &logger
+
+			msg := quickfix.NewMessage()
+			msg.Header.Set(field.NewMsgType(enum.MsgType_MARKET_DATA_REQUEST_REJECT))
+			msg.Body.Set(field.NewMDReqID("req-1"))
+			if len(tt.reason) > 0 {
+				msg.Body.SetString(tag.MDReqRejReason, tt.reason)
+			}
+			if len(tt.text) > 0 {
+				msg.Body.Set(field.NewText(tt.text))
+			}
+
+			if rej := app.onMarketDataRequestReject(msg, quickfix.SessionID{}); rej != nil {
+				t.Fatalf("onMarketDataRequestReject() = %v", rej)
+			}
+
+			logged := map[string]string{}
+			if err := json.Unmarshal(buf.Bytes(), &logged); err != nil {
+				t.Fatalf("unable to decode log line %q: %s", buf, err)
+			}
+
+			want := map[string]string{
+				"level":   "warn",
+				"message": "MarketDataRequest rejected",
+				"mdreqid": "req-1",
+			}
+			if len(tt.wantReason) > 0 {
+				want["reason"] = tt.wantReason
+			}
+			if len(tt.text) > 0 {
+				want["text"] = tt.text
+			}
+
+			for k, v := range want {
+				if logged[k] != v {
+					t.Errorf("logged %s = %q, want %q", k, logged[k], v)
+				}
+			}
+			if _, ok := logged["reason"]; ok && len(tt.wantReason) == 0 {
+				t.Errorf("logged reason = %q, want none", logged["reason"])
+			}
+
+			select {
+			case m := <-app.FromAppMessages:
+				if m != msg {
+					t.Errorf("FromAppMessages received %v, want the reject", m)
+				}
+			default:
+				t.Errorf("reject not relayed to FromAppMessages")
+			}
+		})
+	}
+}
+
+func TestLogonCredentials(t *testing.T) {
+	tests := []struct {
+		name         string
+		settings     map[string]string
+		wantUsername string
+		wantPassword string
+	}{
+		{
+			name:         "username and password",
+			settings:     map[string]string{"Username": "user", "Password": "secret"},
+			wantUsername: "user",
+			wantPassword: "secret",
+		},
+		{
+			name:     "empty password",
+			settings: map[string]string{"Username": "user", "Password": ""},
+			// Validated by initiator.ValidateOptions, only set values are sent
+			wantUsername: "user",
+		},
+		{
+			name: "no credentials",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger := zerolog.Nop()
+
+			session := quickfix.NewSessionSettings()
+			session.Set(config.BeginString, quickfix.BeginStringFIXT11)
+			session.Set(config.SenderCompID, "CLIENT")
+			session.Set(config.TargetCompID, "VENUE")
+			for k, v := range tt.settings {
+				session.Set(k, v)
+			}
+
+			settings := quickfix.NewSettings()
+			sessionID, err := settings.AddSession(session)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			app := NewMarketDataRequest(false, 1)
+			app.Logger = &logger
+			app.Settings = settings
+
+			logon := quickfix.NewMessage()
+			logon.Header.Set(field.NewMsgType(enum.MsgType_LOGON))
+			logon.Header.Set(field.NewMsgSeqNum(1))
+
+			app.ToAdmin(logon, sessionID)
+
+			for _, f := range []struct {
+				tag  quickfix.Tag
+				want string
+			}{
+				{tag.Username, tt.wantUsername},
+				{tag.Password, tt.wantPassword},
+			} {
+				got, err := logon.Header.GetString(f.tag)
+				if len(f.want) == 0 {
+					if err == nil {
+						t.Errorf("tag %d = %q, want it unset", f.tag, got)
+					}
+					continue
+				}
+				if got != f.want {
+					t.Errorf("tag %d = %q, want %q", f.tag, got, f.want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package application
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// DefaultAppChanBuffer is the default size of the buffer of the channels
+// relaying the application messages received to the commands.
+const DefaultAppChanBuffer = 1024
+
+var (
+	metricAppChanDepth = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "fix",
+			Subsystem: "application",
+			Name:      "chan_depth",
+			Help:      "Number of application messages waiting in the channel to be processed",
+		},
+		[]string{"application"},
+	)
+	metricAppChanCapacity = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "fix",
+			Subsystem: "application",
+			Name:      "chan_capacity",
+			Help:      "Size of the buffer of the application message channel",
+		},
+		[]string{"application"},
+	)
+	metricAppChanFull = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "fix",
+			Subsystem: "application",
+			Name:      "chan_full_total",
+			Help:      "Number of application messages which found the channel full, blocking quickfix until processed",
+		},
+		[]string{"application"},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(metricAppChanDepth)
+	prometheus.MustRegister(metricAppChanCapacity)
+	prometheus.MustRegister(metricAppChanFull)
+}
@@ -2,6 +2,10 @@ package initiator
 
 import (
 	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 
@@ -23,6 +27,11 @@ func ValidateOptions(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	err = conf.ExpandCompIDs(time.Now())
+	if err != nil {
+		return err
+	}
+
 	// Retrieve the global config pointer
 	fixConfig := config.GetConfig()
 
@@ -63,6 +72,23 @@ func ValidateOptions(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	if err := context.ApplyEnumOverrides(); err != nil {
+		return err
+	}
+
+	// Make it clear that the venue gets non-standard enum values
+	if logger := config.GetLogger(); logger != nil {
+		fields := make([]string, 0, len(context.EnumOverrides))
+		for field := range context.EnumOverrides {
+			fields = append(fields, field)
+		}
+		sort.Strings(fields)
+
+		for _, field := range fields {
+			logger.Warn().Str("field", field).Interface("values", context.EnumOverrides[field]).Msg("Enum override active")
+		}
+	}
+
 	sessions, err := context.GetSessions()
 	if err != nil {
 		return err
@@ -77,6 +103,76 @@ func ValidateOptions(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	// Resolve the session password from a file or an environment variable so
+	// that it does not have to be stored in the configuration
+	switch {
+	case len(options.PasswordFile) > 0 && len(options.PasswordEnv) > 0:
+		return fmt.Errorf("%w: --password-file and --password-env can't be used together", errors.OptionsInconsistentValues)
+	case len(options.PasswordFile) > 0:
+		b, err := os.ReadFile(options.PasswordFile)
+		if err != nil {
+			return fmt.Errorf("%w: %s", errors.Options, err)
+		}
+		sessions[0].Password = strings.TrimRight(string(b), "\r\n")
+	case len(options.PasswordEnv) > 0:
+		password, ok := os.LookupEnv(options.PasswordEnv)
+		if !ok {
+			return fmt.Errorf("%w: environment variable %s not set", errors.Options, options.PasswordEnv)
+		}
+		sessions[0].Password = password
+	}
+
+	if (len(sessions[0].Username) > 0) != (len(sessions[0].Password) > 0) {
+		return fmt.Errorf("%w: session %s: Username and Password must be set together", errors.Config, sessions[0].Name)
+	}
+
+	if options.ReconnectInterval < 0 {
+		return fmt.Errorf("%w: --reconnect-interval must be positive", errors.Options)
+	}
+
+	if len(options.SendingTime) > 0 {
+		if _, err := parseSendingTime(options.SendingTime); err != nil {
+			return err
+		}
+	}
+
+	if options.SelfDescribingTag < 0 {
+		return fmt.Errorf("%w: --self-describing-tag can't be negative", errors.Options)
+	}
+
+	if options.AutoApplVerID && len(options.DefaultApplVerID) > 0 {
+		return fmt.Errorf("%w: --auto-appl-ver can't be used with --default-appl-ver-id", errors.OptionsInconsistentValues)
+	}
+
+	// Override transport and application versions
+	if len(options.BeginString) > 0 || len(options.DefaultApplVerID) > 0 {
+		if len(options.BeginString) > 0 {
+			sessions[0].BeginString = options.BeginString
+		}
+		if len(options.DefaultApplVerID) > 0 {
+			sessions[0].DefaultApplVerID = options.DefaultApplVerID
+		}
+
+		if err := config.ValidateVersions(sessions[0].BeginString, sessions[0].DefaultApplVerID); err != nil {
+			return err
+		}
+	}
+
+	// Override data dictionaries with the ones given on the command line
+	if len(options.TransportDict) > 0 {
+		if _, err := config.ParseFIXDictionary(options.TransportDict); err != nil {
+			return fmt.Errorf("%w: --transport-dict: %s", errors.Options, err)
+		}
+		sessions[0].TransportDataDictionary = options.TransportDict
+	}
+
+	if len(options.AppDict) > 0 {
+		if _, err := config.ParseFIXDictionary(options.AppDict); err != nil {
+			return fmt.Errorf("%w: --app-dict: %s", errors.Options, err)
+		}
+		sessions[0].AppDataDictionary = options.AppDict
+	}
+
 	return nil
 }
 
@@ -87,7 +183,19 @@ func AddPersistentFlags(cmd *cobra.Command) {
 	cmd.PersistentFlags().StringVar(&options.Initiator, "initiator", "", "Initiator to use (can't be used with --context)")
 	cmd.PersistentFlags().StringVar(&options.Session, "session", "", "Session to use (can't be used with --context)")
 	cmd.PersistentFlags().DurationVar(&options.Timeout, "timeout", 0, "Duration for timeouts")
+	cmd.PersistentFlags().DurationVar(&options.ReconnectInterval, "reconnect-interval", 0, "Time between attempts to reconnect a dropped connection, overriding the session's one (default 30s)")
 	cmd.PersistentFlags().BoolVar(&options.QuickFixLogging, "quickfix-logging", false, "Enable quickfix logging")
+	cmd.PersistentFlags().BoolVar(&options.ShowFraming, "show-framing", false, "Log the BodyLength and CheckSum of sent and received messages and whether they are valid")
+	cmd.PersistentFlags().StringVar(&options.BeginString, "begin-string", "", "Transport version (BeginString) overriding the session's one")
+	cmd.PersistentFlags().StringVar(&options.DefaultApplVerID, "default-appl-ver-id", "", "Application version (DefaultApplVerID) overriding the session's one")
+	cmd.PersistentFlags().StringVar(&options.TransportDict, "transport-dict", "", "Transport data dictionary file overriding the session's one")
+	cmd.PersistentFlags().BoolVar(&options.SelfDescribing, "self-describing", true, "Stamp the tool name and version in outgoing messages, disable with --self-describing=false")
+	cmd.PersistentFlags().IntVar(&options.SelfDescribingTag, "self-describing-tag", 0, "Custom header tag used to stamp the tool name and version (0 uses ApplicationSystemName/Version on Logon when supported)")
+	cmd.PersistentFlags().StringVar(&options.SendingTime, "sending-time", "", "SendingTime set on the app messages sent (RFC3339 or now for the start of the command), testing aid for reproducible captures")
+	cmd.PersistentFlags().StringVar(&options.LogoutText, "logout-text", "", "Text reason sent in the Logout message on shutdown")
+	cmd.PersistentFlags().StringVar(&options.AppDict, "app-dict", "", "Application data dictionary file overriding the session's one")
+	cmd.PersistentFlags().StringVar(&options.PasswordFile, "password-file", "", "File holding the session password sent on Logon, overriding the session's one")
+	cmd.PersistentFlags().StringVar(&options.PasswordEnv, "password-env", "", "Environment variable holding the session password sent on Logon, overriding the session's one")
 }
 
 func AddPersistentFlagCompletions(cmd *cobra.Command) error {
@@ -103,5 +211,13 @@ func AddPersistentFlagCompletions(cmd *cobra.Command) error {
 		return err
 	}
 
+	if err := cmd.RegisterFlagCompletionFunc("begin-string", complete.BeginStrings); err != nil {
+		return err
+	}
+
+	if err := cmd.RegisterFlagCompletionFunc("default-appl-ver-id", complete.ApplVerIDs); err != nil {
+		return err
+	}
+
 	return nil
 }
@@ -0,0 +1,162 @@
+package initiator
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"sylr.dev/fix/config"
+	"sylr.dev/fix/pkg/errors"
+)
+
+const testConfig = `initiators:
+  - name: initiator
+    SocketConnectHost: 127.0.0.1
+    SocketConnectPort: 5001
+sessions:
+  - name: session
+    BeginString: FIXT.1.1
+    DefaultApplVerID: FIX.5.0SP2
+    SenderCompID: CLIENT
+    TargetCompID: VENUE
+%s
+contexts:
+  - name: context
+    initiator: initiator
+    sessions: [session]
+current-context: context
+`
+
+// validateOptions runs ValidateOptions against the configuration made of
+// testConfig completed with the given session settings, with the options
+// changed by set. It returns the session of the context.
+func validateOptions(t *testing.T, sessionSettings string, set func()) (*config.Session, error) {
+	t.Helper()
+
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+
+	path := filepath.Join(dir, "config")
+	if err := os.WriteFile(path, []byte(fmt.Sprintf(testConfig, sessionSettings)), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	options, fixConfig := config.GetOptions(), config.GetConfig()
+	oldOptions, oldConfig := *options, *fixConfig
+	t.Cleanup(func() {
+		*options, *fixConfig = oldOptions, oldConfig
+	})
+
+	options.Config = path
+	options.Interactive = false
+	if set != nil {
+		set()
+	}
+
+	if err := ValidateOptions(nil, nil); err != nil {
+		return nil, err
+	}
+
+	return config.GetSession("session")
+}
+
+func TestValidateOptionsCredentials(t *testing.T) {
+	passwordFile := filepath.Join(t.TempDir(), "password")
+	if err := os.WriteFile(passwordFile, []byte("from-file\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name         string
+		credentials  string
+		passwordFile string
+		passwordEnv  string
+		env          map[string]string
+		wantUsername string
+		wantPassword string
+		wantErr      error
+	}{
+		{
+			name: "none",
+		},
+		{
+			name:         "from the configuration",
+			credentials:  "    Username: user\n    Password: from-config",
+			wantUsername: "user",
+			wantPassword: "from-config",
+		},
+		{
+			name:         "from a file",
+			credentials:  "    Username: user",
+			passwordFile: passwordFile,
+			wantUsername: "user",
+			wantPassword: "from-file",
+		},
+		{
+			name:         "from the environment",
+			credentials:  "    Username: user\n    Password: from-config",
+			passwordEnv:  "FIX_TEST_PASSWORD",
+			env:          map[string]string{"FIX_TEST_PASSWORD": "from-env"},
+			wantUsername: "user",
+			wantPassword: "from-env",
+		},
+		{
+			name:         "file and environment",
+			credentials:  "    Username: user",
+			passwordFile: passwordFile,
+			passwordEnv:  "FIX_TEST_PASSWORD",
+			env:          map[string]string{"FIX_TEST_PASSWORD": "from-env"},
+			wantErr:      errors.OptionsInconsistentValues,
+		},
+		{
+			name:         "missing file",
+			credentials:  "    Username: user",
+			passwordFile: filepath.Join(t.TempDir(), "missing"),
+			wantErr:      errors.Options,
+		},
+		{
+			name:        "unset environment variable",
+			credentials: "    Username: user",
+			passwordEnv: "FIX_TEST_PASSWORD_UNSET",
+			wantErr:     errors.Options,
+		},
+		{
+			name:        "username without password",
+			credentials: "    Username: user",
+			wantErr:     errors.Config,
+		},
+		{
+			name:        "password without username",
+			credentials: "    Password: from-config",
+			wantErr:     errors.Config,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+
+			session, err := validateOptions(t, tt.credentials, func() {
+				config.GetOptions().PasswordFile = tt.passwordFile
+				config.GetOptions().PasswordEnv = tt.passwordEnv
+			})
+
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("ValidateOptions() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ValidateOptions() error = %v", err)
+			}
+
+			if session.Username != tt.wantUsername || session.Password != tt.wantPassword {
+				t.Errorf("credentials = %q/%q, want %q/%q", session.Username, session.Password, tt.wantUsername, tt.wantPassword)
+			}
+		})
+	}
+}
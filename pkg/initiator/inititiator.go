@@ -5,6 +5,7 @@ import (
 
 	"github.com/quickfixgo/quickfix"
 
+	"sylr.dev/fix/config"
 	"sylr.dev/fix/pkg/utils"
 )
 
@@ -27,5 +28,40 @@ func Initiate(app quickfix.Application, settings *quickfix.Settings, logger *zer
 		msgStoreFactory = quickfix.NewMemoryStoreFactory()
 	}
 
-	return quickfix.NewInitiator(app, msgStoreFactory, settings, utils.NewQuickFixLogFactory(logger))
+	logFactory := utils.NewQuickFixLogFactory(logger)
+	if observer, ok := app.(utils.QuickFixEventObserver); ok {
+		logFactory = utils.NewQuickFixObservedLogFactory(logger, observer)
+	}
+
+	options := config.GetOptions()
+
+	if options.ShowFraming {
+		logFactory = utils.NewQuickFixFramingLogFactory(logFactory, config.GetLogger())
+	}
+
+	if options.AutoApplVerID {
+		app = newApplVerIDApplication(app)
+	}
+
+	if len(options.SendingTime) > 0 {
+		sendingTime, err := parseSendingTime(options.SendingTime)
+		if err != nil {
+			return nil, err
+		}
+		app = newSendingTimeApplication(app, sendingTime)
+	}
+
+	if len(options.LogoutText) > 0 {
+		app = newLogoutTextApplication(app, options.LogoutText)
+	}
+
+	if options.SelfDescribing {
+		app = newSelfDescribingApplication(app, settings, quickfix.Tag(options.SelfDescribingTag))
+	}
+
+	if len(options.OTelEndpoint) > 0 {
+		app = newTracingApplication(app)
+	}
+
+	return quickfix.NewInitiator(app, msgStoreFactory, settings, logFactory)
 }
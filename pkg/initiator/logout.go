@@ -0,0 +1,31 @@
+package initiator
+
+import (
+	"github.com/quickfixgo/enum"
+	"github.com/quickfixgo/field"
+	"github.com/quickfixgo/quickfix"
+	"github.com/quickfixgo/tag"
+)
+
+// logoutTextApplication wraps a quickfix.Application and sets a Text reason on
+// outgoing Logout messages which do not already carry one.
+type logoutTextApplication struct {
+	quickfix.Application
+
+	text string
+}
+
+func newLogoutTextApplication(app quickfix.Application, text string) *logoutTextApplication {
+	return &logoutTextApplication{
+		Application: app,
+		text:        text,
+	}
+}
+
+func (app *logoutTextApplication) ToAdmin(message *quickfix.Message, sessionID quickfix.SessionID) {
+	if message.IsMsgTypeOf(string(enum.MsgType_LOGOUT)) && !message.Body.Has(tag.Text) {
+		message.Body.Set(field.NewText(app.text))
+	}
+
+	app.Application.ToAdmin(message, sessionID)
+}
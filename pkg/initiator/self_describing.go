@@ -0,0 +1,97 @@
+package initiator
+
+import (
+	"github.com/quickfixgo/enum"
+	"github.com/quickfixgo/field"
+	"github.com/quickfixgo/quickfix"
+	qconfig "github.com/quickfixgo/quickfix/config"
+	"github.com/quickfixgo/tag"
+
+	"sylr.dev/fix/config"
+)
+
+const applicationSystemName = "fix"
+
+// selfDescribingApplication wraps a quickfix.Application and stamps the tool
+// name and version in outgoing messages.
+//
+// If tag is set, "fix/<version>" is written in this header tag of every
+// outgoing message. Otherwise ApplicationSystemName and ApplicationSystemVersion
+// are set on Logon messages if the transport dictionary supports them.
+type selfDescribingApplication struct {
+	quickfix.Application
+
+	tag quickfix.Tag
+
+	// applicationSystem holds the sessions whose transport dictionary defines
+	// ApplicationSystemName in the Logon message. It is resolved before the
+	// session starts as quickfix calls the application from its own goroutine.
+	applicationSystem map[quickfix.SessionID]bool
+}
+
+func newSelfDescribingApplication(app quickfix.Application, settings *quickfix.Settings, stampTag quickfix.Tag) *selfDescribingApplication {
+	sda := &selfDescribingApplication{
+		Application:       app,
+		tag:               stampTag,
+		applicationSystem: make(map[quickfix.SessionID]bool),
+	}
+
+	if stampTag == 0 {
+		for sessionID, session := range settings.SessionSettings() {
+			sda.applicationSystem[sessionID] = supportsApplicationSystem(session)
+		}
+	}
+
+	return sda
+}
+
+func (app *selfDescribingApplication) ToAdmin(message *quickfix.Message, sessionID quickfix.SessionID) {
+	app.stamp(message, sessionID)
+	app.Application.ToAdmin(message, sessionID)
+}
+
+func (app *selfDescribingApplication) ToApp(message *quickfix.Message, sessionID quickfix.SessionID) error {
+	app.stamp(message, sessionID)
+	return app.Application.ToApp(message, sessionID)
+}
+
+func (app *selfDescribingApplication) stamp(message *quickfix.Message, sessionID quickfix.SessionID) {
+	if app.tag > 0 {
+		message.Header.SetString(app.tag, applicationSystemName+"/"+config.GetVersion())
+		return
+	}
+
+	if !message.IsMsgTypeOf(string(enum.MsgType_LOGON)) || !app.applicationSystem[sessionID] {
+		return
+	}
+
+	message.Body.Set(field.NewApplicationSystemName(applicationSystemName))
+	message.Body.Set(field.NewApplicationSystemVersion(config.GetVersion()))
+}
+
+// supportsApplicationSystem returns true if the transport dictionary of the
+// session defines ApplicationSystemName in the Logon message.
+func supportsApplicationSystem(session *quickfix.SessionSettings) bool {
+	if !session.HasSetting(qconfig.TransportDataDictionary) {
+		return false
+	}
+
+	path, err := session.Setting(qconfig.TransportDataDictionary)
+	if err != nil || len(path) == 0 {
+		return false
+	}
+
+	dd, err := config.ParseFIXDictionary(path)
+	if err != nil {
+		return false
+	}
+
+	logon, ok := dd.Messages[string(enum.MsgType_LOGON)]
+	if !ok {
+		return false
+	}
+
+	_, ok = logon.Fields[int(tag.ApplicationSystemName)]
+
+	return ok
+}
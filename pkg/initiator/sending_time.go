@@ -0,0 +1,47 @@
+package initiator
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/quickfixgo/quickfix"
+	"github.com/quickfixgo/tag"
+
+	"sylr.dev/fix/pkg/errors"
+)
+
+// sendingTimeApplication wraps a quickfix.Application and replaces the
+// SendingTime quickfix sets on outgoing app messages. Admin messages keep the
+// current clock so that the session is not rejected by the acceptor.
+type sendingTimeApplication struct {
+	quickfix.Application
+
+	sendingTime time.Time
+}
+
+func newSendingTimeApplication(app quickfix.Application, sendingTime time.Time) *sendingTimeApplication {
+	return &sendingTimeApplication{
+		Application: app,
+		sendingTime: sendingTime,
+	}
+}
+
+func (app *sendingTimeApplication) ToApp(message *quickfix.Message, sessionID quickfix.SessionID) error {
+	message.Header.SetField(tag.SendingTime, quickfix.FIXUTCTimestamp{Time: app.sendingTime})
+
+	return app.Application.ToApp(message, sessionID)
+}
+
+// parseSendingTime parses --sending-time, `now` being the time it is parsed.
+func parseSendingTime(raw string) (time.Time, error) {
+	if raw == "now" {
+		return time.Now().UTC(), nil
+	}
+
+	t, err := time.Parse(time.RFC3339Nano, raw)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: invalid --sending-time `%s`, expecting RFC3339 or now", errors.Options, raw)
+	}
+
+	return t.UTC(), nil
+}
@@ -0,0 +1,129 @@
+package initiator
+
+import (
+	"sync"
+
+	"github.com/quickfixgo/enum"
+	"github.com/quickfixgo/quickfix"
+	"github.com/quickfixgo/tag"
+
+	"sylr.dev/fix/pkg/tracing"
+)
+
+// tracingApplication wraps a quickfix.Application and records the connect,
+// logon, send and receive spans of the sessions.
+type tracingApplication struct {
+	quickfix.Application
+
+	connects map[quickfix.SessionID]*tracing.Span
+	logons   map[quickfix.SessionID]*tracing.Span
+	mux      sync.Mutex
+}
+
+func newTracingApplication(app quickfix.Application) *tracingApplication {
+	return &tracingApplication{
+		Application: app,
+		connects:    make(map[quickfix.SessionID]*tracing.Span),
+		logons:      make(map[quickfix.SessionID]*tracing.Span),
+	}
+}
+
+func (app *tracingApplication) OnCreate(sessionID quickfix.SessionID) {
+	app.mux.Lock()
+	app.connects[sessionID] = tracing.StartSpan("connect", "fix.session_id", sessionID.String())
+	app.mux.Unlock()
+
+	app.Application.OnCreate(sessionID)
+}
+
+func (app *tracingApplication) OnLogon(sessionID quickfix.SessionID) {
+	app.mux.Lock()
+	app.logons[sessionID].End()
+	app.mux.Unlock()
+
+	app.Application.OnLogon(sessionID)
+}
+
+func (app *tracingApplication) ToAdmin(message *quickfix.Message, sessionID quickfix.SessionID) {
+	// The Logon is only sent once the transport is established
+	if message.IsMsgTypeOf(string(enum.MsgType_LOGON)) {
+		app.mux.Lock()
+		app.connects[sessionID].End()
+		if span, ok := app.logons[sessionID]; !ok || span.Ended() {
+			app.logons[sessionID] = tracing.StartSpan("logon", "fix.session_id", sessionID.String())
+		}
+		app.mux.Unlock()
+	}
+
+	app.Application.ToAdmin(message, sessionID)
+}
+
+func (app *tracingApplication) FromAdmin(message *quickfix.Message, sessionID quickfix.SessionID) quickfix.MessageRejectError {
+	if message.IsMsgTypeOf(string(enum.MsgType_LOGOUT)) {
+		app.mux.Lock()
+		if span := app.logons[sessionID]; !span.Ended() {
+			text, _ := message.Body.GetString(tag.Text)
+			span.SetError(logoutError(text))
+			span.End()
+		}
+		app.mux.Unlock()
+	}
+
+	return app.Application.FromAdmin(message, sessionID)
+}
+
+func (app *tracingApplication) ToApp(message *quickfix.Message, sessionID quickfix.SessionID) error {
+	span := tracing.StartSpan("send", messageAttributes(message, sessionID)...)
+	defer span.End()
+
+	err := app.Application.ToApp(message, sessionID)
+	span.SetError(err)
+
+	return err
+}
+
+func (app *tracingApplication) FromApp(message *quickfix.Message, sessionID quickfix.SessionID) quickfix.MessageRejectError {
+	span := tracing.StartSpan("receive", messageAttributes(message, sessionID)...)
+	defer span.End()
+
+	rej := app.Application.FromApp(message, sessionID)
+	if rej != nil {
+		span.SetError(rej)
+	}
+
+	return rej
+}
+
+// tracedTags are the identifiers set as attributes of the message spans when
+// the messages carry them.
+var tracedTags = map[quickfix.Tag]string{
+	tag.MDReqID:       "fix.mdreqid",
+	tag.ClOrdID:       "fix.clordid",
+	tag.SecurityReqID: "fix.securityreqid",
+}
+
+func messageAttributes(message *quickfix.Message, sessionID quickfix.SessionID) []string {
+	attrs := []string{"fix.session_id", sessionID.String()}
+
+	if msgType, err := message.MsgType(); err == nil {
+		attrs = append(attrs, "fix.msg_type", msgType)
+	}
+
+	for t, key := range tracedTags {
+		if v, err := message.Body.GetString(t); err == nil {
+			attrs = append(attrs, key, v)
+		}
+	}
+
+	return attrs
+}
+
+type logoutError string
+
+func (e logoutError) Error() string {
+	if len(e) == 0 {
+		return "logout"
+	}
+
+	return "logout: " + string(e)
+}
@@ -0,0 +1,102 @@
+// Package testutils holds the helpers shared by the tests of the module.
+package testutils
+
+import (
+	"compress/bzip2"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"testing"
+
+	"github.com/quickfixgo/quickfix/datadictionary"
+)
+
+// Names of the data dictionaries bundled by `fix init config`.
+const (
+	TransportDictionary = "FIXT11.xml"
+	AppDictionary       = "FIX50SP2.xml"
+)
+
+var (
+	dictionaries = map[string]*datadictionary.DataDictionary{}
+	dictMux      sync.Mutex
+)
+
+// Dictionaries returns the bundled FIXT.1.1 transport and FIX.5.0SP2 app data
+// dictionaries, parsed once per test binary.
+func Dictionaries(t testing.TB) (*datadictionary.DataDictionary, *datadictionary.DataDictionary) {
+	t.Helper()
+
+	return Dictionary(t, TransportDictionary), Dictionary(t, AppDictionary)
+}
+
+// Dictionary returns the bundled data dictionary name, e.g. FIX50SP2.xml.
+func Dictionary(t testing.TB, name string) *datadictionary.DataDictionary {
+	t.Helper()
+
+	dictMux.Lock()
+	defer dictMux.Unlock()
+
+	if dd, ok := dictionaries[name]; ok {
+		return dd
+	}
+
+	r, closer := openDictionary(t, name)
+	defer closer.Close()
+
+	dd, err := datadictionary.ParseSrc(r)
+	if err != nil {
+		t.Fatalf("unable to parse %s: %s", name, err)
+	}
+	dictionaries[name] = dd
+
+	return dd
+}
+
+// DictionaryFile writes the bundled data dictionary name to a temporary
+// directory removed at the end of the test and returns its path, for the
+// settings pointing quickfix to dictionary files.
+func DictionaryFile(t testing.TB, name string) string {
+	t.Helper()
+
+	r, closer := openDictionary(t, name)
+	defer closer.Close()
+
+	path := filepath.Join(t.TempDir(), name)
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	if _, err := io.Copy(f, r); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+func openDictionary(t testing.TB, name string) (io.Reader, io.Closer) {
+	t.Helper()
+
+	f, err := os.Open(filepath.Join(templatesDir(t), name+".bz2"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return bzip2.NewReader(f), f
+}
+
+// templatesDir returns the directory of the templates of `fix init config`.
+func templatesDir(t testing.TB) string {
+	t.Helper()
+
+	_, file, _, ok := runtime.Caller(0)
+	if !ok {
+		t.Fatal("unable to locate the bundled dictionaries")
+	}
+
+	return filepath.Join(filepath.Dir(file), "..", "..", "cmd", "init", "config", "templates")
+}
@@ -0,0 +1,413 @@
+// Package tracing records the spans of a command and exports them to an
+// OpenTelemetry collector with the OTLP/HTTP JSON protocol. Ended spans are
+// exported in batches while the command runs and dropped once exported, the
+// last ones when the command ends. Nothing is recorded unless Start is called,
+// spans being nil and their methods no-ops.
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	serviceName = "fix"
+	scopeName   = "sylr.dev/fix"
+)
+
+// OTLP span kinds and status codes.
+const (
+	spanKindInternal = 1
+	spanKindClient   = 3
+
+	statusCodeOk    = 1
+	statusCodeError = 2
+)
+
+// Bounds of the spans held in memory, long running commands such as market data
+// subscriptions record a span per message received.
+var (
+	// exportInterval is the period at which the ended spans are exported.
+	exportInterval = 5 * time.Second
+	// exportBatchSize is the maximum number of spans of an export request, an
+	// export is triggered as soon as that many spans are pending.
+	exportBatchSize = 512
+	// maxPendingSpans is the maximum number of ended spans waiting to be
+	// exported, the spans ended beyond it are dropped.
+	maxPendingSpans = 4 * exportBatchSize
+)
+
+var (
+	tracer *Tracer
+	mux    sync.Mutex
+)
+
+// Tracer holds the spans of the command until they are exported.
+type Tracer struct {
+	endpoint string
+	version  string
+	client   *http.Client
+	traceID  string
+	root     *Span
+	open     map[*Span]struct{}
+	pending  []*Span
+	dropped  int
+	err      error
+	flush    chan struct{}
+	done     chan struct{}
+	stopped  chan struct{}
+	mux      sync.Mutex
+}
+
+// Span is a timed operation of the command.
+type Span struct {
+	tracer *Tracer
+	id     string
+	parent string
+	name   string
+	kind   int
+	start  time.Time
+	end    time.Time
+	attrs  map[string]string
+	err    error
+	mux    sync.Mutex
+}
+
+// Start starts tracing the command, its span being the parent of the spans
+// started afterwards. Spans are exported to the OTLP/HTTP endpoint, e.g.
+// http://localhost:4318, until Stop is called.
+func Start(endpoint string, command string, version string) {
+	mux.Lock()
+	defer mux.Unlock()
+
+	if tracer != nil {
+		return
+	}
+
+	endpoint = strings.TrimSuffix(endpoint, "/")
+	if !strings.HasSuffix(endpoint, "/v1/traces") {
+		endpoint += "/v1/traces"
+	}
+
+	tracer = &Tracer{
+		endpoint: endpoint,
+		version:  version,
+		client:   &http.Client{Timeout: 5 * time.Second},
+		traceID:  randomID(16),
+		open:     make(map[*Span]struct{}),
+		flush:    make(chan struct{}, 1),
+		done:     make(chan struct{}),
+		stopped:  make(chan struct{}),
+	}
+	tracer.root = tracer.newSpan(command, "", spanKindInternal)
+
+	go tracer.run()
+}
+
+// Stop ends the command span with err and exports the spans not exported yet.
+// Spans which are still open are ended first. It returns the first export
+// error of the command, if any.
+func Stop(ctx context.Context, err error) error {
+	mux.Lock()
+	t := tracer
+	tracer = nil
+	mux.Unlock()
+
+	if t == nil {
+		return nil
+	}
+
+	close(t.done)
+	<-t.stopped
+
+	t.mux.Lock()
+	open := make([]*Span, 0, len(t.open))
+	for span := range t.open {
+		if span != t.root {
+			open = append(open, span)
+		}
+	}
+	t.mux.Unlock()
+
+	for _, span := range open {
+		span.SetError(fmt.Errorf("not completed"))
+		span.End()
+	}
+
+	t.root.SetError(err)
+	t.root.End()
+
+	t.exportPending(ctx)
+
+	t.mux.Lock()
+	defer t.mux.Unlock()
+
+	if t.err == nil && t.dropped > 0 {
+		t.err = fmt.Errorf("%d spans dropped, more than %d spans pending export", t.dropped, maxPendingSpans)
+	}
+
+	return t.err
+}
+
+// StartSpan starts a span, child of the command span. It returns nil if the
+// command is not traced.
+func StartSpan(name string, attrs ...string) *Span {
+	mux.Lock()
+	t := tracer
+	mux.Unlock()
+
+	if t == nil {
+		return nil
+	}
+
+	span := t.newSpan(name, t.root.id, spanKindClient)
+	span.SetAttributes(attrs...)
+
+	return span
+}
+
+func (t *Tracer) newSpan(name string, parent string, kind int) *Span {
+	span := &Span{
+		tracer: t,
+		id:     randomID(8),
+		parent: parent,
+		name:   name,
+		kind:   kind,
+		start:  time.Now(),
+		attrs:  map[string]string{},
+	}
+
+	t.mux.Lock()
+	t.open[span] = struct{}{}
+	t.mux.Unlock()
+
+	return span
+}
+
+// ended queues the span for export. The command span is never dropped.
+func (t *Tracer) ended(span *Span) {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+
+	delete(t.open, span)
+
+	if len(t.pending) >= maxPendingSpans && span != t.root {
+		t.dropped++
+		return
+	}
+
+	t.pending = append(t.pending, span)
+
+	if len(t.pending) >= exportBatchSize {
+		select {
+		case t.flush <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// run exports the pending spans periodically or when a batch is full, until
+// Stop is called.
+func (t *Tracer) run() {
+	defer close(t.stopped)
+
+	ticker := time.NewTicker(exportInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-t.flush:
+		case <-t.done:
+			return
+		}
+
+		t.exportPending(context.Background())
+	}
+}
+
+// exportPending exports the pending spans in batches of exportBatchSize. The
+// spans are dropped once sent, whether the export succeeded or not, the first
+// error being kept for Stop.
+func (t *Tracer) exportPending(ctx context.Context) {
+	for {
+		t.mux.Lock()
+		n := len(t.pending)
+		if n > exportBatchSize {
+			n = exportBatchSize
+		}
+		batch := t.pending[:n:n]
+		t.pending = t.pending[n:]
+		if len(t.pending) == 0 {
+			t.pending = nil
+		}
+		t.mux.Unlock()
+
+		if len(batch) == 0 {
+			return
+		}
+
+		if err := t.export(ctx, batch); err != nil {
+			t.mux.Lock()
+			if t.err == nil {
+				t.err = err
+			}
+			t.mux.Unlock()
+		}
+	}
+}
+
+// SetAttributes sets the attributes given as key value pairs.
+func (s *Span) SetAttributes(attrs ...string) {
+	if s == nil {
+		return
+	}
+
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	for i := 0; i+1 < len(attrs); i += 2 {
+		s.attrs[attrs[i]] = attrs[i+1]
+	}
+}
+
+// SetError marks the span as failed if err is not nil.
+func (s *Span) SetError(err error) {
+	if s == nil || err == nil {
+		return
+	}
+
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	s.err = err
+}
+
+// End ends the span, ending it again has no effect.
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+
+	s.mux.Lock()
+	if !s.end.IsZero() {
+		s.mux.Unlock()
+		return
+	}
+	s.end = time.Now()
+	s.mux.Unlock()
+
+	s.tracer.ended(s)
+}
+
+// Ended reports whether the span has been ended.
+func (s *Span) Ended() bool {
+	if s == nil {
+		return true
+	}
+
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	return !s.end.IsZero()
+}
+
+func (t *Tracer) export(ctx context.Context, spans []*Span) error {
+	body, err := json.Marshal(t.request(spans))
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("unable to export spans to %s: %s", t.endpoint, resp.Status)
+	}
+
+	return nil
+}
+
+// request returns the OTLP ExportTraceServiceRequest holding the spans.
+func (t *Tracer) request(spans []*Span) otlpRequest {
+	req := otlpRequest{
+		ResourceSpans: []otlpResourceSpans{{
+			Resource: otlpResource{
+				Attributes: []otlpKeyValue{
+					newKeyValue("service.name", serviceName),
+					newKeyValue("service.version", t.version),
+				},
+			},
+			ScopeSpans: []otlpScopeSpans{{
+				Scope: otlpScope{Name: scopeName, Version: t.version},
+			}},
+		}},
+	}
+
+	scope := &req.ResourceSpans[0].ScopeSpans[0]
+	for _, span := range spans {
+		scope.Spans = append(scope.Spans, span.otlp(t.traceID))
+	}
+
+	return req
+}
+
+func (s *Span) otlp(traceID string) otlpSpan {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	span := otlpSpan{
+		TraceID:           traceID,
+		SpanID:            s.id,
+		ParentSpanID:      s.parent,
+		Name:              s.name,
+		Kind:              s.kind,
+		StartTimeUnixNano: strconv.FormatInt(s.start.UnixNano(), 10),
+		EndTimeUnixNano:   strconv.FormatInt(s.end.UnixNano(), 10),
+		Status:            otlpStatus{Code: statusCodeOk},
+	}
+
+	keys := make([]string, 0, len(s.attrs))
+	for k := range s.attrs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		span.Attributes = append(span.Attributes, newKeyValue(k, s.attrs[k]))
+	}
+
+	if s.err != nil {
+		span.Status = otlpStatus{Code: statusCodeError, Message: s.err.Error()}
+	}
+
+	return span
+}
+
+func randomID(size int) string {
+	b := make([]byte, size)
+	rand.Read(b)
+
+	return hex.EncodeToString(b)
+}
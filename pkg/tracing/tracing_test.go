@@ -0,0 +1,273 @@
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// The OTLP/HTTP JSON request as a collector decodes it.
+type collectedRequest struct {
+	ResourceSpans []struct {
+		Resource struct {
+			Attributes []collectedKeyValue `json:"attributes"`
+		} `json:"resource"`
+		ScopeSpans []struct {
+			Scope struct {
+				Name    string `json:"name"`
+				Version string `json:"version"`
+			} `json:"scope"`
+			Spans []collectedSpan `json:"spans"`
+		} `json:"scopeSpans"`
+	} `json:"resourceSpans"`
+}
+
+type collectedSpan struct {
+	TraceID           string              `json:"traceId"`
+	SpanID            string              `json:"spanId"`
+	ParentSpanID      string              `json:"parentSpanId"`
+	Name              string              `json:"name"`
+	Kind              int                 `json:"kind"`
+	StartTimeUnixNano string              `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string              `json:"endTimeUnixNano"`
+	Attributes        []collectedKeyValue `json:"attributes"`
+	Status            struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"status"`
+}
+
+type collectedKeyValue struct {
+	Key   string `json:"key"`
+	Value struct {
+		StringValue string `json:"stringValue"`
+	} `json:"value"`
+}
+
+// collector is an OTLP/HTTP endpoint recording the requests it receives.
+type collector struct {
+	*httptest.Server
+	requests []collectedRequest
+	mux      sync.Mutex
+}
+
+func newCollector(t *testing.T) *collector {
+	t.Helper()
+
+	c := &collector{}
+	c.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/v1/traces" {
+			t.Errorf("request %s %s, want POST /v1/traces", r.Method, r.URL.Path)
+		}
+		if ct := r.Header.Get("Content-Type"); ct != "application/json" {
+			t.Errorf("Content-Type = %q, want application/json", ct)
+		}
+
+		req := collectedRequest{}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("unable to decode request: %s", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		c.mux.Lock()
+		c.requests = append(c.requests, req)
+		c.mux.Unlock()
+	}))
+	t.Cleanup(c.Close)
+
+	return c
+}
+
+// spans returns the spans received along with the number of requests.
+func (c *collector) spans() ([]collectedSpan, int) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	spans := []collectedSpan{}
+	for _, req := range c.requests {
+		for _, rs := range req.ResourceSpans {
+			for _, ss := range rs.ScopeSpans {
+				spans = append(spans, ss.Spans...)
+			}
+		}
+	}
+
+	return spans, len(c.requests)
+}
+
+// setExportLimits sets the export bounds for the duration of the test.
+func setExportLimits(t *testing.T, interval time.Duration, batchSize int, maxPending int) {
+	t.Helper()
+
+	oldInterval, oldBatchSize, oldMaxPending := exportInterval, exportBatchSize, maxPendingSpans
+	t.Cleanup(func() {
+		exportInterval, exportBatchSize, maxPendingSpans = oldInterval, oldBatchSize, oldMaxPending
+	})
+
+	exportInterval, exportBatchSize, maxPendingSpans = interval, batchSize, maxPending
+}
+
+func TestExport(t *testing.T) {
+	c := newCollector(t)
+
+	Start(c.URL+"/", "fix marketdata request", "v1.2.3")
+
+	send := StartSpan("send", "fix.msg_type", "V", "fix.mdreqid", "req-1")
+	send.End()
+	StartSpan("logon")
+
+	if err := Stop(context.Background(), fmt.Errorf("boom")); err != nil {
+		t.Fatalf("Stop() error = %v", err)
+	}
+
+	c.mux.Lock()
+	if len(c.requests) != 1 {
+		c.mux.Unlock()
+		t.Fatalf("received %d requests, want 1", len(c.requests))
+	}
+	req := c.requests[0]
+	c.mux.Unlock()
+
+	if len(req.ResourceSpans) != 1 || len(req.ResourceSpans[0].ScopeSpans) != 1 {
+		t.Fatalf("request = %+v, want one resource and one scope", req)
+	}
+
+	resource := map[string]string{}
+	for _, kv := range req.ResourceSpans[0].Resource.Attributes {
+		resource[kv.Key] = kv.Value.StringValue
+	}
+	if resource["service.name"] != "fix" || resource["service.version"] != "v1.2.3" {
+		t.Errorf("resource attributes = %v", resource)
+	}
+
+	scope := req.ResourceSpans[0].ScopeSpans[0]
+	if scope.Scope.Name != scopeName || scope.Scope.Version != "v1.2.3" {
+		t.Errorf("scope = %+v", scope.Scope)
+	}
+
+	spans := map[string]collectedSpan{}
+	for _, span := range scope.Spans {
+		spans[span.Name] = span
+	}
+	if len(spans) != 3 {
+		t.Fatalf("spans = %+v, want the command, send and logon spans", scope.Spans)
+	}
+
+	root := spans["fix marketdata request"]
+	if len(root.TraceID) != 32 || len(root.SpanID) != 16 || len(root.ParentSpanID) != 0 {
+		t.Errorf("command span ids = %q %q %q", root.TraceID, root.SpanID, root.ParentSpanID)
+	}
+	if root.Kind != spanKindInternal || root.Status.Code != statusCodeError || root.Status.Message != "boom" {
+		t.Errorf("command span = %+v", root)
+	}
+
+	for _, span := range scope.Spans {
+		if span.TraceID != root.TraceID {
+			t.Errorf("%s traceId = %q, want %q", span.Name, span.TraceID, root.TraceID)
+		}
+		if span.Name != root.Name && (span.ParentSpanID != root.SpanID || span.Kind != spanKindClient) {
+			t.Errorf("%s = %+v, want a client child of the command span", span.Name, span)
+		}
+		if span.StartTimeUnixNano > span.EndTimeUnixNano || len(span.StartTimeUnixNano) < 19 {
+			t.Errorf("%s times = %s-%s", span.Name, span.StartTimeUnixNano, span.EndTimeUnixNano)
+		}
+	}
+
+	if got := spans["send"]; got.Status.Code != statusCodeOk || len(got.Attributes) != 2 ||
+		got.Attributes[0].Key != "fix.mdreqid" || got.Attributes[0].Value.StringValue != "req-1" ||
+		got.Attributes[1].Key != "fix.msg_type" || got.Attributes[1].Value.StringValue != "V" {
+		t.Errorf("send span = %+v", got)
+	}
+	if got := spans["logon"]; got.Status.Code != statusCodeError || got.Status.Message != "not completed" {
+		t.Errorf("logon span = %+v, want it failed", got)
+	}
+}
+
+func TestExportBatches(t *testing.T) {
+	setExportLimits(t, time.Hour, 2, 100)
+	c := newCollector(t)
+
+	Start(c.URL, "fix", "")
+
+	for i := 0; i < 5; i++ {
+		StartSpan("receive").End()
+	}
+
+	// A full batch is exported without waiting for the end of the command
+	for deadline := time.Now().Add(5 * time.Second); ; time.Sleep(10 * time.Millisecond) {
+		if _, n := c.spans(); n > 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("no spans exported before Stop")
+		}
+	}
+
+	if err := Stop(context.Background(), nil); err != nil {
+		t.Fatalf("Stop() error = %v", err)
+	}
+
+	c.mux.Lock()
+	for i, req := range c.requests {
+		if n := len(req.ResourceSpans[0].ScopeSpans[0].Spans); n > 2 {
+			t.Errorf("request %d holds %d spans, want at most 2", i, n)
+		}
+	}
+	c.mux.Unlock()
+
+	spans, _ := c.spans()
+	ids := map[string]bool{}
+	for _, span := range spans {
+		ids[span.SpanID] = true
+	}
+	if len(spans) != 6 || len(ids) != 6 {
+		t.Errorf("exported %d spans with %d ids, want the 5 spans and the command span once", len(spans), len(ids))
+	}
+}
+
+func TestDroppedSpans(t *testing.T) {
+	setExportLimits(t, time.Hour, 100, 3)
+	c := newCollector(t)
+
+	Start(c.URL, "fix", "")
+
+	for i := 0; i < 5; i++ {
+		StartSpan("receive").End()
+	}
+
+	err := Stop(context.Background(), nil)
+	if err == nil || !strings.Contains(err.Error(), "2 spans dropped") {
+		t.Errorf("Stop() error = %v, want 2 spans dropped", err)
+	}
+
+	// The command span is exported even though the limit is reached
+	spans, _ := c.spans()
+	if len(spans) != 4 || spans[3].Name != "fix" {
+		t.Errorf("exported %+v, want 3 spans and the command span", spans)
+	}
+}
+
+func TestNotStarted(t *testing.T) {
+	span := StartSpan("send")
+	if span != nil {
+		t.Fatalf("StartSpan() = %v, want nil", span)
+	}
+
+	span.SetAttributes("key", "value")
+	span.SetError(fmt.Errorf("error"))
+	span.End()
+
+	if !span.Ended() {
+		t.Error("Ended() = false, want true")
+	}
+	if err := Stop(context.Background(), nil); err != nil {
+		t.Errorf("Stop() error = %v", err)
+	}
+}
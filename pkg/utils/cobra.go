@@ -8,6 +8,39 @@ import (
 	"github.com/spf13/pflag"
 )
 
+// Annotations of the commands sending a typed FIX message, they are listed by
+// `fix info`.
+const (
+	AnnotationMsgType  = "fix.msgtype"
+	AnnotationVersions = "fix.versions"
+)
+
+// MessageAnnotations returns the annotations of a command sending messages of
+// type msgType for the given versions, FIXT.1.1 ones being written
+// "FIXT.1.1/<DefaultApplVerID>". No version means every version whose
+// dictionary defines the message.
+func MessageAnnotations(msgType string, versions ...string) map[string]string {
+	return map[string]string{
+		AnnotationMsgType:  msgType,
+		AnnotationVersions: strings.Join(versions, ","),
+	}
+}
+
+// MessageCommands returns the commands under root annotated with
+// MessageAnnotations.
+func MessageCommands(root *cobra.Command) []*cobra.Command {
+	commands := []*cobra.Command{}
+
+	for _, c := range root.Commands() {
+		if _, ok := c.Annotations[AnnotationMsgType]; ok {
+			commands = append(commands, c)
+		}
+		commands = append(commands, MessageCommands(c)...)
+	}
+
+	return commands
+}
+
 // MakePersistentPreRunE returns a PersistentPreRunE function.
 func MakePersistentPreRunE(validator func(*cobra.Command, []string) error) func(*cobra.Command, []string) error {
 	return func(cmd *cobra.Command, args []string) error {
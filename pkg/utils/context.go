@@ -0,0 +1,16 @@
+package utils
+
+import (
+	"context"
+	"time"
+)
+
+// WithMaxRuntime returns a copy of parent which is cancelled once d has
+// elapsed. If d is zero the returned context is only cancelled by cancel.
+func WithMaxRuntime(parent context.Context, d time.Duration) (context.Context, context.CancelFunc) {
+	if d <= 0 {
+		return context.WithCancel(parent)
+	}
+
+	return context.WithTimeout(parent, d)
+}
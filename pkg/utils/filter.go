@@ -0,0 +1,294 @@
+package utils
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Filter is a boolean expression evaluated against a set of named fields, e.g.
+// `symbol==EUR/USD && (type==bid || type==offer) && price>1.05`.
+//
+// Supported operators are ==, !=, <, <=, >, >=, &&, ||, ! and parentheses.
+// Values are compared as numbers when both sides are numbers and as case
+// insensitive strings otherwise. Values containing spaces or operators must
+// be quoted.
+type Filter struct {
+	root filterNode
+}
+
+type filterNode interface {
+	eval(fields map[string]string) bool
+}
+
+type filterAnd struct{ left, right filterNode }
+type filterOr struct{ left, right filterNode }
+type filterNot struct{ node filterNode }
+type filterCmp struct {
+	field string
+	op    string
+	value string
+}
+
+func (n filterAnd) eval(fields map[string]string) bool {
+	return n.left.eval(fields) && n.right.eval(fields)
+}
+
+func (n filterOr) eval(fields map[string]string) bool {
+	return n.left.eval(fields) || n.right.eval(fields)
+}
+
+func (n filterNot) eval(fields map[string]string) bool {
+	return !n.node.eval(fields)
+}
+
+func (n filterCmp) eval(fields map[string]string) bool {
+	value := fields[n.field]
+
+	var cmp int
+	a, errA := strconv.ParseFloat(value, 64)
+	b, errB := strconv.ParseFloat(n.value, 64)
+	switch {
+	case errA == nil && errB == nil && a < b:
+		cmp = -1
+	case errA == nil && errB == nil && a > b:
+		cmp = 1
+	case errA == nil && errB == nil:
+		cmp = 0
+	default:
+		cmp = strings.Compare(strings.ToLower(value), strings.ToLower(n.value))
+	}
+
+	switch n.op {
+	case "==":
+		return cmp == 0
+	case "!=":
+		return cmp != 0
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	case ">":
+		return cmp > 0
+	default:
+		return cmp >= 0
+	}
+}
+
+// ParseFilter parses the filter expression. Field names are case insensitive,
+// underscores being ignored, and must be one of fields.
+func ParseFilter(expr string, fields []string) (*Filter, error) {
+	tokens, err := tokenizeFilter(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	known := make(map[string]struct{}, len(fields))
+	for _, f := range fields {
+		known[NormalizeFilterField(f)] = struct{}{}
+	}
+
+	p := &filterParser{tokens: tokens, known: known}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("unexpected `%s`", p.tokens[p.pos].text)
+	}
+
+	return &Filter{root: root}, nil
+}
+
+// Match evaluates the filter against the fields, missing fields being empty.
+// Field names must be normalized with NormalizeFilterField.
+func (f *Filter) Match(fields map[string]string) bool {
+	if f == nil {
+		return true
+	}
+
+	return f.root.eval(fields)
+}
+
+// NormalizeFilterField returns the normalized form of a field name.
+func NormalizeFilterField(name string) string {
+	return strings.ReplaceAll(strings.ToLower(name), "_", "")
+}
+
+type filterToken struct {
+	text   string
+	quoted bool
+}
+
+var filterOperators = []string{"&&", "||", "==", "!=", "<=", ">=", "<", ">", "!", "(", ")"}
+
+func (t filterToken) isOperator() bool {
+	return !t.quoted && Search(filterOperators, t.text) >= 0
+}
+
+func tokenizeFilter(expr string) ([]filterToken, error) {
+	tokens := []filterToken{}
+
+	for i := 0; i < len(expr); {
+		c := expr[i]
+
+		if unicode.IsSpace(rune(c)) {
+			i++
+			continue
+		}
+
+		if c == '"' || c == '\'' {
+			end := strings.IndexByte(expr[i+1:], c)
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated string at position %d", i)
+			}
+			tokens = append(tokens, filterToken{text: expr[i+1 : i+1+end], quoted: true})
+			i += end + 2
+			continue
+		}
+
+		operator := ""
+		for _, op := range filterOperators {
+			if strings.HasPrefix(expr[i:], op) {
+				operator = op
+				break
+			}
+		}
+		if len(operator) > 0 {
+			tokens = append(tokens, filterToken{text: operator})
+			i += len(operator)
+			continue
+		}
+
+		start := i
+		for i < len(expr) && !unicode.IsSpace(rune(expr[i])) && !strings.ContainsRune("&|=!<>()\"'", rune(expr[i])) {
+			i++
+		}
+		if start == i {
+			return nil, fmt.Errorf("unexpected `%c` at position %d", c, i)
+		}
+		tokens = append(tokens, filterToken{text: expr[start:i]})
+	}
+
+	return tokens, nil
+}
+
+type filterParser struct {
+	tokens []filterToken
+	pos    int
+	known  map[string]struct{}
+}
+
+func (p *filterParser) peek() (filterToken, bool) {
+	if p.pos >= len(p.tokens) {
+		return filterToken{}, false
+	}
+
+	return p.tokens[p.pos], true
+}
+
+func (p *filterParser) isOperator(op string) bool {
+	t, ok := p.peek()
+	return ok && !t.quoted && t.text == op
+}
+
+func (p *filterParser) parseOr() (filterNode, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.isOperator("||") {
+		p.pos++
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = filterOr{left, right}
+	}
+
+	return left, nil
+}
+
+func (p *filterParser) parseAnd() (filterNode, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.isOperator("&&") {
+		p.pos++
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = filterAnd{left, right}
+	}
+
+	return left, nil
+}
+
+func (p *filterParser) parseUnary() (filterNode, error) {
+	switch {
+	case p.isOperator("!"):
+		p.pos++
+		node, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return filterNot{node}, nil
+
+	case p.isOperator("("):
+		p.pos++
+		node, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if !p.isOperator(")") {
+			return nil, fmt.Errorf("missing `)`")
+		}
+		p.pos++
+		return node, nil
+	}
+
+	return p.parseComparison()
+}
+
+func (p *filterParser) parseComparison() (filterNode, error) {
+	field, ok := p.peek()
+	if !ok {
+		return nil, fmt.Errorf("unexpected end of expression")
+	}
+	if field.isOperator() {
+		return nil, fmt.Errorf("unexpected `%s`", field.text)
+	}
+
+	name := NormalizeFilterField(field.text)
+	if _, ok := p.known[name]; !ok {
+		return nil, fmt.Errorf("unknown field `%s`", field.text)
+	}
+	p.pos++
+
+	op, ok := p.peek()
+	if !ok || op.quoted {
+		return nil, fmt.Errorf("missing operator after `%s`", field.text)
+	}
+	switch op.text {
+	case "==", "!=", "<", "<=", ">", ">=":
+	default:
+		return nil, fmt.Errorf("unexpected `%s` after `%s`", op.text, field.text)
+	}
+	p.pos++
+
+	value, ok := p.peek()
+	if !ok {
+		return nil, fmt.Errorf("missing value after `%s%s`", field.text, op.text)
+	}
+	if value.isOperator() {
+		return nil, fmt.Errorf("unexpected `%s` after `%s%s`", value.text, field.text, op.text)
+	}
+	p.pos++
+
+	return filterCmp{field: name, op: op.text, value: value.text}, nil
+}
@@ -0,0 +1,14 @@
+package utils
+
+import (
+	"math/rand"
+
+	"github.com/google/uuid"
+)
+
+// SeedIDs makes the UUIDs generated afterwards deterministic, the same seed
+// yielding the same sequence of UUIDs. It is meant for reproducible test
+// captures only.
+func SeedIDs(seed int64) {
+	uuid.SetRand(rand.New(rand.NewSource(seed)))
+}
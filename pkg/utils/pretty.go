@@ -5,6 +5,9 @@ import (
 	"strings"
 )
 
+// PrettyOptionValues returns the lowercased keys of input sorted, so that the
+// values listed in help messages and completions do not depend on the map
+// iteration order.
 func PrettyOptionValues[T any](input map[string]T) []string {
 	output := make([]string, 0, len(input))
 	for k := range input {
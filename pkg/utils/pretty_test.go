@@ -0,0 +1,41 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPrettyOptionValues(t *testing.T) {
+	tests := []struct {
+		name  string
+		input map[string]int
+		want  []string
+	}{
+		{
+			name:  "empty",
+			input: map[string]int{},
+			want:  []string{},
+		},
+		{
+			name:  "lowercased and sorted",
+			input: map[string]int{"OFFER": 1, "BID": 0, "TRADE": 2},
+			want:  []string{"bid", "offer", "trade"},
+		},
+		{
+			name:  "underscores",
+			input: map[string]int{"SNAPSHOT_PLUS_UPDATES": 1, "SNAPSHOT": 0},
+			want:  []string{"snapshot", "snapshot_plus_updates"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Map iteration order is random, the output must not be
+			for i := 0; i < 10; i++ {
+				if got := PrettyOptionValues(tt.input); !reflect.DeepEqual(got, tt.want) {
+					t.Fatalf("PrettyOptionValues() = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
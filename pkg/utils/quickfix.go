@@ -11,7 +11,6 @@ import (
 
 	"github.com/olekukonko/tablewriter"
 	"github.com/quickfixgo/enum"
-	qtag "github.com/quickfixgo/tag"
 	"github.com/rs/zerolog"
 	"github.com/shopspring/decimal"
 	"sylr.dev/fix/pkg/dict"
@@ -130,8 +129,8 @@ func (app *QuickFixAppMessageLogger) WriteField(w io.Writer, field quickfix.TagV
 	fieldTagDescription := "<unknown>"
 	fieldValue := field.Value()
 
-	if fieldTag == qtag.Password {
-		fieldValue = "<redacted>"
+	if IsMaskedTag(fieldTag) {
+		fieldValue = MaskValue
 	}
 
 	if app.AppDataDictionary != nil {
@@ -0,0 +1,146 @@
+package utils
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+
+	"github.com/quickfixgo/quickfix"
+	"github.com/rs/zerolog"
+)
+
+// Framing holds the BodyLength and CheckSum of a serialized message along with
+// the values computed from its bytes.
+type Framing struct {
+	BodyLength         int
+	ComputedBodyLength int
+	CheckSum           string
+	ComputedCheckSum   string
+}
+
+// BodyLengthValid tells whether the BodyLength (9) of the message matches its
+// actual length.
+func (f Framing) BodyLengthValid() bool {
+	return f.BodyLength == f.ComputedBodyLength
+}
+
+// CheckSumValid tells whether the CheckSum (10) of the message matches its
+// actual checksum.
+func (f Framing) CheckSumValid() bool {
+	return f.CheckSum == f.ComputedCheckSum
+}
+
+// ParseFraming computes the framing of the serialized message. BodyLength
+// counts the bytes following the BodyLength field up to the CheckSum field and
+// CheckSum is the sum of the bytes preceding the CheckSum field modulo 256.
+func ParseFraming(raw []byte) (Framing, error) {
+	framing := Framing{BodyLength: -1}
+
+	bodyStart := bytes.Index(raw, []byte("\x019="))
+	if bodyStart < 0 {
+		return framing, fmt.Errorf("no BodyLength field")
+	}
+	bodyLengthEnd := bytes.IndexByte(raw[bodyStart+1:], '\x01')
+	if bodyLengthEnd < 0 {
+		return framing, fmt.Errorf("truncated BodyLength field")
+	}
+	bodyLengthEnd += bodyStart + 1
+
+	if n, err := strconv.Atoi(string(raw[bodyStart+3 : bodyLengthEnd])); err == nil {
+		framing.BodyLength = n
+	}
+
+	checkSumStart := bytes.LastIndex(raw, []byte("\x0110="))
+	if checkSumStart < bodyLengthEnd {
+		// Truncated messages are reported with the length they have
+		framing.ComputedBodyLength = len(raw) - bodyLengthEnd - 1
+		framing.ComputedCheckSum = checkSum(raw)
+		return framing, fmt.Errorf("no CheckSum field")
+	}
+	checkSumStart++
+
+	framing.ComputedBodyLength = checkSumStart - bodyLengthEnd - 1
+	framing.ComputedCheckSum = checkSum(raw[:checkSumStart])
+	framing.CheckSum = string(bytes.TrimRight(raw[checkSumStart+3:], "\x01\r\n"))
+
+	return framing, nil
+}
+
+func checkSum(b []byte) string {
+	sum := 0
+	for _, c := range b {
+		sum += int(c)
+	}
+
+	return fmt.Sprintf("%03d", sum%256)
+}
+
+// framingLog is a quickfix.Log logging the framing of every message sent and
+// received on top of the wrapped log.
+type framingLog struct {
+	quickfix.Log
+	prefix string
+	logger *zerolog.Logger
+}
+
+func (l framingLog) OnIncoming(s []byte) {
+	l.Log.OnIncoming(s)
+	l.logFraming("incoming", s)
+}
+
+func (l framingLog) OnOutgoing(s []byte) {
+	l.Log.OnOutgoing(s)
+	l.logFraming("outgoing", s)
+}
+
+func (l framingLog) logFraming(direction string, s []byte) {
+	framing, err := ParseFraming(s)
+
+	event := l.logger.Info()
+	if err != nil || !framing.BodyLengthValid() || !framing.CheckSumValid() {
+		event = l.logger.Warn()
+	}
+	if err != nil {
+		event = event.Err(err)
+	}
+
+	event.
+		Str("session", l.prefix).
+		Str("direction", direction).
+		Int("body_length", framing.BodyLength).
+		Int("computed_body_length", framing.ComputedBodyLength).
+		Bool("body_length_valid", framing.BodyLengthValid()).
+		Str("checksum", framing.CheckSum).
+		Str("computed_checksum", framing.ComputedCheckSum).
+		Bool("checksum_valid", framing.CheckSumValid()).
+		Msg("Message framing")
+}
+
+type framingLogFactory struct {
+	quickfix.LogFactory
+	logger *zerolog.Logger
+}
+
+func (f framingLogFactory) Create() (quickfix.Log, error) {
+	log, err := f.LogFactory.Create()
+	if err != nil {
+		return nil, err
+	}
+
+	return framingLog{Log: log, prefix: "global", logger: f.logger}, nil
+}
+
+func (f framingLogFactory) CreateSessionLog(sessionID quickfix.SessionID) (quickfix.Log, error) {
+	log, err := f.LogFactory.CreateSessionLog(sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	return framingLog{Log: log, prefix: sessionID.String(), logger: f.logger}, nil
+}
+
+// NewQuickFixFramingLogFactory wraps factory so that the framing of every
+// message sent and received is logged to logger.
+func NewQuickFixFramingLogFactory(factory quickfix.LogFactory, logger *zerolog.Logger) quickfix.LogFactory {
+	return framingLogFactory{LogFactory: factory, logger: logger}
+}
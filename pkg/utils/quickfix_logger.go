@@ -1,30 +1,75 @@
 package utils
 
 import (
+	"bytes"
 	"fmt"
 
 	"github.com/quickfixgo/quickfix"
 	"github.com/rs/zerolog"
 )
 
+var sohChar = []byte("|")
+
+// SetSOHChar sets the delimiter rendering SOH in printed raw messages. `\x01`,
+// `\001` and `SOH` keep the SOH delimiter.
+func SetSOHChar(s string) {
+	switch s {
+	case `\x01`, `\001`, "SOH", "\x01":
+		sohChar = []byte("\x01")
+	default:
+		sohChar = []byte(s)
+	}
+}
+
+// FormatRawMessage returns the raw message with masked values and SOH
+// rendered as set by SetSOHChar.
+func FormatRawMessage(raw []byte) []byte {
+	return bytes.ReplaceAll(MaskRawMessage(raw), []byte("\x01"), sohChar)
+}
+
+// SOHDelimiter returns the delimiter rendering SOH as set by SetSOHChar.
+func SOHDelimiter() []byte {
+	return sohChar
+}
+
+// RestoreSOH returns raw with the delimiter set by SetSOHChar replaced by SOH,
+// e.g. to parse messages printed by FormatRawMessage.
+func RestoreSOH(raw []byte) []byte {
+	if len(sohChar) == 0 || bytes.ContainsRune(raw, '\x01') {
+		return raw
+	}
+
+	return bytes.ReplaceAll(raw, sohChar, []byte("\x01"))
+}
+
+// QuickFixEventObserver is implemented by applications willing to be notified
+// of the quickfix session events, e.g. to find out why a logon failed.
+type QuickFixEventObserver interface {
+	OnQuickFixEvent(event string)
+}
+
 type quickFixLog struct {
-	prefix string
-	logger *zerolog.Logger
+	prefix   string
+	logger   *zerolog.Logger
+	observer QuickFixEventObserver
 }
 
 func (l quickFixLog) OnIncoming(s []byte) {
 	if l.logger != nil {
-		l.logger.Trace().Msgf("quickfix(%s, incoming): %s", l.prefix, s)
+		l.logger.Trace().Msgf("quickfix(%s, incoming): %s", l.prefix, FormatRawMessage(s))
 	}
 }
 
 func (l quickFixLog) OnOutgoing(s []byte) {
 	if l.logger != nil {
-		l.logger.Trace().Msgf("quickfix(%s, outgoing): %s", l.prefix, s)
+		l.logger.Trace().Msgf("quickfix(%s, outgoing): %s", l.prefix, FormatRawMessage(s))
 	}
 }
 
 func (l quickFixLog) OnEvent(s string) {
+	if l.observer != nil {
+		l.observer.OnQuickFixEvent(s)
+	}
 	if l.logger != nil {
 		l.logger.Debug().Msgf("quickfix(%s, event): %s", l.prefix, s)
 	}
@@ -35,16 +80,17 @@ func (l quickFixLog) OnEventf(format string, a ...interface{}) {
 }
 
 type quickfixLogFactory struct {
-	logger *zerolog.Logger
+	logger   *zerolog.Logger
+	observer QuickFixEventObserver
 }
 
 func (q quickfixLogFactory) Create() (quickfix.Log, error) {
-	log := quickFixLog{prefix: "global", logger: q.logger}
+	log := quickFixLog{prefix: "global", logger: q.logger, observer: q.observer}
 	return log, nil
 }
 
 func (q quickfixLogFactory) CreateSessionLog(sessionID quickfix.SessionID) (quickfix.Log, error) {
-	log := quickFixLog{prefix: sessionID.String(), logger: q.logger}
+	log := quickFixLog{prefix: sessionID.String(), logger: q.logger, observer: q.observer}
 	return log, nil
 }
 
@@ -52,3 +98,9 @@ func (q quickfixLogFactory) CreateSessionLog(sessionID quickfix.SessionID) (quic
 func NewQuickFixLogFactory(logger *zerolog.Logger) quickfix.LogFactory {
 	return quickfixLogFactory{logger: logger}
 }
+
+// NewQuickFixObservedLogFactory creates an instance of LogFactory like
+// NewQuickFixLogFactory which also notifies the observer of every event.
+func NewQuickFixObservedLogFactory(logger *zerolog.Logger, observer QuickFixEventObserver) quickfix.LogFactory {
+	return quickfixLogFactory{logger: logger, observer: observer}
+}
@@ -0,0 +1,56 @@
+package utils
+
+import (
+	"bytes"
+	"strconv"
+
+	"github.com/quickfixgo/quickfix"
+	qtag "github.com/quickfixgo/tag"
+)
+
+// MaskValue replaces the values of masked tags in logged messages.
+const MaskValue = "***"
+
+var maskedTags = map[quickfix.Tag]struct{}{
+	qtag.Password:    {},
+	qtag.NewPassword: {},
+}
+
+// SetMaskedTags sets the tags whose values are masked in logged messages.
+func SetMaskedTags(tags []int) {
+	maskedTags = make(map[quickfix.Tag]struct{}, len(tags))
+	for _, t := range tags {
+		maskedTags[quickfix.Tag(t)] = struct{}{}
+	}
+}
+
+// IsMaskedTag returns true if the value of the tag must be masked in logs.
+func IsMaskedTag(t quickfix.Tag) bool {
+	_, ok := maskedTags[t]
+	return ok
+}
+
+// MaskRawMessage returns a copy of the SOH delimited raw message with the
+// values of masked tags replaced by MaskValue.
+func MaskRawMessage(raw []byte) []byte {
+	if len(maskedTags) == 0 {
+		return raw
+	}
+
+	fields := bytes.Split(raw, []byte("\x01"))
+	for i, f := range fields {
+		k, _, found := bytes.Cut(f, []byte("="))
+		if !found {
+			continue
+		}
+
+		t, err := strconv.Atoi(string(k))
+		if err != nil || !IsMaskedTag(quickfix.Tag(t)) {
+			continue
+		}
+
+		fields[i] = append(append(k[:len(k):len(k)], '='), MaskValue...)
+	}
+
+	return bytes.Join(fields, []byte("\x01"))
+}
@@ -0,0 +1,93 @@
+package utils
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/quickfixgo/enum"
+	"github.com/quickfixgo/field"
+	"github.com/quickfixgo/quickfix"
+	"github.com/quickfixgo/tag"
+)
+
+func TestMaskRawMessage(t *testing.T) {
+	logon := quickfix.NewMessage()
+	logon.Header.Set(field.NewBeginString(quickfix.BeginStringFIXT11))
+	logon.Header.Set(field.NewMsgType(enum.MsgType_LOGON))
+	logon.Header.Set(field.NewSenderCompID("CLIENT"))
+	logon.Header.Set(field.NewTargetCompID("VENUE"))
+	logon.Header.Set(field.NewMsgSeqNum(1))
+	logon.Body.Set(field.NewEncryptMethod(enum.EncryptMethod_NONE_OTHER))
+	logon.Body.Set(field.NewHeartBtInt(30))
+	logon.Body.Set(field.NewUsername("user"))
+	logon.Body.Set(field.NewPassword("s3cr=t"))
+	logon.Body.Set(field.NewNewPassword("n3w"))
+	raw := logon.String()
+
+	tests := []struct {
+		name       string
+		tags       []int
+		wantMasked []quickfix.Tag
+		wantClear  map[quickfix.Tag]string
+	}{
+		{
+			name:       "default tags",
+			tags:       []int{int(tag.Password), int(tag.NewPassword)},
+			wantMasked: []quickfix.Tag{tag.Password, tag.NewPassword},
+			wantClear:  map[quickfix.Tag]string{tag.Username: "user", tag.SenderCompID: "CLIENT"},
+		},
+		{
+			name:       "username",
+			tags:       []int{int(tag.Username)},
+			wantMasked: []quickfix.Tag{tag.Username},
+			wantClear:  map[quickfix.Tag]string{tag.Password: "s3cr=t", tag.NewPassword: "n3w"},
+		},
+		{
+			name:      "no tags",
+			tags:      []int{},
+			wantClear: map[quickfix.Tag]string{tag.Username: "user", tag.Password: "s3cr=t", tag.NewPassword: "n3w"},
+		},
+	}
+
+	old := maskedTags
+	t.Cleanup(func() { maskedTags = old })
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			SetMaskedTags(tt.tags)
+
+			input := []byte(raw)
+			masked := MaskRawMessage(input)
+
+			if string(input) != raw {
+				t.Fatalf("MaskRawMessage() modified its input")
+			}
+
+			values := map[quickfix.Tag]string{}
+			fields := strings.Split(strings.TrimSuffix(string(masked), "\x01"), "\x01")
+			for _, f := range fields {
+				k, v, _ := strings.Cut(f, "=")
+				n, err := strconv.Atoi(k)
+				if err != nil {
+					t.Fatalf("invalid field %q", f)
+				}
+				values[quickfix.Tag(n)] = v
+			}
+
+			if len(fields) != strings.Count(raw, "\x01") {
+				t.Errorf("MaskRawMessage() returned %d fields, want %d", len(fields), strings.Count(raw, "\x01"))
+			}
+			for _, tg := range tt.wantMasked {
+				if values[tg] != MaskValue {
+					t.Errorf("tag %d = %q, want %q", tg, values[tg], MaskValue)
+				}
+			}
+			for tg, want := range tt.wantClear {
+				if values[tg] != want {
+					t.Errorf("tag %d = %q, want %q", tg, values[tg], want)
+				}
+			}
+		})
+	}
+}
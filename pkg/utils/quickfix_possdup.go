@@ -0,0 +1,44 @@
+package utils
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/quickfixgo/field"
+	"github.com/quickfixgo/quickfix"
+	"github.com/quickfixgo/tag"
+
+	"sylr.dev/fix/pkg/errors"
+)
+
+// SetPossDup flags the message as a possible duplicate. Its OrigSendingTime is
+// set, unless already present, to its SendingTime or to origSendingTime if it
+// has none.
+func SetPossDup(message *quickfix.Message, origSendingTime time.Time) {
+	message.Header.Set(field.NewPossDupFlag(true))
+
+	if message.Header.Has(tag.OrigSendingTime) {
+		return
+	}
+
+	if sendingTime, err := message.Header.GetBytes(tag.SendingTime); err == nil {
+		message.Header.SetBytes(tag.OrigSendingTime, sendingTime)
+	} else if !origSendingTime.IsZero() {
+		message.Header.Set(field.NewOrigSendingTime(origSendingTime.UTC()))
+	}
+}
+
+// CheckPossDup fails if the message is flagged as a possible duplicate without
+// OrigSendingTime, which venues reject.
+func CheckPossDup(message *quickfix.Message) error {
+	possDup, err := message.Header.GetBool(tag.PossDupFlag)
+	if err != nil || !possDup {
+		return nil
+	}
+
+	if !message.Header.Has(tag.OrigSendingTime) {
+		return fmt.Errorf("%w: PossDupFlag(43) set without OrigSendingTime(122)", errors.FixInvalidOutboundMessage)
+	}
+
+	return nil
+}
@@ -0,0 +1,491 @@
+package utils
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/quickfixgo/quickfix"
+	"github.com/quickfixgo/quickfix/datadictionary"
+)
+
+// ParseRawMessage parses a raw FIX message. Fields can either be delimited by
+// SOH or by `|`, surrounding whitespaces and new lines are ignored.
+func ParseRawMessage(raw []byte, transportDict, appDict *datadictionary.DataDictionary) (*quickfix.Message, error) {
+	raw = bytes.TrimSpace(raw)
+	if !bytes.ContainsRune(raw, '\x01') {
+		raw = bytes.ReplaceAll(raw, []byte("|"), []byte("\x01"))
+	}
+
+	msg := quickfix.NewMessage()
+	err := quickfix.ParseMessageWithDataDictionary(msg, bytes.NewBuffer(raw), transportDict, appDict)
+	if err != nil {
+		return nil, err
+	}
+
+	return msg, nil
+}
+
+// ReadRawMessages parses the raw FIX messages read from r, one per line. Empty
+// lines and lines starting with `#` are ignored.
+func ReadRawMessages(r io.Reader, transportDict, appDict *datadictionary.DataDictionary) ([]*quickfix.Message, error) {
+	messages := []*quickfix.Message{}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+
+	for line := 1; scanner.Scan(); line++ {
+		raw := bytes.TrimSpace(scanner.Bytes())
+		if len(raw) == 0 || raw[0] == '#' {
+			continue
+		}
+
+		msg, err := ParseRawMessage(raw, transportDict, appDict)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		messages = append(messages, msg)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return messages, nil
+}
+
+// ReadBinaryMessages parses the FIX messages found in r as sent on the wire,
+// SOH delimiters included, e.g. payloads extracted from a network capture.
+// Messages are framed by their BodyLength and CheckSum so they may be
+// concatenated and hold new lines in data fields; bytes between messages are
+// ignored.
+func ReadBinaryMessages(r io.Reader, transportDict, appDict *datadictionary.DataDictionary) ([]*quickfix.Message, error) {
+	raw, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	frames, err := SplitBinaryMessages(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	messages := make([]*quickfix.Message, 0, len(frames))
+	for i, frame := range frames {
+		msg := quickfix.NewMessage()
+		if err := quickfix.ParseMessageWithDataDictionary(msg, bytes.NewBuffer(frame), transportDict, appDict); err != nil {
+			return nil, fmt.Errorf("message %d: %w", i+1, err)
+		}
+		messages = append(messages, msg)
+	}
+
+	return messages, nil
+}
+
+// SplitBinaryMessages returns the FIX messages found in raw, framed by their
+// BodyLength and CheckSum.
+func SplitBinaryMessages(raw []byte) ([][]byte, error) {
+	frames := [][]byte{}
+
+	for {
+		start := indexBeginString(raw)
+		if start < 0 {
+			break
+		}
+		raw = raw[start:]
+
+		// 8=FIX.x.y<SOH>9=n<SOH>
+		beginEnd := bytes.IndexByte(raw, '\x01')
+		if beginEnd < 0 || !bytes.HasPrefix(raw[beginEnd+1:], []byte("9=")) {
+			return nil, fmt.Errorf("message %d: BodyLength must follow BeginString", len(frames)+1)
+		}
+		lengthEnd := bytes.IndexByte(raw[beginEnd+1:], '\x01')
+		if lengthEnd < 0 {
+			return nil, fmt.Errorf("message %d: truncated BodyLength", len(frames)+1)
+		}
+		lengthEnd += beginEnd + 1
+
+		bodyLength, err := strconv.Atoi(string(raw[beginEnd+3 : lengthEnd]))
+		if err != nil || bodyLength < 0 {
+			return nil, fmt.Errorf("message %d: invalid BodyLength `%s`", len(frames)+1, raw[beginEnd+3:lengthEnd])
+		}
+
+		// 10=nnn<SOH>
+		end := lengthEnd + 1 + bodyLength + 7
+		if end > len(raw) {
+			return nil, fmt.Errorf("message %d: truncated, %d bytes missing", len(frames)+1, end-len(raw))
+		}
+		if !bytes.HasPrefix(raw[end-7:], []byte("10=")) || raw[end-1] != '\x01' {
+			return nil, fmt.Errorf("message %d: CheckSum not found where BodyLength ends", len(frames)+1)
+		}
+
+		frames = append(frames, raw[:end])
+		raw = raw[end:]
+	}
+
+	if len(frames) == 0 {
+		return nil, ErrNoFIXMessage
+	}
+
+	return frames, nil
+}
+
+// indexBeginString returns the index of the first BeginString field of raw
+// which is not the end of another tag, e.g. 58=FIX.
+func indexBeginString(raw []byte) int {
+	offset := 0
+	for {
+		i := bytes.Index(raw[offset:], []byte("8=FIX"))
+		if i < 0 {
+			return -1
+		}
+		i += offset
+		if i == 0 || raw[i-1] < '0' || raw[i-1] > '9' {
+			return i
+		}
+		offset = i + 1
+	}
+}
+
+// CapturedMessage is a message read from a capture file with the time it was
+// captured at, if any.
+type CapturedMessage struct {
+	Time    time.Time
+	Message *quickfix.Message
+}
+
+// ErrNoFIXMessage is returned when parsing a capture line which does not hold
+// any FIX message.
+var ErrNoFIXMessage = fmt.Errorf("no FIX message found")
+
+// captureTimeLayouts are the timestamp layouts accepted in front of captured
+// messages.
+var captureTimeLayouts = []string{
+	time.RFC3339Nano,
+	"20060102-15:04:05.999999999",
+	"2006-01-02 15:04:05.999999999",
+	"2006/01/02 15:04:05.999999999",
+}
+
+// ReadCapturedMessages parses the FIX messages read from r, one per line, like
+// ReadRawMessages does. A line can start with a timestamp, e.g. as found in
+// quickfix logs, which is separated from the message by spaces or ` : `.
+func ReadCapturedMessages(r io.Reader, transportDict, appDict *datadictionary.DataDictionary) ([]CapturedMessage, error) {
+	messages := []CapturedMessage{}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+
+	for line := 1; scanner.Scan(); line++ {
+		raw := bytes.TrimSpace(scanner.Bytes())
+		if len(raw) == 0 || raw[0] == '#' {
+			continue
+		}
+
+		captured, err := ParseCapturedMessage(raw, transportDict, appDict)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+
+		messages = append(messages, captured)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return messages, nil
+}
+
+// ParseCapturedMessage parses a line of a capture file, see
+// ReadCapturedMessages.
+func ParseCapturedMessage(raw []byte, transportDict, appDict *datadictionary.DataDictionary) (CapturedMessage, error) {
+	captured := CapturedMessage{}
+
+	i := bytes.Index(raw, []byte("8=FIX"))
+	if i < 0 {
+		return captured, ErrNoFIXMessage
+	}
+	if i > 0 {
+		prefix := string(bytes.TrimRight(raw[:i], " \t:"))
+		t, err := parseCaptureTime(prefix)
+		if err != nil {
+			return captured, err
+		}
+		captured.Time = t
+	}
+
+	msg, err := ParseRawMessage(raw[i:], transportDict, appDict)
+	if err != nil {
+		return captured, err
+	}
+	captured.Message = msg
+
+	return captured, nil
+}
+
+func parseCaptureTime(s string) (time.Time, error) {
+	for _, layout := range captureTimeLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("unknown timestamp format `%s`", s)
+}
+
+// MessageField is a field of a FIX message. Repeating group counter fields
+// hold the entries of the group.
+type MessageField struct {
+	Tag     int               `json:"tag"`
+	Name    string            `json:"name,omitempty"`
+	Value   string            `json:"value"`
+	Entries [][]*MessageField `json:"entries,omitempty"`
+}
+
+// Label returns the name of the field followed by its tag.
+func (f *MessageField) Label() string {
+	if len(f.Name) == 0 {
+		return strconv.Itoa(f.Tag)
+	}
+
+	return fmt.Sprintf("%s(%d)", f.Name, f.Tag)
+}
+
+// MessageTree returns the fields of a parsed message in wire order with the
+// repeating group entries nested under their counter field. Without
+// dictionaries all fields are returned flat.
+func MessageTree(msg *quickfix.Message, transportDict, appDict *datadictionary.DataDictionary) []*MessageField {
+	defs := make(map[int]*datadictionary.FieldDef)
+
+	for _, dict := range []*datadictionary.DataDictionary{transportDict, appDict} {
+		if dict == nil {
+			continue
+		}
+		if dict.Header != nil {
+			for tag, def := range dict.Header.Fields {
+				defs[tag] = def
+			}
+		}
+		if dict.Trailer != nil {
+			for tag, def := range dict.Trailer.Fields {
+				defs[tag] = def
+			}
+		}
+	}
+
+	if msgType, err := msg.MsgType(); err == nil && appDict != nil {
+		if msgDef, ok := appDict.Messages[msgType]; ok {
+			for tag, def := range msgDef.Fields {
+				defs[tag] = def
+			}
+		}
+	}
+
+	name := func(tag int) string {
+		for _, dict := range []*datadictionary.DataDictionary{appDict, transportDict} {
+			if dict == nil {
+				continue
+			}
+			if ft, ok := dict.FieldTypeByTag[tag]; ok {
+				return ft.Name()
+			}
+		}
+		return ""
+	}
+
+	i := 0
+	return messageTreeLevel(msg.GetFields(), &i, defs, 0, true, name)
+}
+
+func messageTreeLevel(fields []quickfix.TagValue, i *int, defs map[int]*datadictionary.FieldDef, delim int, top bool, name func(int) string) []*MessageField {
+	level := []*MessageField{}
+
+	for *i < len(fields) {
+		tag := int(fields[*i].Tag())
+		def, ok := defs[tag]
+
+		// Field belongs to the parent level or starts the next group entry
+		if !top && (!ok || (tag == delim && len(level) > 0)) {
+			break
+		}
+
+		field := &MessageField{
+			Tag:   tag,
+			Name:  name(tag),
+			Value: fields[*i].Value(),
+		}
+		*i++
+
+		if ok && def.IsGroup() && len(def.Fields) > 0 {
+			childDefs := make(map[int]*datadictionary.FieldDef, len(def.Fields))
+			for _, child := range def.Fields {
+				childDefs[child.Tag()] = child
+			}
+
+			childDelim := def.Fields[0].Tag()
+			count, _ := strconv.Atoi(field.Value)
+			for e := 0; e < count && *i < len(fields) && int(fields[*i].Tag()) == childDelim; e++ {
+				field.Entries = append(field.Entries, messageTreeLevel(fields, i, childDefs, childDelim, false, name))
+			}
+		}
+
+		level = append(level, field)
+	}
+
+	return level
+}
+
+// EncodeMessageTree serializes the message tree in its field order, as
+// MessageTree returns it. Fields are given by tag or by name, the counter of
+// repeating groups defaults to the number of entries, BodyLength and CheckSum
+// are computed.
+func EncodeMessageTree(fields []*MessageField, dicts ...*datadictionary.DataDictionary) ([]byte, error) {
+	body := bytes.Buffer{}
+	beginString := ""
+
+	if err := encodeMessageTreeLevel(&body, fields, &beginString, true, dicts); err != nil {
+		return nil, err
+	}
+	if len(beginString) == 0 {
+		return nil, fmt.Errorf("missing BeginString")
+	}
+
+	raw := bytes.Buffer{}
+	fmt.Fprintf(&raw, "8=%s\x019=%d\x01", beginString, body.Len())
+	raw.Write(body.Bytes())
+	fmt.Fprintf(&raw, "10=%s\x01", checkSum(raw.Bytes()))
+
+	return raw.Bytes(), nil
+}
+
+func encodeMessageTreeLevel(w *bytes.Buffer, fields []*MessageField, beginString *string, top bool, dicts []*datadictionary.DataDictionary) error {
+	for _, f := range fields {
+		tag := f.Tag
+		if tag == 0 {
+			for _, dict := range dicts {
+				if dict == nil {
+					continue
+				}
+				if ft, ok := dict.FieldTypeByName[f.Name]; ok {
+					tag = ft.Tag()
+					break
+				}
+			}
+		}
+		if tag <= 0 {
+			return fmt.Errorf("unknown field `%s`", f.Name)
+		}
+
+		value := f.Value
+		if f.Entries != nil && len(value) == 0 {
+			value = strconv.Itoa(len(f.Entries))
+		}
+
+		// Framing fields are computed
+		switch {
+		case top && tag == 8:
+			*beginString = value
+			continue
+		case top && (tag == 9 || tag == 10):
+			continue
+		}
+
+		fmt.Fprintf(w, "%d=%s\x01", tag, value)
+
+		for _, entry := range f.Entries {
+			if err := encodeMessageTreeLevel(w, entry, beginString, false, dicts); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// MessageTreeJSON returns the message tree as a JSON object whose keys are the
+// field names, or tags when unknown, and repeating groups arrays of objects.
+func MessageTreeJSON(fields []*MessageField) map[string]interface{} {
+	object := make(map[string]interface{}, len(fields))
+
+	for _, f := range fields {
+		key := f.Name
+		if len(key) == 0 {
+			key = strconv.Itoa(f.Tag)
+		}
+
+		if f.Entries == nil {
+			object[key] = f.Value
+			continue
+		}
+
+		entries := make([]map[string]interface{}, 0, len(f.Entries))
+		for _, entry := range f.Entries {
+			entries = append(entries, MessageTreeJSON(entry))
+		}
+		object[key] = entries
+	}
+
+	return object
+}
+
+// MessageTreeGroupValues returns the values of the field tag found in the
+// entries of the top level repeating group.
+func MessageTreeGroupValues(fields []*MessageField, group int, tag int) []string {
+	values := []string{}
+
+	for _, f := range fields {
+		if f.Tag != group {
+			continue
+		}
+		for _, entry := range f.Entries {
+			for _, ef := range entry {
+				if ef.Tag == tag {
+					values = append(values, ef.Value)
+				}
+			}
+		}
+	}
+
+	return values
+}
+
+// MessageFieldPath is a field of a message tree along with its path.
+type MessageFieldPath struct {
+	Path  string
+	Field *MessageField
+}
+
+// FlattenMessageTree returns the fields of a message tree along with a path
+// uniquely identifying them, e.g. `NoMDEntries(268)[0].MDEntryPx(270)`.
+func FlattenMessageTree(fields []*MessageField) []MessageFieldPath {
+	return flattenMessageTree(fields, "")
+}
+
+func flattenMessageTree(fields []*MessageField, prefix string) []MessageFieldPath {
+	paths := []MessageFieldPath{}
+	seen := make(map[string]int)
+
+	for _, field := range fields {
+		path := prefix + field.Label()
+
+		// Repeated tags at the same level
+		if n := seen[path]; n > 0 {
+			seen[path]++
+			path = fmt.Sprintf("%s#%d", path, n+1)
+		} else {
+			seen[path] = 1
+		}
+
+		paths = append(paths, MessageFieldPath{Path: path, Field: field})
+
+		for e, entry := range field.Entries {
+			paths = append(paths, flattenMessageTree(entry, fmt.Sprintf("%s[%d].", path, e))...)
+		}
+	}
+
+	return paths
+}
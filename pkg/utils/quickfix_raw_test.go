@@ -0,0 +1,121 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"sylr.dev/fix/pkg/testutils"
+)
+
+// rawMessage frames the given fields with BeginString, BodyLength and CheckSum.
+func rawMessage(fields ...string) string {
+	body := strings.Join(fields, "\x01") + "\x01"
+	raw := fmt.Sprintf("8=FIXT.1.1\x019=%d\x01%s", len(body), body)
+
+	return raw + "10=" + checkSum([]byte(raw)) + "\x01"
+}
+
+func TestMessageTreeRoundTrip(t *testing.T) {
+	transportDict, appDict := testutils.Dictionaries(t)
+
+	raw := rawMessage(
+		"35=W", "49=VENUE", "56=CLIENT", "34=2", "52=20230101-00:00:00.000",
+		"262=req-1", "55=EUR/USD",
+		"268=2", "269=0", "270=1.0999", "271=1000000", "269=1", "270=1.1001", "271=2000000",
+	)
+
+	msg, err := ParseRawMessage([]byte(raw), transportDict, appDict)
+	if err != nil {
+		t.Fatalf("ParseRawMessage() error = %v", err)
+	}
+
+	// tojson
+	encoded, err := json.Marshal(MessageTree(msg, transportDict, appDict))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// fromjson
+	fields := []*MessageField{}
+	if err := json.Unmarshal(encoded, &fields); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := EncodeMessageTree(fields, appDict, transportDict)
+	if err != nil {
+		t.Fatalf("EncodeMessageTree() error = %v", err)
+	}
+	if string(got) != raw {
+		t.Errorf("EncodeMessageTree() = %q, want %q", got, raw)
+	}
+}
+
+func TestEncodeMessageTree(t *testing.T) {
+	transportDict, appDict := testutils.Dictionaries(t)
+
+	tests := []struct {
+		name    string
+		json    string
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "fields by name and computed counter",
+			json: `[
+				{"name": "BeginString", "value": "FIXT.1.1"},
+				{"name": "MsgType", "value": "V"},
+				{"tag": 262, "value": "req-1"},
+				{"name": "NoMDEntryTypes", "entries": [
+					[{"name": "MDEntryType", "value": "0"}],
+					[{"name": "MDEntryType", "value": "1"}]
+				]}
+			]`,
+			want: rawMessage("35=V", "262=req-1", "267=2", "269=0", "269=1"),
+		},
+		{
+			name: "framing fields are recomputed",
+			json: `[
+				{"tag": 8, "value": "FIXT.1.1"},
+				{"tag": 9, "value": "999"},
+				{"tag": 35, "value": "0"},
+				{"tag": 10, "value": "000"}
+			]`,
+			want: rawMessage("35=0"),
+		},
+		{
+			name:    "unknown field name",
+			json:    `[{"tag": 8, "value": "FIXT.1.1"}, {"name": "NotAField", "value": "x"}]`,
+			wantErr: true,
+		},
+		{
+			name:    "missing BeginString",
+			json:    `[{"tag": 35, "value": "0"}]`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fields := []*MessageField{}
+			if err := json.Unmarshal([]byte(tt.json), &fields); err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := EncodeMessageTree(fields, appDict, transportDict)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("EncodeMessageTree() = %q, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("EncodeMessageTree() error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("EncodeMessageTree() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
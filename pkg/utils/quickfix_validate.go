@@ -0,0 +1,177 @@
+package utils
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/quickfixgo/quickfix"
+	"github.com/quickfixgo/quickfix/datadictionary"
+	qtag "github.com/quickfixgo/tag"
+
+	"sylr.dev/fix/pkg/errors"
+)
+
+// ValidateOutgoingMessage validates a message built for sending against the
+// data dictionaries. The header fields set by quickfix when sending the message
+// are filled with placeholder values before validation.
+//
+// The returned error lists all missing required body fields, or the first
+// invalid field found by the quickfix validator.
+func ValidateOutgoingMessage(message *quickfix.Message, beginString string, transportDict, appDict *datadictionary.DataDictionary) error {
+	if appDict == nil {
+		return nil
+	}
+
+	msg := quickfix.NewMessage()
+	message.CopyInto(msg)
+
+	if !msg.Header.Has(qtag.BeginString) {
+		msg.Header.SetString(qtag.BeginString, beginString)
+	}
+	if !msg.Header.Has(qtag.MsgSeqNum) {
+		msg.Header.SetInt(qtag.MsgSeqNum, 1)
+	}
+	if !msg.Header.Has(qtag.SendingTime) {
+		msg.Header.SetString(qtag.SendingTime, time.Now().UTC().Format("20060102-15:04:05.000"))
+	}
+
+	parsed := quickfix.NewMessage()
+	err := quickfix.ParseMessageWithDataDictionary(parsed, bytes.NewBufferString(msg.String()), transportDict, appDict)
+	if err != nil {
+		return fmt.Errorf("%w: %s", errors.FixInvalidOutboundMessage, err)
+	}
+
+	msgType, err := parsed.MsgType()
+	if err != nil {
+		return fmt.Errorf("%w: %s", errors.FixInvalidOutboundMessage, err)
+	}
+
+	if def, ok := appDict.Messages[msgType]; ok {
+		missing := []string{}
+		for required := range def.RequiredTags {
+			if !parsed.Body.Has(quickfix.Tag(required)) {
+				missing = append(missing, describeTag(appDict, quickfix.Tag(required)))
+			}
+		}
+
+		if len(missing) > 0 {
+			sort.Strings(missing)
+			return fmt.Errorf("%w: missing required fields %s", errors.FixInvalidOutboundMessage, strings.Join(missing, ", "))
+		}
+	}
+
+	validator := quickfix.NewValidator(quickfix.ValidatorSettings{RejectInvalidMessage: true}, appDict, transportDict)
+	if rej := validator.Validate(parsed); rej != nil {
+		if ref := rej.RefTagID(); ref != nil {
+			return fmt.Errorf("%w: %s %s", errors.FixInvalidOutboundMessage, rej.Error(), describeTag(appDict, *ref))
+		}
+		return fmt.Errorf("%w: %s", errors.FixInvalidOutboundMessage, rej.Error())
+	}
+
+	return nil
+}
+
+// UnknownTags returns the tags of the message, including the ones found in
+// repeating groups, which are not defined in the data dictionaries.
+func UnknownTags(message *quickfix.Message, transportDict, appDict *datadictionary.DataDictionary) []int {
+	if appDict == nil {
+		return nil
+	}
+
+	seen := make(map[int]struct{})
+	unknown := []int{}
+
+	for _, pair := range strings.Split(message.String(), "\x01") {
+		i := strings.IndexByte(pair, '=')
+		if i <= 0 {
+			continue
+		}
+
+		tag, err := strconv.Atoi(pair[:i])
+		if err != nil {
+			continue
+		}
+
+		if _, ok := seen[tag]; ok {
+			continue
+		}
+		seen[tag] = struct{}{}
+
+		if _, ok := appDict.FieldTypeByTag[tag]; ok {
+			continue
+		}
+		if transportDict != nil {
+			if _, ok := transportDict.FieldTypeByTag[tag]; ok {
+				continue
+			}
+		}
+
+		unknown = append(unknown, tag)
+	}
+
+	sort.Ints(unknown)
+
+	return unknown
+}
+
+// CheckGroupDefinition checks that the repeating group identified by groupTag
+// is defined for the given message type in the dictionary, and that all the
+// member tags are defined in the group.
+func CheckGroupDefinition(dict *datadictionary.DataDictionary, msgType string, groupTag quickfix.Tag, memberTags ...quickfix.Tag) error {
+	if dict == nil {
+		return nil
+	}
+
+	def, ok := dict.Messages[msgType]
+	if !ok {
+		return fmt.Errorf("%w: message type %s not defined", errors.FixDictionaryMismatch, msgType)
+	}
+
+	group, ok := def.Fields[int(groupTag)]
+	if !ok || !group.IsGroup() {
+		return fmt.Errorf("%w: group %s not defined in message %s", errors.FixDictionaryMismatch, describeTag(dict, groupTag), def.Name)
+	}
+
+MEMBERS:
+	for _, member := range memberTags {
+		for _, f := range group.Fields {
+			if f.Tag() == int(member) {
+				continue MEMBERS
+			}
+		}
+
+		return fmt.Errorf("%w: field %s not defined in group %s of message %s", errors.FixDictionaryMismatch, describeTag(dict, member), describeTag(dict, groupTag), def.Name)
+	}
+
+	return nil
+}
+
+// MessageHasField returns true if the field is defined at the top level of the
+// given message type in the dictionary. It returns true if dict is nil as
+// nothing can be checked.
+func MessageHasField(dict *datadictionary.DataDictionary, msgType string, tag quickfix.Tag) bool {
+	if dict == nil {
+		return true
+	}
+
+	def, ok := dict.Messages[msgType]
+	if !ok {
+		return false
+	}
+
+	_, ok = def.Fields[int(tag)]
+
+	return ok
+}
+
+func describeTag(dict *datadictionary.DataDictionary, tag quickfix.Tag) string {
+	if ft, ok := dict.FieldTypeByTag[int(tag)]; ok {
+		return fmt.Sprintf("%d(%s)", tag, ft.Name())
+	}
+
+	return fmt.Sprintf("%d", tag)
+}
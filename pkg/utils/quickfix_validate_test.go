@@ -0,0 +1,222 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/quickfixgo/enum"
+	"github.com/quickfixgo/field"
+	"github.com/quickfixgo/quickfix"
+	"github.com/quickfixgo/quickfix/datadictionary"
+	"github.com/quickfixgo/tag"
+
+	"sylr.dev/fix/pkg/errors"
+	"sylr.dev/fix/pkg/testutils"
+)
+
+// newMarketDataRequest returns a complete MarketDataRequest for one symbol.
+func newMarketDataRequest() *quickfix.Message {
+	message := quickfix.NewMessage()
+	message.Header.Set(field.NewMsgType(enum.MsgType_MARKET_DATA_REQUEST))
+	message.Header.Set(field.NewSenderCompID("CLIENT"))
+	message.Header.Set(field.NewTargetCompID("VENUE"))
+	message.Body.Set(field.NewMDReqID("req-1"))
+	message.Body.Set(field.NewSubscriptionRequestType(enum.SubscriptionRequestType_SNAPSHOT))
+	message.Body.Set(field.NewMarketDepth(0))
+
+	types := quickfix.NewRepeatingGroup(tag.NoMDEntryTypes, quickfix.GroupTemplate{quickfix.GroupElement(tag.MDEntryType)})
+	types.Add().Set(field.NewMDEntryType(enum.MDEntryType_BID))
+	message.Body.SetGroup(types)
+
+	symbols := quickfix.NewRepeatingGroup(tag.NoRelatedSym, quickfix.GroupTemplate{quickfix.GroupElement(tag.Symbol)})
+	symbols.Add().Set(field.NewSymbol("EUR/USD"))
+	message.Body.SetGroup(symbols)
+
+	return message
+}
+
+func TestValidateOutgoingMessage(t *testing.T) {
+	transportDict, appDict := testutils.Dictionaries(t)
+
+	tests := []struct {
+		name    string
+		build   func() *quickfix.Message
+		wantErr string
+	}{
+		{
+			name:  "complete",
+			build: newMarketDataRequest,
+		},
+		{
+			name: "missing required field",
+			build: func() *quickfix.Message {
+				message := quickfix.NewMessage()
+				message.Header.Set(field.NewMsgType(enum.MsgType_MARKET_DATA_REQUEST))
+				message.Header.Set(field.NewSenderCompID("CLIENT"))
+				message.Header.Set(field.NewTargetCompID("VENUE"))
+				message.Body.Set(field.NewSubscriptionRequestType(enum.SubscriptionRequestType_SNAPSHOT))
+
+				types := quickfix.NewRepeatingGroup(tag.NoMDEntryTypes, quickfix.GroupTemplate{quickfix.GroupElement(tag.MDEntryType)})
+				types.Add().Set(field.NewMDEntryType(enum.MDEntryType_BID))
+				message.Body.SetGroup(types)
+
+				symbols := quickfix.NewRepeatingGroup(tag.NoRelatedSym, quickfix.GroupTemplate{quickfix.GroupElement(tag.Symbol)})
+				symbols.Add().Set(field.NewSymbol("EUR/USD"))
+				message.Body.SetGroup(symbols)
+
+				return message
+			},
+			wantErr: "missing required fields 262(MDReqID), 264(MarketDepth)",
+		},
+		{
+			name: "invalid enum value",
+			build: func() *quickfix.Message {
+				message := newMarketDataRequest()
+				message.Body.SetString(tag.SubscriptionRequestType, "9")
+				return message
+			},
+			wantErr: "263(SubscriptionRequestType)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateOutgoingMessage(tt.build(), quickfix.BeginStringFIXT11, transportDict, appDict)
+
+			if len(tt.wantErr) == 0 {
+				if err != nil {
+					t.Fatalf("ValidateOutgoingMessage() error = %v", err)
+				}
+				return
+			}
+
+			if err == nil {
+				t.Fatalf("ValidateOutgoingMessage() error = nil, want %q", tt.wantErr)
+			}
+			if !errors.Is(err, errors.FixInvalidOutboundMessage) {
+				t.Errorf("ValidateOutgoingMessage() error = %v, want %v", err, errors.FixInvalidOutboundMessage)
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ValidateOutgoingMessage() error = %q, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	t.Run("no dictionary", func(t *testing.T) {
+		if err := ValidateOutgoingMessage(quickfix.NewMessage(), quickfix.BeginStringFIXT11, nil, nil); err != nil {
+			t.Errorf("ValidateOutgoingMessage() error = %v", err)
+		}
+	})
+}
+
+// strippedDictionary is a FIX.5.0SP2 app dictionary reduced to a
+// MarketDataRequest without instruments, as some venues publish.
+const strippedDictionary = `<fix type="FIX" major="5" minor="0" servicepack="2">
+ <header/>
+ <trailer/>
+ <messages>
+  <message name="MarketDataRequest" msgtype="V" msgcat="app">
+   <field name="MDReqID" required="Y"/>
+   <group name="NoMDEntryTypes" required="Y">
+    <field name="MDEntryType" required="Y"/>
+   </group>
+  </message>
+ </messages>
+ <components/>
+ <fields>
+  <field number="55" name="Symbol" type="STRING"/>
+  <field number="146" name="NoRelatedSym" type="NUMINGROUP"/>
+  <field number="262" name="MDReqID" type="STRING"/>
+  <field number="267" name="NoMDEntryTypes" type="NUMINGROUP"/>
+  <field number="269" name="MDEntryType" type="CHAR"/>
+  <field number="1300" name="MarketSegmentID" type="STRING"/>
+ </fields>
+</fix>`
+
+func TestCheckGroupDefinition(t *testing.T) {
+	_, appDict := testutils.Dictionaries(t)
+
+	stripped, err := datadictionary.ParseSrc(strings.NewReader(strippedDictionary))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		dict    *datadictionary.DataDictionary
+		msgType string
+		group   quickfix.Tag
+		members []quickfix.Tag
+		wantErr string
+	}{
+		{
+			name:    "bundled dictionary",
+			dict:    appDict,
+			msgType: "V",
+			group:   tag.NoRelatedSym,
+			members: []quickfix.Tag{tag.Symbol, tag.SecurityGroup},
+		},
+		{
+			name:    "stripped dictionary defining the group",
+			dict:    stripped,
+			msgType: "V",
+			group:   tag.NoMDEntryTypes,
+			members: []quickfix.Tag{tag.MDEntryType},
+		},
+		{
+			name:    "group missing from the message",
+			dict:    stripped,
+			msgType: "V",
+			group:   tag.NoRelatedSym,
+			members: []quickfix.Tag{tag.Symbol},
+			wantErr: "group 146(NoRelatedSym) not defined in message MarketDataRequest",
+		},
+		{
+			name:    "member missing from the group",
+			dict:    stripped,
+			msgType: "V",
+			group:   tag.NoMDEntryTypes,
+			members: []quickfix.Tag{tag.MDEntryType, tag.MarketSegmentID},
+			wantErr: "field 1300(MarketSegmentID) not defined in group 267(NoMDEntryTypes) of message MarketDataRequest",
+		},
+		{
+			name:    "field which is not a group",
+			dict:    stripped,
+			msgType: "V",
+			group:   tag.MDReqID,
+			wantErr: "group 262(MDReqID) not defined in message MarketDataRequest",
+		},
+		{
+			name:    "message missing from the dictionary",
+			dict:    stripped,
+			msgType: "W",
+			group:   tag.NoMDEntries,
+			wantErr: "message type W not defined",
+		},
+		{
+			name:    "no dictionary",
+			msgType: "V",
+			group:   tag.NoRelatedSym,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckGroupDefinition(tt.dict, tt.msgType, tt.group, tt.members...)
+
+			if len(tt.wantErr) == 0 {
+				if err != nil {
+					t.Fatalf("CheckGroupDefinition() error = %v", err)
+				}
+				return
+			}
+
+			if !errors.Is(err, errors.FixDictionaryMismatch) {
+				t.Fatalf("CheckGroupDefinition() error = %v, want %v", err, errors.FixDictionaryMismatch)
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("CheckGroupDefinition() error = %q, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
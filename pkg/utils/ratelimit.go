@@ -0,0 +1,89 @@
+package utils
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RateLimiter is a token bucket pacing outbound messages. A nil RateLimiter
+// never delays anything.
+type RateLimiter struct {
+	interval time.Duration
+	burst    int
+	tokens   float64
+	last     time.Time
+	mux      sync.Mutex
+}
+
+// NewRateLimiter returns a RateLimiter allowing rate messages per second with
+// bursts of at most burst messages. It returns nil if rate is not positive.
+func NewRateLimiter(rate float64, burst int) *RateLimiter {
+	if rate <= 0 {
+		return nil
+	}
+	if burst < 1 {
+		burst = 1
+	}
+
+	return &RateLimiter{
+		interval: time.Duration(float64(time.Second) / rate),
+		burst:    burst,
+		tokens:   float64(burst),
+		last:     time.Now(),
+	}
+}
+
+// Reserve takes a token from the bucket and returns how long the caller must
+// wait before sending. Callers are expected to wait in a select alongside
+// their cancellation channels so that pacing never blocks shutdown.
+func (l *RateLimiter) Reserve() time.Duration {
+	if l == nil {
+		return 0
+	}
+
+	l.mux.Lock()
+	defer l.mux.Unlock()
+
+	now := time.Now()
+	l.tokens += float64(now.Sub(l.last)) / float64(l.interval)
+	if l.tokens > float64(l.burst) {
+		l.tokens = float64(l.burst)
+	}
+	l.last = now
+	l.tokens--
+
+	if l.tokens >= 0 {
+		return 0
+	}
+
+	return time.Duration(-l.tokens * float64(l.interval))
+}
+
+// ParseRate parses a rate expressed as N/s, N/m or N (per second) and returns
+// it as a number of events per second.
+func ParseRate(raw string) (float64, error) {
+	num, unit, found := strings.Cut(raw, "/")
+
+	n, err := strconv.ParseFloat(num, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid rate `%s`", raw)
+	}
+
+	if !found {
+		return n, nil
+	}
+
+	switch unit {
+	case "s":
+		return n, nil
+	case "m":
+		return n / 60, nil
+	case "h":
+		return n / 3600, nil
+	default:
+		return 0, fmt.Errorf("invalid rate unit `%s`, expecting s, m or h", unit)
+	}
+}
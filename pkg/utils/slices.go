@@ -9,3 +9,59 @@ func Search[T comparable](slice []T, search T) int {
 
 	return -1
 }
+
+// Chunk splits slice into chunks of at most size elements. If size is lower
+// than 1 the whole slice is returned as a single chunk.
+func Chunk[T any](slice []T, size int) [][]T {
+	if size < 1 || len(slice) <= size {
+		return [][]T{slice}
+	}
+
+	chunks := make([][]T, 0, (len(slice)+size-1)/size)
+	for size < len(slice) {
+		chunks = append(chunks, slice[:size:size])
+		slice = slice[size:]
+	}
+
+	return append(chunks, slice)
+}
+
+// GlobMatch reports whether s matches the glob pattern where `*` matches any
+// sequence of characters and `?` matches any single character. Unlike
+// path.Match, `/` is not treated as a separator.
+func GlobMatch(pattern string, s string) bool {
+	px, sx := 0, 0
+	nextPx, nextSx := -1, -1
+
+	for px < len(pattern) || sx < len(s) {
+		if px < len(pattern) {
+			switch c := pattern[px]; c {
+			case '*':
+				nextPx, nextSx = px, sx+1
+				px++
+				continue
+			case '?':
+				if sx < len(s) {
+					px++
+					sx++
+					continue
+				}
+			default:
+				if sx < len(s) && s[sx] == c {
+					px++
+					sx++
+					continue
+				}
+			}
+		}
+
+		if nextSx > 0 && nextSx <= len(s) {
+			px, sx = nextPx, nextSx
+			continue
+		}
+
+		return false
+	}
+
+	return true
+}
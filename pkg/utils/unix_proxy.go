@@ -0,0 +1,50 @@
+package utils
+
+import (
+	"io"
+	"net"
+)
+
+// ListenUnixProxy listens on a random loopback TCP port and forwards every
+// accepted connection to the Unix domain socket at path. It is used to reach
+// acceptors listening on Unix sockets as quickfix only dials TCP.
+func ListenUnixProxy(path string) (*net.TCPAddr, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go proxyUnixConn(conn, path)
+		}
+	}()
+
+	return listener.Addr().(*net.TCPAddr), nil
+}
+
+func proxyUnixConn(conn net.Conn, path string) {
+	defer conn.Close()
+
+	unixConn, err := net.Dial("unix", path)
+	if err != nil {
+		return
+	}
+	defer unixConn.Close()
+
+	done := make(chan struct{}, 2)
+	go func() {
+		io.Copy(unixConn, conn)
+		done <- struct{}{}
+	}()
+	go func() {
+		io.Copy(conn, unixConn)
+		done <- struct{}{}
+	}()
+
+	<-done
+}